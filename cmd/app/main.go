@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"strings"
@@ -12,18 +13,43 @@ import (
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/handler/http"
 	authRepo "github.com/kenziehh/cashflow-be/internal/domain/auth/repository"
 	authService "github.com/kenziehh/cashflow-be/internal/domain/auth/service"
+	ruleHandler "github.com/kenziehh/cashflow-be/internal/domain/rule/handler/http"
+	ruleRepo "github.com/kenziehh/cashflow-be/internal/domain/rule/repository"
+	ruleService "github.com/kenziehh/cashflow-be/internal/domain/rule/service"
+	suggestionHandler "github.com/kenziehh/cashflow-be/internal/domain/suggestion/handler/http"
+	suggestionRepo "github.com/kenziehh/cashflow-be/internal/domain/suggestion/repository"
+	suggestionService "github.com/kenziehh/cashflow-be/internal/domain/suggestion/service"
 	transactionHandler "github.com/kenziehh/cashflow-be/internal/domain/transaction/handler/http"
 	transactionRepo "github.com/kenziehh/cashflow-be/internal/domain/transaction/repository"
 	transactionService "github.com/kenziehh/cashflow-be/internal/domain/transaction/service"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
 	"github.com/kenziehh/cashflow-be/internal/infra/postgres"
 	"github.com/kenziehh/cashflow-be/internal/infra/redis"
+	"github.com/kenziehh/cashflow-be/internal/infra/smtpd"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 	"github.com/gofiber/swagger"
 	categoryHandler "github.com/kenziehh/cashflow-be/internal/domain/category/handler/http"
 	categoryRepo "github.com/kenziehh/cashflow-be/internal/domain/category/repository"
+	backupHandler "github.com/kenziehh/cashflow-be/internal/domain/backup/handler/http"
+	backupRepo "github.com/kenziehh/cashflow-be/internal/domain/backup/repository"
+	backupService "github.com/kenziehh/cashflow-be/internal/domain/backup/service"
+	billHandler "github.com/kenziehh/cashflow-be/internal/domain/bill/handler/http"
+	billRepo "github.com/kenziehh/cashflow-be/internal/domain/bill/repository"
+	billService "github.com/kenziehh/cashflow-be/internal/domain/bill/service"
 	categoryService "github.com/kenziehh/cashflow-be/internal/domain/category/service"
+	debtHandler "github.com/kenziehh/cashflow-be/internal/domain/debt/handler/http"
+	debtRepo "github.com/kenziehh/cashflow-be/internal/domain/debt/repository"
+	debtService "github.com/kenziehh/cashflow-be/internal/domain/debt/service"
+	forecastHandler "github.com/kenziehh/cashflow-be/internal/domain/forecast/handler/http"
+	forecastRepo "github.com/kenziehh/cashflow-be/internal/domain/forecast/repository"
+	forecastService "github.com/kenziehh/cashflow-be/internal/domain/forecast/service"
+	goalHandler "github.com/kenziehh/cashflow-be/internal/domain/goal/handler/http"
+	goalRepo "github.com/kenziehh/cashflow-be/internal/domain/goal/repository"
+	goalService "github.com/kenziehh/cashflow-be/internal/domain/goal/service"
 	maximumSpendHandler "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/handler/http"
 	maximumSpendRepo "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/repository"
 	maximumSpendService "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/service"
@@ -55,9 +81,17 @@ func main() {
 	redis := redis.InitRedis(cfg)
 	defer redis.Close()
 
+	// Initialize file storage (disk lokal atau S3)
+	fileStorage := storage.InitStorage(cfg)
+
+	// Initialize keyring untuk enkripsi note dan lampiran
+	keyring := encryption.InitKeyring(cfg, db)
+
 	// Initialize Fiber
+	// Body sedikit lebih besar dari batas lampiran untuk menampung field form lainnya
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middleware.ErrorHandler,
+		BodyLimit:    (cfg.AttachmentMaxSizeMB + 1) * 1024 * 1024,
 	})
 
 	// Middleware
@@ -74,8 +108,9 @@ func main() {
 			return false
 		},
 		AllowCredentials: true,
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match, Idempotency-Key",
+		ExposeHeaders:    "ETag, Idempotent-Replayed",
 	}))
 
 	// Custom rate limiters
@@ -83,6 +118,9 @@ func main() {
 	generalLimiter := middleware.RateLimiter(redis, 100, 1*time.Minute) // global
 	app.Use(generalLimiter)
 
+	// Retry POST dengan Idempotency-Key yang sama mendapat response yang tersimpan
+	app.Use(middleware.Idempotency(redis, 24*time.Hour))
+
 	
 	// Swagger
 	app.Get("/docs/*", swagger.HandlerDefault)
@@ -91,8 +129,8 @@ func main() {
 	api := app.Group("/api/v1")
 
 	// Auth routes
-	authRepository := authRepo.NewAuthRepository(db, redis)
-	authSvc := authService.NewAuthService(authRepository)
+	authRepository := authRepo.NewAuthRepository(db, redis, keyring)
+	authSvc := authService.NewAuthService(authRepository, fileStorage, keyring, time.Duration(cfg.AccountDeletionGraceDays)*24*time.Hour)
 	authHandler := http.NewAuthHandler(authSvc)
 
 	auth := api.Group("/auth")
@@ -100,19 +138,99 @@ func main() {
 	auth.Post("/login", loginLimiter, authHandler.Login)
 	auth.Post("/logout", middleware.JWTAuth(), authHandler.Logout)
 	auth.Get("/me", middleware.JWTAuth(), authHandler.GetProfile)
+	auth.Delete("/me", middleware.JWTAuth(), authHandler.DeleteAccount)
+	auth.Post("/me/cancel-deletion", middleware.JWTAuth(), authHandler.CancelAccountDeletion)
+	auth.Post("/me/export", middleware.JWTAuth(), authHandler.RequestExport)
+	auth.Get("/me/export/:exportId", middleware.JWTAuth(), authHandler.GetExport)
+	auth.Get("/me/export/:exportId/download", middleware.JWTAuth(), authHandler.DownloadExport)
+
+	suggestionRepository := suggestionRepo.NewSuggestionRepository(db, redis, keyring)
+	suggestionSvc := suggestionService.NewSuggestionService(suggestionRepository)
+	suggestionHandler := suggestionHandler.NewSuggestionHandler(suggestionSvc)
+
+	ruleRepository := ruleRepo.NewRuleRepository(db, redis, keyring)
+	ruleSvc := ruleService.NewRuleService(ruleRepository, suggestionSvc)
+	ruleHandler := ruleHandler.NewRuleHandler(ruleSvc)
 
-	transactionRepository := transactionRepo.NewTransactionRepository(db, redis)
-	transactionSvc := transactionService.NewTransactionService(transactionRepository)
+	// Alamat inbox struk hanya diberikan ke user jika listener SMTP aktif
+	receiptInbox := transactionService.ReceiptInbox{Secret: cfg.ReceiptInboxSecret}
+	if cfg.ReceiptSMTPAddr != "" {
+		receiptInbox.Domain = cfg.ReceiptSMTPDomain
+	}
+
+	transactionRepository := transactionRepo.NewTransactionRepository(db, redis, keyring)
+	reconciliationRepository := transactionRepo.NewReconciliationRepository(db, redis, keyring)
+	receiptRepository := transactionRepo.NewReceiptRepository(db, redis, keyring)
+	transactionSvc := transactionService.NewTransactionService(
+		transactionRepository, reconciliationRepository, receiptRepository, ruleSvc, suggestionSvc, fileStorage, keyring,
+		transactionService.AttachmentLimits{
+			MaxSize: int64(cfg.AttachmentMaxSizeMB) * 1024 * 1024,
+			Quota:   int64(cfg.AttachmentQuotaMB) * 1024 * 1024,
+		},
+		transactionService.AttachmentLinks{BaseURL: cfg.AppBaseURL, Secret: cfg.SignedURLSecret},
+		receiptInbox,
+		time.Duration(cfg.TrashRetentionDays)*24*time.Hour,
+	)
 	transactionHandler := transactionHandler.NewTransactionHandler(transactionSvc)
 
 	transactions := api.Group("/transactions", middleware.JWTAuth())
 	transactions.Post("/", transactionHandler.CreateTransaction)
-	transactions.Get("/summary", transactionHandler.GetSummaryTransaction)
+	transactions.Post("/suggest-category", suggestionHandler.SuggestCategory)
+	transactions.Post("/batch", transactionHandler.BatchTransactions)
+	transactions.Post("/quick", transactionHandler.QuickAdd)
+	transactions.Post("/import", transactionHandler.ImportTransactions)
+	transactions.Get("/import/mappings", transactionHandler.GetCategoryMappings)
+	transactions.Put("/import/mappings", transactionHandler.SaveCategoryMapping)
+	transactions.Delete("/import/mappings", transactionHandler.DeleteCategoryMapping)
+	transactions.Get("/summary", etag.New(), transactionHandler.GetSummaryTransaction)
+	transactions.Get("/duplicates", transactionHandler.FindDuplicates)
+	transactions.Get("/trash", transactionHandler.GetTrash)
+	transactions.Post("/trash/:id/restore", transactionHandler.RestoreTransaction)
 	transactions.Get("/:id", transactionHandler.GetTransactionByID)
-	transactions.Get("/:id/proof", transactionHandler.GetProofFile)
-	transactions.Get("/", transactionHandler.GetTransactionsWithPagination)
+	transactions.Get("/", etag.New(), transactionHandler.GetTransactionsWithPagination)
 	transactions.Put("/:id", transactionHandler.UpdateTransaction)
+	transactions.Patch("/:id", transactionHandler.PatchTransaction)
 	transactions.Delete("/:id", transactionHandler.DeleteTransaction)
+	transactions.Post("/:id/merge", transactionHandler.MergeTransactions)
+	transactions.Get("/:id/history", transactionHandler.GetHistory)
+	transactions.Post("/:id/revert", transactionHandler.RevertTransaction)
+	transactions.Get("/:id/attachments", transactionHandler.GetAttachments)
+	transactions.Post("/:id/attachments", transactionHandler.AddAttachment)
+	transactions.Get("/:id/attachments/:attachmentId", transactionHandler.DownloadAttachment)
+	transactions.Delete("/:id/attachments/:attachmentId", transactionHandler.RemoveAttachment)
+	transactions.Post("/:id/attachments/:attachmentId/link", transactionHandler.CreateAttachmentLink)
+
+	api.Get("/sync", middleware.JWTAuth(), transactionHandler.Sync)
+	api.Get("/attachments/:attachmentId", transactionHandler.DownloadSignedAttachment)
+
+	receipts := api.Group("/receipts", middleware.JWTAuth())
+	receipts.Post("/email", transactionHandler.UploadReceiptEmail)
+	receipts.Get("/", transactionHandler.GetReceiptDrafts)
+	receipts.Get("/inbox", transactionHandler.GetReceiptInbox)
+	receipts.Get("/:id", transactionHandler.GetReceiptDraft)
+	receipts.Delete("/:id", transactionHandler.RejectReceiptDraft)
+	receipts.Get("/:id/files/:fileId", transactionHandler.DownloadReceiptFile)
+	receipts.Post("/:id/approve", transactionHandler.ApproveReceiptDraft)
+
+	reconciliations := api.Group("/reconciliations", middleware.JWTAuth())
+	reconciliations.Post("/", transactionHandler.CreateReconciliation)
+	reconciliations.Get("/", transactionHandler.GetReconciliations)
+	reconciliations.Get("/:id", transactionHandler.GetReconciliation)
+	reconciliations.Delete("/:id", transactionHandler.DeleteReconciliation)
+	reconciliations.Post("/:id/matches", transactionHandler.ConfirmStatementMatches)
+	reconciliations.Delete("/:id/matches/:lineId", transactionHandler.UnmatchStatementLine)
+	reconciliations.Post("/:id/transactions", transactionHandler.CreateFromStatement)
+	reconciliations.Post("/:id/lock", transactionHandler.LockReconciliation)
+	reconciliations.Delete("/:id/lock", transactionHandler.UnlockReconciliation)
+
+	rules := api.Group("/rules", middleware.JWTAuth())
+	rules.Post("/", ruleHandler.CreateRule)
+	rules.Get("/", ruleHandler.GetRules)
+	rules.Get("/settings", ruleHandler.GetSettings)
+	rules.Put("/settings", ruleHandler.UpdateSettings)
+	rules.Post("/apply", ruleHandler.ApplyRules)
+	rules.Put("/:id", ruleHandler.UpdateRule)
+	rules.Delete("/:id", ruleHandler.DeleteRule)
 
 	categoryRepository := categoryRepo.NewCategoryRepository(db, redis)
 	categorySvc := categoryService.NewCategoryService(categoryRepository)
@@ -129,6 +247,134 @@ func main() {
 	maximumSpends.Post("/", maximumSpendHandler.SetMaximumSpend)
 	maximumSpends.Get("/", maximumSpendHandler.GetMaximumSpend)
 
+	forecastRepository := forecastRepo.NewForecastRepository(db, redis, keyring)
+	forecastSvc := forecastService.NewForecastService(forecastRepository)
+	forecastHandler := forecastHandler.NewForecastHandler(forecastSvc)
+
+	forecast := api.Group("/forecast", middleware.JWTAuth())
+	forecast.Get("/", forecastHandler.GetForecast)
+	forecast.Put("/threshold", forecastHandler.SetThreshold)
+
+	goalRepository := goalRepo.NewGoalRepository(db, redis, keyring)
+	goalSvc := goalService.NewGoalService(goalRepository)
+	goalHandler := goalHandler.NewGoalHandler(goalSvc)
+
+	goals := api.Group("/goals", middleware.JWTAuth())
+	goals.Post("/", goalHandler.CreateGoal)
+	goals.Get("/", goalHandler.GetGoals)
+	goals.Get("/:id", goalHandler.GetGoalByID)
+	goals.Put("/:id", goalHandler.UpdateGoal)
+	goals.Delete("/:id", goalHandler.DeleteGoal)
+	goals.Post("/:id/contributions", goalHandler.AddContribution)
+	goals.Delete("/:id/contributions/:transactionId", goalHandler.RemoveContribution)
+
+	debtRepository := debtRepo.NewDebtRepository(db, redis, keyring)
+	debtSvc := debtService.NewDebtService(debtRepository)
+	debtHandler := debtHandler.NewDebtHandler(debtSvc)
+
+	debts := api.Group("/debts", middleware.JWTAuth())
+	debts.Post("/counterparties", debtHandler.CreateCounterparty)
+	debts.Get("/counterparties", debtHandler.GetCounterparties)
+	debts.Get("/summary", debtHandler.GetDebtSummary)
+	debts.Get("/reminders", debtHandler.GetReminders)
+	debts.Post("/", debtHandler.CreateDebt)
+	debts.Get("/", debtHandler.GetDebts)
+	debts.Get("/:id", debtHandler.GetDebtByID)
+	debts.Put("/:id", debtHandler.UpdateDebt)
+	debts.Delete("/:id", debtHandler.DeleteDebt)
+	debts.Post("/:id/repayments", debtHandler.AddRepayment)
+
+	billRepository := billRepo.NewBillRepository(db, redis, keyring)
+	billSvc := billService.NewBillService(billRepository, cfg.AppBaseURL)
+	billHandler := billHandler.NewBillHandler(billSvc)
+
+	bills := api.Group("/bills", middleware.JWTAuth())
+	bills.Post("/", billHandler.CreateBill)
+	bills.Get("/", billHandler.GetBills)
+	bills.Get("/upcoming", billHandler.GetUpcomingBills)
+	bills.Post("/calendar-token", billHandler.RotateCalendarToken)
+	bills.Get("/:id", billHandler.GetBillByID)
+	bills.Put("/:id", billHandler.UpdateBill)
+	bills.Delete("/:id", billHandler.DeleteBill)
+	bills.Post("/:id/pay", billHandler.MarkPaid)
+
+	backupRepository := backupRepo.NewBackupRepository(db, redis, keyring)
+	backupSvc := backupService.NewBackupService(backupRepository)
+	backupHandler := backupHandler.NewBackupHandler(backupSvc)
+
+	backup := api.Group("/backup", middleware.JWTAuth())
+	backup.Get("/", backupHandler.CreateBackup)
+	backup.Post("/restore", backupHandler.Restore)
+
+	// Feed kalender publik, diautentikasi lewat token rahasia di URL
+	api.Get("/calendar/:token/bills.ics", billHandler.GetCalendarFeed)
+
+	// Reminder hutang/piutang yang jatuh tempo dicatat ke tabel alerts
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if err := debtSvc.SendDueReminders(context.Background()); err != nil {
+				log.Println("[DebtReminder] failed:", err)
+			}
+		}
+	}()
+
+	// Transaksi di trash yang melewati masa retensi dihapus permanen beserta file lampirannya
+	go func() {
+		ticker := time.NewTicker(6 * time.Hour)
+		defer ticker.Stop()
+		for ; ; <-ticker.C {
+			if n, err := transactionSvc.PurgeTrash(context.Background()); err != nil {
+				log.Println("[TrashPurge] failed:", err)
+			} else if n > 0 {
+				log.Printf("[TrashPurge] purged %d transactions", n)
+			}
+		}
+	}()
+
+	// Thumbnail dan preview lampiran dibuat di background
+	go transactionSvc.RunAttachmentWorker(context.Background(), time.Minute)
+
+	// Email struk yang diteruskan ke alamat inbox user masuk ke antrean draft
+	if cfg.ReceiptSMTPAddr != "" {
+		receiptServer := &smtpd.Server{
+			Addr:    cfg.ReceiptSMTPAddr,
+			Domain:  cfg.ReceiptSMTPDomain,
+			MaxSize: int64(cfg.AttachmentMaxSizeMB) * 1024 * 1024,
+			AcceptRecipient: func(address string) bool {
+				_, ok := transactionSvc.ResolveReceiptInbox(address)
+				return ok
+			},
+			Deliver: func(ctx context.Context, recipients []string, data []byte) error {
+				var lastErr error
+				delivered := 0
+				for _, address := range recipients {
+					userID, _ := transactionSvc.ResolveReceiptInbox(address)
+					if _, err := transactionSvc.IngestEmail(ctx, userID, "smtp", data); err != nil {
+						log.Printf("[ReceiptSMTP] failed to ingest email for %s: %v", address, err)
+						lastErr = err
+						continue
+					}
+					delivered++
+				}
+				if delivered == 0 {
+					return lastErr
+				}
+				return nil
+			},
+		}
+		go func() {
+			log.Printf("Receipt SMTP listener running on %s", cfg.ReceiptSMTPAddr)
+			if err := receiptServer.ListenAndServe(); err != nil {
+				log.Println("[ReceiptSMTP] listener stopped:", err)
+			}
+		}()
+	}
+
+	// Arsip export dibuat di background, akun yang masa tenggangnya lewat dihapus permanen
+	go authSvc.RunAccountWorker(context.Background(), time.Minute)
+
 	// Start server
 	port := os.Getenv("APP_PORT")
 	if port == "" {
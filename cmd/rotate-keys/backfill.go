@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+)
+
+const backfillBatchSize = 500
+
+// noteColumn adalah kolom teks yang dienkripsi aplikasi. selectQuery menerima ID terakhir
+// ($1) dan mengembalikan id, user_id dan isi kolom; updateQuery menerima nilai terenkripsi,
+// id dan nilai lama supaya perubahan yang terjadi selama backfill tidak tertimpa.
+type noteColumn struct {
+	name        string
+	selectQuery string
+	updateQuery string
+}
+
+var noteColumns = []noteColumn{
+	{
+		name: "transactions.note",
+		selectQuery: `
+			SELECT id, user_id, note FROM transactions
+			WHERE note <> '' AND id > $1 ORDER BY id LIMIT $2`,
+		updateQuery: `UPDATE transactions SET note = $1 WHERE id = $2 AND note = $3`,
+	},
+	{
+		name: "transaction_revisions.snapshot",
+		selectQuery: `
+			SELECT r.id, t.user_id, r.snapshot->>'note' FROM transaction_revisions r
+			JOIN transactions t ON t.id = r.transaction_id
+			WHERE COALESCE(r.snapshot->>'note', '') <> '' AND r.id > $1 ORDER BY r.id LIMIT $2`,
+		updateQuery: `
+			UPDATE transaction_revisions SET snapshot = jsonb_set(snapshot, '{note}', to_jsonb($1::TEXT))
+			WHERE id = $2 AND snapshot->>'note' = $3`,
+	},
+	{
+		name: "debts.note",
+		selectQuery: `
+			SELECT id, user_id, note FROM debts
+			WHERE note <> '' AND id > $1 ORDER BY id LIMIT $2`,
+		updateQuery: `UPDATE debts SET note = $1 WHERE id = $2 AND note = $3`,
+	},
+	{
+		name: "bills.note",
+		selectQuery: `
+			SELECT id, user_id, note FROM bills
+			WHERE note <> '' AND id > $1 ORDER BY id LIMIT $2`,
+		updateQuery: `UPDATE bills SET note = $1 WHERE id = $2 AND note = $3`,
+	},
+	{
+		name: "receipt_drafts.note",
+		selectQuery: `
+			SELECT id, user_id, note FROM receipt_drafts
+			WHERE note <> '' AND id > $1 ORDER BY id LIMIT $2`,
+		updateQuery: `UPDATE receipt_drafts SET note = $1 WHERE id = $2 AND note = $3`,
+	},
+	{
+		name: "reconciliation_lines.note",
+		selectQuery: `
+			SELECT l.id, r.user_id, l.note FROM reconciliation_lines l
+			JOIN reconciliations r ON r.id = l.reconciliation_id
+			WHERE l.note <> '' AND l.id > $1 ORDER BY l.id LIMIT $2`,
+		updateQuery: `UPDATE reconciliation_lines SET note = $1 WHERE id = $2 AND note = $3`,
+	},
+}
+
+// fileKeysQuery mengembalikan semua file milik user yang disimpan aplikasi beserta tipenya.
+const fileKeysQuery = `
+	SELECT user_id, storage_key, content_type FROM transaction_attachments
+	UNION
+	SELECT user_id, thumbnail_key, 'image/jpeg' FROM transaction_attachments WHERE thumbnail_key IS NOT NULL
+	UNION
+	SELECT user_id, medium_key, 'image/jpeg' FROM transaction_attachments WHERE medium_key IS NOT NULL
+	UNION
+	SELECT d.user_id, f.storage_key, f.content_type FROM receipt_files f
+	JOIN receipt_drafts d ON d.id = f.draft_id
+	UNION
+	SELECT user_id, storage_key, 'application/zip' FROM account_exports WHERE storage_key IS NOT NULL
+`
+
+// backfillNotes mengenkripsi note yang disimpan sebelum enkripsi aktif.
+func backfillNotes(ctx context.Context, db *sql.DB, keyring *encryption.Keyring) (int, error) {
+	sealed := 0
+	for _, col := range noteColumns {
+		lastID := uuid.Nil
+		for {
+			type note struct {
+				id, userID uuid.UUID
+				value      string
+			}
+
+			rows, err := db.QueryContext(ctx, col.selectQuery, lastID, backfillBatchSize)
+			if err != nil {
+				return sealed, fmt.Errorf("%s: %w", col.name, err)
+			}
+			var batch []note
+			for rows.Next() {
+				var n note
+				if err := rows.Scan(&n.id, &n.userID, &n.value); err != nil {
+					rows.Close()
+					return sealed, fmt.Errorf("%s: %w", col.name, err)
+				}
+				batch = append(batch, n)
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return sealed, fmt.Errorf("%s: %w", col.name, err)
+			}
+
+			for _, n := range batch {
+				if encryption.IsSealedString(n.value) {
+					continue
+				}
+				value, err := keyring.EncryptString(ctx, n.userID, n.value)
+				if err != nil {
+					return sealed, fmt.Errorf("%s %s: %w", col.name, n.id, err)
+				}
+				res, err := db.ExecContext(ctx, col.updateQuery, value, n.id, n.value)
+				if err != nil {
+					return sealed, fmt.Errorf("%s %s: %w", col.name, n.id, err)
+				}
+				if affected, _ := res.RowsAffected(); affected > 0 {
+					sealed++
+				}
+			}
+
+			if len(batch) < backfillBatchSize {
+				break
+			}
+			lastID = batch[len(batch)-1].id
+		}
+	}
+
+	return sealed, nil
+}
+
+// backfillFiles mengenkripsi file yang disimpan sebelum enkripsi aktif. Key file tetap
+// sama karena dibentuk dari isi file asli, jadi baris di database tidak perlu diubah.
+func backfillFiles(ctx context.Context, db *sql.DB, keyring *encryption.Keyring, store storage.FileStorage) (int, error) {
+	type file struct {
+		userID      uuid.UUID
+		key         string
+		contentType string
+	}
+
+	rows, err := db.QueryContext(ctx, fileKeysQuery)
+	if err != nil {
+		return 0, err
+	}
+	var files []file
+	for rows.Next() {
+		var f file
+		if err := rows.Scan(&f.userID, &f.key, &f.contentType); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sealed := 0
+	seen := map[string]bool{}
+	for _, f := range files {
+		if f.key == "" || seen[f.key] {
+			continue
+		}
+		seen[f.key] = true
+
+		r, err := store.Get(ctx, f.key)
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return sealed, fmt.Errorf("file %s: %w", f.key, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return sealed, fmt.Errorf("file %s: %w", f.key, err)
+		}
+		if encryption.IsSealedBlob(data) {
+			continue
+		}
+
+		encrypted, err := keyring.Encrypt(ctx, f.userID, data)
+		if err != nil {
+			return sealed, fmt.Errorf("file %s: %w", f.key, err)
+		}
+		if err := store.Put(ctx, f.key, encrypted, f.contentType); err != nil {
+			return sealed, fmt.Errorf("file %s: %w", f.key, err)
+		}
+		sealed++
+	}
+
+	return sealed, nil
+}
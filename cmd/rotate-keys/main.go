@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/kenziehh/cashflow-be/config"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/internal/infra/postgres"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+)
+
+// rotate-keys membungkus ulang semua data key user dengan master key pertama di
+// ENCRYPTION_MASTER_KEYS. Master key lama harus tetap dicantumkan sampai perintah ini
+// selesai, setelah itu boleh dihapus dari config.
+//
+// Dengan -backfill, note dan file yang disimpan sebelum enkripsi diaktifkan ikut dienkripsi.
+// Perintah ini aman dijalankan ulang karena data yang sudah terenkripsi dilewati.
+func main() {
+	backfill := flag.Bool("backfill", false, "encrypt notes and files stored before encryption was enabled")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+
+	db := postgres.InitDB(cfg)
+	defer db.Close()
+
+	keyring := encryption.InitKeyring(cfg, db)
+
+	ctx := context.Background()
+
+	rotated, err := keyring.Rotate(ctx)
+	if err != nil {
+		log.Fatalf("❌ Key rotation failed after %d data keys: %v", rotated, err)
+	}
+
+	log.Printf("✅ Re-wrapped %d data keys", rotated)
+
+	if !*backfill {
+		return
+	}
+
+	notes, err := backfillNotes(ctx, db, keyring)
+	if err != nil {
+		log.Fatalf("❌ Note backfill failed after %d notes: %v", notes, err)
+	}
+	log.Printf("✅ Encrypted %d notes", notes)
+
+	files, err := backfillFiles(ctx, db, keyring, storage.InitStorage(cfg))
+	if err != nil {
+		log.Fatalf("❌ File backfill failed after %d files: %v", files, err)
+	}
+	log.Printf("✅ Encrypted %d files", files)
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"strconv"
 
 	_ "github.com/lib/pq"
 )
@@ -16,6 +20,36 @@ type Config struct {
 	RedisPort  string
 	JWTSecret  string
 	AppPort    string
+	AppBaseURL string
+	// Lama transaksi disimpan di trash sebelum dihapus permanen
+	TrashRetentionDays int
+	// local atau s3
+	StorageDriver string
+	StorageDir    string
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	// Batas ukuran satu lampiran dan total lampiran per user, dalam MB
+	AttachmentMaxSizeMB int
+	AttachmentQuotaMB   int
+	// Kunci HMAC untuk URL download lampiran, default diturunkan dari JWT secret
+	SignedURLSecret string
+	// development, staging atau production
+	Environment string
+	// Format "id:base64,id:base64", key pertama dipakai untuk membungkus data key baru
+	EncryptionMasterKeys string
+	// Izin eksplisit untuk berjalan tanpa master key di luar development
+	EncryptionDisabled bool
+	// Masa tenggang sebelum akun yang diminta dihapus benar-benar dihapus
+	AccountDeletionGraceDays int
+	// Alamat listener SMTP untuk email struk, misalnya "127.0.0.1:2525"; kosong berarti tidak aktif
+	ReceiptSMTPAddr string
+	// Domain alamat inbox struk yang diarahkan (MX) ke listener SMTP
+	ReceiptSMTPDomain string
+	// Kunci HMAC untuk alamat inbox struk, default diturunkan dari JWT secret
+	ReceiptInboxSecret string
 }
 
 func LoadConfig() *Config {
@@ -29,6 +63,32 @@ func LoadConfig() *Config {
 		RedisPort:  getEnv("REDIS_PORT", "6379"),
 		JWTSecret:  getEnv("JWT_SECRET", "your-secret-key"),
 		AppPort:    getEnv("APP_PORT", "8081"),
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:8081"),
+
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+
+		StorageDriver: getEnv("STORAGE_DRIVER", "local"),
+		StorageDir:    getEnv("STORAGE_DIR", "./uploads"),
+		S3Endpoint:    getEnv("S3_ENDPOINT", ""),
+		S3Region:      getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:      getEnv("S3_BUCKET", ""),
+		S3AccessKey:   getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:   getEnv("S3_SECRET_KEY", ""),
+
+		AttachmentMaxSizeMB: getEnvInt("ATTACHMENT_MAX_SIZE_MB", 10),
+		AttachmentQuotaMB:   getEnvInt("ATTACHMENT_QUOTA_MB", 200),
+		SignedURLSecret:     getEnvSecret("SIGNED_URL_SECRET", "signed-url"),
+
+		Environment: getEnv("ENVIRONMENT", "production"),
+
+		EncryptionMasterKeys: getEnv("ENCRYPTION_MASTER_KEYS", ""),
+		EncryptionDisabled:   getEnvBool("ENCRYPTION_DISABLED", false),
+
+		AccountDeletionGraceDays: getEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 14),
+
+		ReceiptSMTPAddr:    getEnv("RECEIPT_SMTP_ADDR", ""),
+		ReceiptSMTPDomain:  getEnv("RECEIPT_SMTP_DOMAIN", "localhost"),
+		ReceiptInboxSecret: getEnvSecret("RECEIPT_INBOX_SECRET", "receipt-inbox"),
 	}
 }
 
@@ -38,3 +98,33 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvSecret membaca kunci HMAC dari env. Jika kosong, kunci diturunkan dari JWT secret
+// dengan HKDF memakai label purpose, supaya setiap fitur punya kunci sendiri dan tanda tangan
+// untuk satu fitur tidak bisa dipakai di fitur lain.
+func getEnvSecret(key, purpose string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	master := []byte(getEnv("JWT_SECRET", "your-secret-key"))
+	derived, err := hkdf.Key(sha256.New, master, nil, "cashflow-be/"+purpose, 32)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(derived)
+}
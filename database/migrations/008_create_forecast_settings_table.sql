@@ -0,0 +1,6 @@
+CREATE TABLE forecast_settings (
+    user_id UUID PRIMARY KEY,
+    low_balance_threshold DECIMAL(12,2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_forecast_settings_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,36 @@
+CREATE TYPE debt_direction AS ENUM ('payable', 'receivable');
+
+CREATE TABLE counterparties (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    contact VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_counterparties_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE debts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    counterparty_id UUID NOT NULL,
+    direction debt_direction NOT NULL,
+    principal DECIMAL(12,2) NOT NULL,
+    note TEXT,
+    start_date DATE NOT NULL,
+    due_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_debts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_debts_counterparty FOREIGN KEY (counterparty_id) REFERENCES counterparties(id) ON DELETE CASCADE
+);
+
+-- Cicilan hutang/piutang dicatat sebagai transaksi yang punya debt_id,
+-- dan tidak dihitung di summary income/expense.
+ALTER TABLE transactions ADD COLUMN debt_id UUID;
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transactions_debt FOREIGN KEY (debt_id) REFERENCES debts(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_counterparties_user ON counterparties(user_id);
+CREATE INDEX idx_debts_user ON debts(user_id);
+CREATE INDEX idx_transactions_debt ON transactions(debt_id);
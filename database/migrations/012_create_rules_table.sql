@@ -0,0 +1,30 @@
+ALTER TABLE transactions ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    note_contains VARCHAR(255),
+    note_regex VARCHAR(255),
+    min_amount DECIMAL(12,2),
+    max_amount DECIMAL(12,2),
+    transaction_type transaction_type,
+    set_category_id CHAR(26),
+    add_tags TEXT[] NOT NULL DEFAULT '{}',
+    note_rewrite VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_rules_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_rules_category FOREIGN KEY (set_category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE TABLE rule_settings (
+    user_id UUID PRIMARY KEY,
+    match_mode VARCHAR(10) NOT NULL DEFAULT 'first',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_rule_settings_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_rules_user ON rules(user_id);
@@ -0,0 +1,28 @@
+CREATE TABLE category_model_states (
+    user_id UUID PRIMARY KEY,
+    documents INT NOT NULL DEFAULT 0,
+    trained_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_category_model_states_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE category_model_classes (
+    user_id UUID NOT NULL,
+    category_id CHAR(26) NOT NULL,
+    doc_count INT NOT NULL DEFAULT 0,
+    token_count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, category_id),
+    CONSTRAINT fk_category_model_classes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_category_model_classes_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE category_model_tokens (
+    user_id UUID NOT NULL,
+    category_id CHAR(26) NOT NULL,
+    token VARCHAR(64) NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, category_id, token),
+    CONSTRAINT fk_category_model_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_category_model_tokens_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_category_model_tokens_user_token ON category_model_tokens(user_id, token);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE transaction_revisions (
+    id UUID PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    version INT NOT NULL,
+    changed_by UUID,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_transaction_revisions_version UNIQUE (transaction_id, version),
+    CONSTRAINT fk_transaction_revisions_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transaction_revisions_user FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
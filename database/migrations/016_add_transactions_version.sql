@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,61 @@
+-- Nomor urut global yang naik setiap kali baris dibuat, diubah, atau dihapus
+CREATE SEQUENCE sync_seq;
+
+CREATE OR REPLACE FUNCTION touch_sync_seq() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sync_seq := nextval('sync_seq');
+    IF TG_OP = 'INSERT' THEN
+        NEW.created_seq := NEW.sync_seq;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Baris yang dihapus permanen dicatat supaya client bisa ikut menghapus salinannya
+CREATE TABLE sync_tombstones (
+    seq BIGINT PRIMARY KEY DEFAULT nextval('sync_seq'),
+    entity VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    user_id UUID,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sync_tombstones_user_seq ON sync_tombstones(user_id, seq);
+
+CREATE OR REPLACE FUNCTION record_sync_tombstone() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO sync_tombstones (entity, entity_id, user_id)
+    VALUES (TG_ARGV[0], OLD.id::TEXT, (to_jsonb(OLD)->>'user_id')::UUID);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE transactions ADD COLUMN sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE transactions ADD COLUMN created_seq BIGINT;
+UPDATE transactions SET created_seq = sync_seq;
+CREATE INDEX idx_transactions_user_sync_seq ON transactions(user_id, sync_seq);
+
+ALTER TABLE categories ADD COLUMN sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE categories ADD COLUMN created_seq BIGINT;
+UPDATE categories SET created_seq = sync_seq;
+CREATE INDEX idx_categories_sync_seq ON categories(sync_seq);
+
+ALTER TABLE maximum_spends ADD COLUMN sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE maximum_spends ADD COLUMN created_seq BIGINT;
+UPDATE maximum_spends SET created_seq = sync_seq;
+CREATE INDEX idx_maximum_spends_user_sync_seq ON maximum_spends(user_id, sync_seq);
+
+CREATE TRIGGER trg_transactions_sync_seq BEFORE INSERT OR UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION touch_sync_seq();
+CREATE TRIGGER trg_transactions_sync_tombstone AFTER DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('transaction');
+
+CREATE TRIGGER trg_categories_sync_seq BEFORE INSERT OR UPDATE ON categories
+    FOR EACH ROW EXECUTE FUNCTION touch_sync_seq();
+CREATE TRIGGER trg_categories_sync_tombstone AFTER DELETE ON categories
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('category');
+
+CREATE TRIGGER trg_maximum_spends_sync_seq BEFORE INSERT OR UPDATE ON maximum_spends
+    FOR EACH ROW EXECUTE FUNCTION touch_sync_seq();
+CREATE TRIGGER trg_maximum_spends_sync_tombstone AFTER DELETE ON maximum_spends
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('budget');
@@ -0,0 +1,9 @@
+-- proof_file kini berisi key storage relatif terhadap STORAGE_DIR (default ./uploads),
+-- bukan path disk. File lama tetap bisa dibaca oleh storage lokal dengan key "proofs/<nama>".
+UPDATE transactions
+SET proof_file = regexp_replace(proof_file, '^(\./)?uploads/', '')
+WHERE proof_file ~ '^(\./)?uploads/';
+
+UPDATE transaction_revisions
+SET snapshot = jsonb_set(snapshot, '{proof_file}', to_jsonb(regexp_replace(snapshot->>'proof_file', '^(\./)?uploads/', '')))
+WHERE snapshot->>'proof_file' ~ '^(\./)?uploads/';
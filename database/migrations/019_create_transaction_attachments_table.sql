@@ -0,0 +1,24 @@
+CREATE TABLE transaction_attachments (
+    id UUID PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_transaction_attachments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_transaction_attachments_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_attachments_transaction ON transaction_attachments(transaction_id);
+CREATE INDEX idx_transaction_attachments_user ON transaction_attachments(user_id);
+CREATE INDEX idx_transaction_attachments_storage_key ON transaction_attachments(storage_key);
+
+-- Bukti lama dipindah menjadi lampiran; ukurannya tidak diketahui sehingga dicatat 0
+INSERT INTO transaction_attachments (id, transaction_id, user_id, storage_key, filename, content_type, size, created_at)
+SELECT gen_random_uuid(), id, user_id, proof_file, regexp_replace(proof_file, '^.*/', ''), 'application/octet-stream', 0, created_at
+FROM transactions
+WHERE proof_file IS NOT NULL AND proof_file <> '';
+
+ALTER TABLE transactions DROP COLUMN proof_file;
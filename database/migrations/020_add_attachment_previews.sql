@@ -0,0 +1,11 @@
+-- Thumbnail dan preview dibuat worker di background. processed_at NULL berarti lampiran
+-- belum diproses; claimed_at mencegah dua worker memproses lampiran yang sama.
+ALTER TABLE transaction_attachments
+    ADD COLUMN thumbnail_key VARCHAR(255),
+    ADD COLUMN medium_key VARCHAR(255),
+    ADD COLUMN claimed_at TIMESTAMP,
+    ADD COLUMN processed_at TIMESTAMP;
+
+CREATE INDEX idx_transaction_attachments_pending ON transaction_attachments(created_at) WHERE processed_at IS NULL;
+CREATE INDEX idx_transaction_attachments_thumbnail_key ON transaction_attachments(thumbnail_key);
+CREATE INDEX idx_transaction_attachments_medium_key ON transaction_attachments(medium_key);
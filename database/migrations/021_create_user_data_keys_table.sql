@@ -0,0 +1,11 @@
+-- Data key per user untuk enkripsi note dan lampiran, disimpan terbungkus oleh master key
+CREATE TABLE user_data_keys (
+    user_id UUID PRIMARY KEY,
+    master_key_id VARCHAR(50) NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    rotated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_data_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_data_keys_master_key ON user_data_keys(master_key_id);
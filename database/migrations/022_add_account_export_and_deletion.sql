@@ -0,0 +1,24 @@
+-- Akun yang diminta dihapus tetap ada sampai deletion_scheduled_at lewat (masa tenggang),
+-- setelah itu worker menghapus user beserta semua datanya lewat ON DELETE CASCADE.
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Arsip export dibuat worker di background. claimed_at mencegah dua worker membuat arsip
+-- yang sama; arsip yang sudah expires_at dihapus dari storage.
+CREATE TABLE account_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    storage_key VARCHAR(255),
+    size BIGINT NOT NULL DEFAULT 0,
+    claimed_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account_exports_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_account_exports_user_id ON account_exports(user_id, created_at DESC);
+CREATE INDEX idx_account_exports_pending ON account_exports(created_at) WHERE status = 'pending';
+CREATE INDEX idx_account_exports_expires_at ON account_exports(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- ID transaksi dari file import (misalnya FITID OFX), dipakai supaya import ulang file yang
+-- sama tidak membuat transaksi ganda. Transaksi di trash tetap dihitung sehingga transaksi
+-- yang sengaja dihapus tidak muncul lagi.
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_transactions_user_external_id ON transactions(user_id, external_id) WHERE external_id IS NOT NULL;
+
+-- Pemetaan kategori aplikasi sumber ke kategori kita, per user dan per format import.
+-- source_category disimpan dalam bentuk ternormalisasi (huruf kecil, spasi tunggal).
+CREATE TABLE import_category_mappings (
+    user_id UUID NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    source_category VARCHAR(100) NOT NULL,
+    category_id CHAR(26) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, source, source_category),
+    CONSTRAINT fk_import_category_mappings_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_import_category_mappings_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
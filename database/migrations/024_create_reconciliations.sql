@@ -0,0 +1,85 @@
+-- Rekonsiliasi mencocokkan rekening koran dengan transaksi di aplikasi untuk satu periode.
+-- Aplikasi belum punya konsep rekening, jadi account hanya label dari user dan kunci periode
+-- berlaku untuk semua transaksi user di periode tersebut.
+CREATE TABLE reconciliations (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    account VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    locked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_reconciliations_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_reconciliations_period CHECK (start_date <= end_date)
+);
+
+CREATE INDEX idx_reconciliations_user_id ON reconciliations(user_id, start_date DESC);
+CREATE INDEX idx_reconciliations_locked ON reconciliations(user_id, start_date, end_date) WHERE locked_at IS NOT NULL;
+
+-- Satu baris rekening koran. transaction_id terisi setelah user mengonfirmasi pasangannya;
+-- satu transaksi hanya bisa menjadi pasangan satu baris rekening koran.
+CREATE TABLE reconciliation_lines (
+    id UUID PRIMARY KEY,
+    reconciliation_id UUID NOT NULL,
+    line INT NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    date DATE NOT NULL,
+    -- Positif untuk pemasukan, negatif untuk pengeluaran
+    amount DECIMAL(12,2) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    transaction_id UUID,
+    confirmed_at TIMESTAMP,
+    CONSTRAINT fk_reconciliation_lines_reconciliation FOREIGN KEY (reconciliation_id) REFERENCES reconciliations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reconciliation_lines_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_reconciliation_lines_reconciliation_id ON reconciliation_lines(reconciliation_id, line);
+CREATE UNIQUE INDEX idx_reconciliation_lines_transaction_id ON reconciliation_lines(transaction_id) WHERE transaction_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION is_period_locked(p_user_id UUID, p_date DATE) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM reconciliations
+        WHERE user_id = p_user_id AND locked_at IS NOT NULL AND p_date BETWEEN start_date AND end_date
+    );
+$$ LANGUAGE sql STABLE;
+
+-- Transaksi aktif di periode yang dikunci tidak bisa dibuat, dihapus, dipulihkan dari trash,
+-- atau diubah nominal, tipe dan tanggalnya. Kategori, note dan tag tetap boleh diubah.
+-- Kode error CF001 dipetakan ke errx.ErrPeriodLocked oleh errx.FromWrite.
+CREATE OR REPLACE FUNCTION enforce_reconciliation_lock() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.deleted_at IS NULL AND is_period_locked(NEW.user_id, NEW.date) THEN
+            RAISE EXCEPTION 'transaction date % is in a locked reconciliation period', NEW.date USING ERRCODE = 'CF001';
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        -- Hapus akun ikut menghapus transaksi lewat cascade setelah user terhapus, dan tidak boleh terhalang
+        IF OLD.deleted_at IS NULL AND is_period_locked(OLD.user_id, OLD.date)
+            AND EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id) THEN
+            RAISE EXCEPTION 'transaction date % is in a locked reconciliation period', OLD.date USING ERRCODE = 'CF001';
+        END IF;
+        RETURN OLD;
+    END IF;
+
+    IF NEW.amount IS DISTINCT FROM OLD.amount
+        OR NEW.type IS DISTINCT FROM OLD.type
+        OR NEW.date IS DISTINCT FROM OLD.date
+        OR NEW.deleted_at IS DISTINCT FROM OLD.deleted_at
+        OR NEW.user_id IS DISTINCT FROM OLD.user_id THEN
+        IF (OLD.deleted_at IS NULL AND is_period_locked(OLD.user_id, OLD.date))
+            OR (NEW.deleted_at IS NULL AND is_period_locked(NEW.user_id, NEW.date)) THEN
+            RAISE EXCEPTION 'transaction date % is in a locked reconciliation period', OLD.date USING ERRCODE = 'CF001';
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_transactions_reconciliation_lock BEFORE INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION enforce_reconciliation_lock();
@@ -0,0 +1,52 @@
+-- Draft transaksi dari email struk yang menunggu ditinjau user. Email asli dan lampirannya
+-- disimpan terenkripsi di storage dan menjadi lampiran transaksi saat draft disetujui.
+CREATE TABLE receipt_drafts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    -- upload atau smtp
+    source VARCHAR(10) NOT NULL,
+    -- Template pengirim yang dipakai, generic jika pengirim tidak dikenal
+    template VARCHAR(30) NOT NULL,
+    -- Message-ID email, atau hash isi email jika tidak ada, supaya email yang sama tidak masuk dua kali
+    message_key VARCHAR(255) NOT NULL,
+    sender VARCHAR(255) NOT NULL DEFAULT '',
+    merchant VARCHAR(100) NOT NULL DEFAULT '',
+    type VARCHAR(10) NOT NULL DEFAULT 'expense',
+    -- NULL jika total tidak ditemukan di email
+    amount DECIMAL(12,2),
+    date DATE NOT NULL,
+    category_id CHAR(26),
+    -- Terenkripsi seperti note transaksi
+    note TEXT NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    transaction_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_receipt_drafts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_receipt_drafts_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL,
+    CONSTRAINT fk_receipt_drafts_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL,
+    CONSTRAINT chk_receipt_drafts_type CHECK (type IN ('income', 'expense')),
+    CONSTRAINT chk_receipt_drafts_status CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE UNIQUE INDEX idx_receipt_drafts_message_key ON receipt_drafts(user_id, message_key);
+CREATE INDEX idx_receipt_drafts_user_status ON receipt_drafts(user_id, status, created_at DESC);
+
+-- File milik draft yang belum disetujui. Saat disetujui barisnya dipindah ke
+-- transaction_attachments dengan storage key yang sama.
+CREATE TABLE receipt_files (
+    id UUID PRIMARY KEY,
+    draft_id UUID NOT NULL,
+    -- email untuk email asli, attachment untuk lampiran email
+    kind VARCHAR(10) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_receipt_files_draft FOREIGN KEY (draft_id) REFERENCES receipt_drafts(id) ON DELETE CASCADE,
+    CONSTRAINT chk_receipt_files_kind CHECK (kind IN ('email', 'attachment'))
+);
+
+CREATE INDEX idx_receipt_files_draft_id ON receipt_files(draft_id);
+CREATE INDEX idx_receipt_files_storage_key ON receipt_files(storage_key);
@@ -0,0 +1,36 @@
+-- sync_seq diambil saat baris ditulis, bukan saat commit, sehingga transaksi yang commit lebih
+-- lambat bisa memegang nomor yang lebih kecil dari perubahan yang sudah terkirim ke client.
+-- Setiap perubahan kini juga mencatat xid transaksi penulisnya. Sync hanya mengirim perubahan
+-- dari transaksi dengan xid di bawah xmin snapshot (semuanya sudah selesai) dan cursor diurutkan
+-- berdasarkan (sync_xid, sync_seq), jadi penulis yang belum commit tidak bisa terlewat.
+-- Baris lama mendapat xid 0 sehingga token lama yang hanya berisi nomor urut tetap bisa dilanjutkan.
+ALTER TABLE transactions
+    ADD COLUMN sync_xid xid8 NOT NULL DEFAULT '0',
+    ADD COLUMN created_xid xid8 NOT NULL DEFAULT '0';
+CREATE INDEX idx_transactions_user_sync_xid ON transactions(user_id, sync_xid, sync_seq);
+
+ALTER TABLE categories
+    ADD COLUMN sync_xid xid8 NOT NULL DEFAULT '0',
+    ADD COLUMN created_xid xid8 NOT NULL DEFAULT '0';
+CREATE INDEX idx_categories_sync_xid ON categories(sync_xid, sync_seq);
+
+ALTER TABLE maximum_spends
+    ADD COLUMN sync_xid xid8 NOT NULL DEFAULT '0',
+    ADD COLUMN created_xid xid8 NOT NULL DEFAULT '0';
+CREATE INDEX idx_maximum_spends_user_sync_xid ON maximum_spends(user_id, sync_xid, sync_seq);
+
+ALTER TABLE sync_tombstones ADD COLUMN sync_xid xid8 NOT NULL DEFAULT '0';
+ALTER TABLE sync_tombstones ALTER COLUMN sync_xid SET DEFAULT pg_current_xact_id();
+CREATE INDEX idx_sync_tombstones_user_xid ON sync_tombstones(user_id, sync_xid, seq);
+
+CREATE OR REPLACE FUNCTION touch_sync_seq() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sync_seq := nextval('sync_seq');
+    NEW.sync_xid := pg_current_xact_id();
+    IF TG_OP = 'INSERT' THEN
+        NEW.created_seq := NEW.sync_seq;
+        NEW.created_xid := NEW.sync_xid;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
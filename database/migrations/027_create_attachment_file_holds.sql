@@ -0,0 +1,11 @@
+-- File lampiran disimpan dengan key berbasis isi, jadi upload baru bisa memakai file yang sedang
+-- dihapus karena lampiran lain dilepas. Sebelum file ditulis, key-nya ditahan sampai pinned_until
+-- supaya tidak dihapus sebelum baris lampirannya tersimpan. Penghapusan yang tertunda karena
+-- tahanan ini ditandai remove_requested dan dijalankan worker setelah tahanannya habis.
+CREATE TABLE attachment_file_holds (
+    storage_key VARCHAR(255) PRIMARY KEY,
+    pinned_until TIMESTAMP NOT NULL,
+    remove_requested BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_attachment_file_holds_pinned_until ON attachment_file_holds(pinned_until);
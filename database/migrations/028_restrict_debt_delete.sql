@@ -0,0 +1,9 @@
+-- Menghapus hutang dulu ikut menghapus permanen semua cicilannya lewat ON DELETE CASCADE, sehingga
+-- cicilan tidak masuk trash, lampirannya tertinggal di storage dan kunci periode rekonsiliasi
+-- berubah menjadi error database. Hutang yang masih punya cicilan kini tidak bisa dihapus.
+-- NO ACTION dipakai, bukan RESTRICT, karena pengecekannya baru dijalankan di akhir statement:
+-- saat user dihapus, hutang dan transaksinya sama-sama terhapus lewat cascade dari users dan
+-- RESTRICT akan menolak jika hutang kebetulan terhapus lebih dulu dari cicilannya.
+ALTER TABLE transactions DROP CONSTRAINT fk_transactions_debt;
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transactions_debt FOREIGN KEY (debt_id) REFERENCES debts(id) ON DELETE NO ACTION;
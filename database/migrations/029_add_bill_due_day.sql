@@ -0,0 +1,8 @@
+-- Tanggal jatuh tempo asli tagihan. next_due_date di bulan pendek sudah dipendekkan (31 Jan ->
+-- 28 Feb), jadi jatuh tempo berikutnya dihitung dari due_day supaya kembali ke tanggal 31.
+-- Tagihan lama memakai tanggal next_due_date saat ini; yang sudah terlanjur bergeser ke
+-- tanggal 28 perlu diperbaiki lewat update tagihan.
+ALTER TABLE bills ADD COLUMN due_day SMALLINT;
+UPDATE bills SET due_day = EXTRACT(DAY FROM next_due_date);
+ALTER TABLE bills ALTER COLUMN due_day SET NOT NULL;
+ALTER TABLE bills ADD CONSTRAINT chk_bills_due_day CHECK (due_day BETWEEN 1 AND 31);
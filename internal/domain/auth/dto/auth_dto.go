@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
@@ -20,8 +22,97 @@ type UserProfile struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
 	Name  string `json:"name"`
+	// Diisi jika akun sedang menunggu dihapus
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 type UpdateProfileRequest struct {
 	Name string `json:"name" validate:"required"`
-}
\ No newline at end of file
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// ExportManifest ditulis sebagai manifest.json di dalam arsip export.
+type ExportManifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	UserID     string    `json:"user_id"`
+}
+
+// AccountExportData berisi seluruh data user yang ditulis ke arsip export, satu file JSON per field.
+type AccountExportData struct {
+	Profile      ExportProfile       `json:"profile"`
+	Transactions []ExportTransaction `json:"transactions"`
+	Categories   []ExportCategory    `json:"categories"`
+	Budgets      []ExportBudget      `json:"budgets"`
+	Alerts       []ExportAlert       `json:"alerts"`
+	AuditLogs    []ExportAuditLog    `json:"audit_logs"`
+}
+
+type ExportProfile struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExportTransaction struct {
+	ID              string             `json:"id"`
+	TransactionType string             `json:"transaction_type"`
+	Amount          float64            `json:"amount"`
+	CategoryID      string             `json:"category_id"`
+	Period          string             `json:"period"`
+	Note            string             `json:"note"`
+	Date            string             `json:"date"`
+	Tags            []string           `json:"tags"`
+	DebtID          *string            `json:"debt_id,omitempty"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	Attachments     []ExportAttachment `json:"attachments"`
+}
+
+type ExportAttachment struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Lokasi file di dalam arsip, kosong jika file tidak ditemukan di storage
+	Path       string `json:"path"`
+	StorageKey string `json:"-"`
+}
+
+type ExportCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ExportBudget struct {
+	ID           string     `json:"id"`
+	DailyLimit   *float64   `json:"daily_limit"`
+	MonthlyLimit *float64   `json:"monthly_limit"`
+	YearlyLimit  *float64   `json:"yearly_limit"`
+	CreatedAt    *time.Time `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+}
+
+type ExportAlert struct {
+	ID          string     `json:"id"`
+	Message     string     `json:"message"`
+	Type        string     `json:"type"`
+	TriggeredAt *time.Time `json:"triggered_at"`
+}
+
+type ExportAuditLog struct {
+	ID        string     `json:"id"`
+	Action    string     `json:"action"`
+	CreatedAt *time.Time `json:"created_at"`
+}
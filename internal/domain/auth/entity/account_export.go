@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+// AccountExport adalah permintaan arsip seluruh data milik user (JSON dan file lampiran).
+type AccountExport struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	StorageKey  string     `json:"-"`
+	Size        int64      `json:"size"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// Arsip tidak bisa diunduh lagi setelah waktu ini
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
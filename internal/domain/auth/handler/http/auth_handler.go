@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -169,3 +170,159 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		return c.JSON(response.SuccessResponse("Profile updated successfully", nil))
 	}
 }
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Schedule the current account for permanent deletion after a grace period. The password must be re-confirmed. All data and stored files are purged once the grace period ends; until then the deletion can be cancelled.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Password confirmation"
+// @Success 202 {object} response.Response{data=dto.AccountDeletionResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/me [delete]
+func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.DeleteAccount(c.Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response.SuccessResponse("Account scheduled for deletion", result))
+}
+
+// CancelAccountDeletion godoc
+// @Summary Cancel account deletion
+// @Description Cancel a pending account deletion during the grace period
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/me/cancel-deletion [post]
+func (h *AuthHandler) CancelAccountDeletion(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	if err := h.service.CancelAccountDeletion(c.Context(), userID); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Account deletion cancelled", nil))
+}
+
+// RequestExport godoc
+// @Summary Request account data export
+// @Description Start building an archive with the profile, transactions, categories, budgets, alerts, audit logs and all receipt files as JSON and original files. The archive is built in the background; poll the export until its status is ready, then download it. Ready archives can be downloaded for 7 days.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.Response{data=entity.AccountExport}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/me/export [post]
+func (h *AuthHandler) RequestExport(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.RequestExport(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response.SuccessResponse("Export requested successfully", result))
+}
+
+// GetExport godoc
+// @Summary Get account data export
+// @Description Get the status of an export: pending, ready or failed
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exportId path string true "Export ID"
+// @Success 200 {object} response.Response{data=entity.AccountExport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/me/export/{exportId} [get]
+func (h *AuthHandler) GetExport(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	exportID, err := uuid.Parse(c.Params("exportId"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid export ID format")
+	}
+
+	result, err := h.service.GetExport(c.Context(), userID, exportID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Export retrieved successfully", result))
+}
+
+// DownloadExport godoc
+// @Summary Download account data export
+// @Description Download a ready export as a zip archive
+// @Tags auth
+// @Produce application/zip
+// @Security BearerAuth
+// @Param exportId path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/me/export/{exportId}/download [get]
+func (h *AuthHandler) DownloadExport(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	exportID, err := uuid.Parse(c.Params("exportId"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid export ID format")
+	}
+
+	export, file, err := h.service.OpenExport(c.Context(), userID, exportID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "cashflow-export-"+export.CreatedAt.Format("20060102")+".zip"))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.SendStream(file)
+}
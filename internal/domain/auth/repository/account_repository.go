@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/auth/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/auth/entity"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+const exportColumns = `id, user_id, status, COALESCE(storage_key, ''), size, completed_at, expires_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *authRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at *time.Time) error {
+	query := `UPDATE users SET deletion_scheduled_at = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, at, userID); err != nil {
+		log.Printf("[DB ERROR] ScheduleDeletion failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *authRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	query := `SELECT id FROM users WHERE deletion_scheduled_at <= $1`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Printf("[DB ERROR] GetUsersDueForDeletion failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return ids, nil
+}
+
+// DeleteUser hanya menghapus user yang jadwal penghapusannya sudah lewat, sehingga pembatalan
+// yang terjadi bersamaan tetap dihormati. Semua data user ikut terhapus lewat ON DELETE CASCADE.
+func (r *authRepository) DeleteUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Key file dikumpulkan sebelum baris lampiran dan export ikut terhapus
+	rows, err := dbTx.QueryContext(ctx, `
+		SELECT storage_key FROM transaction_attachments WHERE user_id = $1
+		UNION SELECT thumbnail_key FROM transaction_attachments WHERE user_id = $1 AND thumbnail_key IS NOT NULL
+		UNION SELECT medium_key FROM transaction_attachments WHERE user_id = $1 AND medium_key IS NOT NULL
+		UNION SELECT storage_key FROM account_exports WHERE user_id = $1 AND storage_key IS NOT NULL
+		UNION SELECT f.storage_key FROM receipt_files f
+			JOIN receipt_drafts d ON d.id = f.draft_id WHERE d.user_id = $1
+	`, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteUser failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, errx.ErrDatabaseError
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	result, err := dbTx.ExecContext(ctx,
+		`DELETE FROM users WHERE id = $1 AND deletion_scheduled_at <= NOW()`, userID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteUser failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+
+	// Tombstone sync dari baris yang ikut terhapus tidak akan pernah diambil lagi
+	if _, err := dbTx.ExecContext(ctx, `DELETE FROM sync_tombstones WHERE user_id = $1`, userID); err != nil {
+		log.Printf("[DB ERROR] DeleteUser failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	// File lama dengan key berbasis isi bisa dipakai bersama lampiran user lain
+	rows, err = dbTx.QueryContext(ctx, `
+		SELECT k FROM unnest($1::text[]) AS k
+		WHERE NOT EXISTS (
+			SELECT 1 FROM transaction_attachments
+			WHERE storage_key = k OR thumbnail_key = k OR medium_key = k
+		) AND NOT EXISTS (SELECT 1 FROM receipt_files WHERE storage_key = k)
+	`, pq.Array(keys))
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteUser failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	var orphaned []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, errx.ErrDatabaseError
+		}
+		orphaned = append(orphaned, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return orphaned, nil
+}
+
+func (r *authRepository) CreateExport(ctx context.Context, export *entity.AccountExport) error {
+	query := `
+		INSERT INTO account_exports (id, user_id, status, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, export.ID, export.UserID, export.Status, export.CreatedAt); err != nil {
+		log.Printf("[DB ERROR] CreateExport failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *authRepository) GetExport(ctx context.Context, id uuid.UUID) (*entity.AccountExport, error) {
+	query := `SELECT ` + exportColumns + ` FROM account_exports WHERE id = $1`
+
+	export, err := scanExport(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errx.ErrExportNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetExport failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return export, nil
+}
+
+func (r *authRepository) GetPendingExport(ctx context.Context, userID uuid.UUID) (*entity.AccountExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM account_exports
+		WHERE user_id = $1 AND status = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	export, err := scanExport(r.db.QueryRowContext(ctx, query, userID, entity.ExportPending))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetPendingExport failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return export, nil
+}
+
+// ClaimPendingExports mengambil export yang belum dibuat dan menandainya sedang diproses.
+// Klaim yang lebih lama dari 30 menit dianggap gagal dan bisa diambil lagi.
+func (r *authRepository) ClaimPendingExports(ctx context.Context, limit int) ([]*entity.AccountExport, error) {
+	query := `
+		UPDATE account_exports
+		SET claimed_at = NOW()
+		WHERE id IN (
+			SELECT id FROM account_exports
+			WHERE status = $1
+				AND (claimed_at IS NULL OR claimed_at < NOW() - INTERVAL '30 minutes')
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+
+	rows, err := r.db.QueryContext(ctx, query, entity.ExportPending, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] ClaimPendingExports failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var exports []*entity.AccountExport
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		exports = append(exports, export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return exports, nil
+}
+
+func (r *authRepository) CompleteExport(ctx context.Context, export *entity.AccountExport) error {
+	query := `
+		UPDATE account_exports
+		SET status = $1, storage_key = NULLIF($2, ''), size = $3, completed_at = $4, expires_at = $5
+		WHERE id = $6
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		export.Status,
+		export.StorageKey,
+		export.Size,
+		export.CompletedAt,
+		export.ExpiresAt,
+		export.ID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] CompleteExport failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *authRepository) DeleteExpiredExports(ctx context.Context, now time.Time) ([]string, error) {
+	query := `DELETE FROM account_exports WHERE expires_at < $1 RETURNING COALESCE(storage_key, '')`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteExpiredExports failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return keys, nil
+}
+
+// GetExportData membaca semua data user dalam satu snapshot sehingga isi arsip konsisten
+// walaupun user masih mengubah data saat export dibuat. Note transaksi didekripsi.
+func (r *authRepository) GetExportData(ctx context.Context, userID uuid.UUID) (*dto.AccountExportData, error) {
+	dbTx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	data := &dto.AccountExportData{
+		Transactions: []dto.ExportTransaction{},
+		Categories:   []dto.ExportCategory{},
+		Budgets:      []dto.ExportBudget{},
+		Alerts:       []dto.ExportAlert{},
+		AuditLogs:    []dto.ExportAuditLog{},
+	}
+
+	p := &data.Profile
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT id, email, name, created_at, updated_at FROM users WHERE id = $1`, userID,
+	).Scan(&p.ID, &p.Email, &p.Name, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errx.ErrUserNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetExportData failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	attachments := map[string][]dto.ExportAttachment{}
+	err = queryRows(ctx, dbTx, `
+		SELECT id, transaction_id, filename, content_type, size, storage_key, created_at
+		FROM transaction_attachments
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var a dto.ExportAttachment
+		var transactionID string
+		if err := row.Scan(&a.ID, &transactionID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt); err != nil {
+			return err
+		}
+		attachments[transactionID] = append(attachments[transactionID], a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, type, amount, COALESCE(category_id, ''), COALESCE(period, ''), COALESCE(note, ''),
+			TO_CHAR(date, 'YYYY-MM-DD'), tags, debt_id, deleted_at, created_at, updated_at
+		FROM transactions
+		WHERE user_id = $1
+		ORDER BY date, created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var t dto.ExportTransaction
+		if err := row.Scan(
+			&t.ID, &t.TransactionType, &t.Amount, &t.CategoryID, &t.Period, &t.Note,
+			&t.Date, pq.Array(&t.Tags), &t.DebtID, &t.DeletedAt, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		note, err := r.keyring.DecryptString(ctx, userID, t.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return err
+		}
+		t.Note = note
+		if t.Tags == nil {
+			t.Tags = []string{}
+		}
+		t.Attachments = attachments[t.ID]
+		if t.Attachments == nil {
+			t.Attachments = []dto.ExportAttachment{}
+		}
+
+		data.Transactions = append(data.Transactions, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Kategori berlaku untuk semua user, disertakan supaya category_id di transaksi bisa dibaca
+	err = queryRows(ctx, dbTx, `SELECT id, name FROM categories ORDER BY name`, nil, func(row rowScanner) error {
+		var c dto.ExportCategory
+		if err := row.Scan(&c.ID, &c.Name); err != nil {
+			return err
+		}
+		data.Categories = append(data.Categories, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, daily_limit, monthly_limit, yearly_limit, created_at, updated_at
+		FROM maximum_spends
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var b dto.ExportBudget
+		if err := row.Scan(&b.ID, &b.DailyLimit, &b.MonthlyLimit, &b.YearlyLimit, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return err
+		}
+		data.Budgets = append(data.Budgets, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, COALESCE(message, ''), COALESCE(type, ''), triggered_at
+		FROM alerts
+		WHERE user_id = $1
+		ORDER BY triggered_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var a dto.ExportAlert
+		if err := row.Scan(&a.ID, &a.Message, &a.Type, &a.TriggeredAt); err != nil {
+			return err
+		}
+		data.Alerts = append(data.Alerts, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, COALESCE(action, ''), created_at
+		FROM audit_logs
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var l dto.ExportAuditLog
+		if err := row.Scan(&l.ID, &l.Action, &l.CreatedAt); err != nil {
+			return err
+		}
+		data.AuditLogs = append(data.AuditLogs, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// queryRows menjalankan query dan memanggil scan untuk setiap baris hasilnya.
+func queryRows(ctx context.Context, dbTx *sql.Tx, query string, args []interface{}, scan func(row rowScanner) error) error {
+	rows, err := dbTx.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("[DB ERROR] GetExportData failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			log.Printf("[DB ERROR] GetExportData failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func scanExport(row rowScanner) (*entity.AccountExport, error) {
+	export := &entity.AccountExport{}
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.StorageKey,
+		&export.Size,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+		&export.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
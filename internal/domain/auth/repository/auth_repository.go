@@ -7,6 +7,7 @@ import (
 
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/dto"
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
 	"github.com/kenziehh/cashflow-be/pkg/errx"
 
 	"github.com/go-redis/redis/v8"
@@ -21,17 +22,34 @@ type AuthRepository interface {
 	DeleteToken(ctx context.Context, token string) error
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req *dto.UpdateProfileRequest) error
+	// ScheduleDeletion mengisi deletion_scheduled_at; nil membatalkan penghapusan
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, at *time.Time) error
+	GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+	// DeleteUser menghapus user beserta datanya dan mengembalikan key file di storage yang
+	// tidak lagi dipakai user lain
+	DeleteUser(ctx context.Context, userID uuid.UUID) ([]string, error)
+	CreateExport(ctx context.Context, export *entity.AccountExport) error
+	GetExport(ctx context.Context, id uuid.UUID) (*entity.AccountExport, error)
+	// GetPendingExport mengembalikan nil jika user tidak punya export yang sedang diproses
+	GetPendingExport(ctx context.Context, userID uuid.UUID) (*entity.AccountExport, error)
+	ClaimPendingExports(ctx context.Context, limit int) ([]*entity.AccountExport, error)
+	CompleteExport(ctx context.Context, export *entity.AccountExport) error
+	// DeleteExpiredExports menghapus export yang kedaluwarsa dan mengembalikan key arsipnya
+	DeleteExpiredExports(ctx context.Context, now time.Time) ([]string, error)
+	GetExportData(ctx context.Context, userID uuid.UUID) (*dto.AccountExportData, error)
 }
 
 type authRepository struct {
-	db    *sql.DB
-	redis *redis.Client
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
 }
 
-func NewAuthRepository(db *sql.DB, redis *redis.Client) AuthRepository {
+func NewAuthRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) AuthRepository {
 	return &authRepository{
-		db:    db,
-		redis: redis,
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
 	}
 }
 
@@ -59,7 +77,7 @@ func (r *authRepository) CreateUser(ctx context.Context, user *entity.User) erro
 
 func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
-		SELECT id, email, password, name, created_at, updated_at
+		SELECT id, email, password, name, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -70,6 +88,7 @@ func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*ent
 		&user.Email,
 		&user.Password,
 		&user.Name,
+		&user.DeletionScheduledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -87,7 +106,7 @@ func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*ent
 
 func (r *authRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	query := `
-		SELECT id, email, password, name, created_at, updated_at
+		SELECT id, email, password, name, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -98,6 +117,7 @@ func (r *authRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*entity
 		&user.Email,
 		&user.Password,
 		&user.Name,
+		&user.DeletionScheduledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
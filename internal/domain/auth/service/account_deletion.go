@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/auth/dto"
+	"github.com/kenziehh/cashflow-be/pkg/bcrypt"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// DeleteAccount meminta konfirmasi password lalu menjadwalkan penghapusan akun. Selama masa
+// tenggang akun masih bisa dipakai dan penghapusan bisa dibatalkan.
+func (s *authService) DeleteAccount(ctx context.Context, userID uuid.UUID, req *dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bcrypt.CheckPassword(req.Password, user.Password) {
+		return nil, errx.NewForbiddenError("Incorrect password")
+	}
+
+	// Permintaan ulang tidak memperpanjang masa tenggang
+	if user.DeletionScheduledAt != nil {
+		return &dto.AccountDeletionResponse{DeletionScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	at := time.Now().Add(s.deletionGrace)
+	if err := s.repo.ScheduleDeletion(ctx, userID, &at); err != nil {
+		return nil, err
+	}
+
+	return &dto.AccountDeletionResponse{DeletionScheduledAt: at}, nil
+}
+
+func (s *authService) CancelAccountDeletion(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return errx.NewConflictError("Account is not scheduled for deletion")
+	}
+
+	return s.repo.ScheduleDeletion(ctx, userID, nil)
+}
+
+// purgeDeletedAccounts menghapus permanen akun yang masa tenggangnya sudah lewat beserta
+// file lampiran dan arsip export miliknya.
+func (s *authService) purgeDeletedAccounts(ctx context.Context) error {
+	userIDs, err := s.repo.GetUsersDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		keys, err := s.repo.DeleteUser(ctx, userID)
+		if err != nil {
+			log.Printf("[AccountWorker] failed to delete account %s: %v", userID, err)
+			continue
+		}
+
+		for _, key := range keys {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				log.Printf("[Storage] failed to remove file %s: %v", key, err)
+			}
+		}
+		s.keyring.Forget(userID)
+
+		log.Printf("[AccountWorker] deleted account %s", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/auth/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/auth/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+const (
+	// Versi format arsip, dinaikkan jika struktur file JSON berubah
+	exportFormatVersion = 1
+	// Lama arsip export bisa diunduh sebelum dihapus dari storage
+	exportRetention = 7 * 24 * time.Hour
+
+	exportBatchSize = 5
+)
+
+// RequestExport membuat permintaan export baru. Jika masih ada export yang sedang diproses,
+// export tersebut yang dikembalikan supaya permintaan berulang tidak menumpuk.
+func (s *authService) RequestExport(ctx context.Context, userID uuid.UUID) (*entity.AccountExport, error) {
+	pending, err := s.repo.GetPendingExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return pending, nil
+	}
+
+	export := &entity.AccountExport{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    entity.ExportPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	s.queueExportWork()
+
+	return export, nil
+}
+
+func (s *authService) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*entity.AccountExport, error) {
+	export, err := s.repo.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.UserID != userID {
+		return nil, errx.ErrExportNotFound
+	}
+
+	return export, nil
+}
+
+func (s *authService) OpenExport(ctx context.Context, userID, exportID uuid.UUID) (*entity.AccountExport, io.ReadCloser, error) {
+	export, err := s.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case export.Status == entity.ExportPending:
+		return nil, nil, errx.NewConflictError("Export is not ready yet")
+	case export.Status == entity.ExportFailed:
+		return nil, nil, errx.NewConflictError("Export failed, please request a new one")
+	case export.ExpiresAt != nil && export.ExpiresAt.Before(time.Now()):
+		return nil, nil, errx.NewNotFoundError("Export has expired")
+	}
+
+	data, err := s.readFile(ctx, userID, export.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, errx.NewNotFoundError("Export has expired")
+	}
+	if err != nil {
+		log.Printf("[Storage] failed to read export %s: %v", export.StorageKey, err)
+		return nil, nil, errx.ErrInternalServer
+	}
+
+	return export, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// RunAccountWorker membuat arsip export, menghapus export kedaluwarsa dan menghapus akun yang
+// masa tenggangnya sudah lewat sampai ctx selesai. Worker bangun setiap interval, atau lebih
+// cepat saat ada permintaan export baru di instance ini.
+func (s *authService) RunAccountWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.processPendingExports(ctx)
+			if err != nil {
+				log.Println("[AccountWorker] failed:", err)
+			}
+			if err != nil || n < exportBatchSize {
+				break
+			}
+		}
+
+		if err := s.purgeExpiredExports(ctx); err != nil {
+			log.Println("[AccountWorker] failed to purge exports:", err)
+		}
+		if err := s.purgeDeletedAccounts(ctx); err != nil {
+			log.Println("[AccountWorker] failed to purge accounts:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.exportQueued:
+		}
+	}
+}
+
+func (s *authService) queueExportWork() {
+	select {
+	case s.exportQueued <- struct{}{}:
+	default:
+	}
+}
+
+func (s *authService) processPendingExports(ctx context.Context) (int, error) {
+	exports, err := s.repo.ClaimPendingExports(ctx, exportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if err := s.buildExport(ctx, export); err != nil {
+			log.Printf("[AccountWorker] failed to build export %s: %v", export.ID, err)
+
+			// Export gagal tetap diberi masa berlaku supaya ikut dibersihkan
+			now := time.Now()
+			expires := now.Add(exportRetention)
+			export.Status = entity.ExportFailed
+			export.CompletedAt = &now
+			export.ExpiresAt = &expires
+			if err := s.repo.CompleteExport(ctx, export); err != nil {
+				log.Printf("[AccountWorker] failed to mark export %s as failed: %v", export.ID, err)
+			}
+		}
+	}
+
+	return len(exports), nil
+}
+
+// buildExport menulis arsip zip berisi satu file JSON per jenis data beserta semua file
+// lampiran (sudah didekripsi), lalu menyimpannya terenkripsi seperti lampiran lainnya.
+func (s *authService) buildExport(ctx context.Context, export *entity.AccountExport) error {
+	data, err := s.repo.GetExportData(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for i := range data.Transactions {
+		for j := range data.Transactions[i].Attachments {
+			attachment := &data.Transactions[i].Attachments[j]
+
+			content, err := s.readFile(ctx, export.UserID, attachment.StorageKey)
+			if err == storage.ErrNotFound {
+				log.Printf("[AccountWorker] attachment %s is missing from storage", attachment.ID)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			// Foto dan PDF sudah terkompresi, jadi disimpan tanpa kompresi ulang
+			attachment.Path = "attachments/" + attachment.ID + "-" + attachment.Filename
+			w, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     attachment.Path,
+				Method:   zip.Store,
+				Modified: attachment.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(content); err != nil {
+				return err
+			}
+		}
+	}
+
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"manifest.json", dto.ExportManifest{
+			Version:    exportFormatVersion,
+			ExportedAt: time.Now(),
+			UserID:     export.UserID.String(),
+		}},
+		{"profile.json", data.Profile},
+		{"transactions.json", data.Transactions},
+		{"categories.json", data.Categories},
+		{"budgets.json", data.Budgets},
+		{"alerts.json", data.Alerts},
+		{"audit_logs.json", data.AuditLogs},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.value); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	encrypted, err := s.keyring.Encrypt(ctx, export.UserID, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("exports/%s/%s.zip", export.UserID, export.ID)
+	if err := s.storage.Put(ctx, key, encrypted, "application/zip"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expires := now.Add(exportRetention)
+	export.Status = entity.ExportReady
+	export.StorageKey = key
+	export.Size = int64(buf.Len())
+	export.CompletedAt = &now
+	export.ExpiresAt = &expires
+
+	return s.repo.CompleteExport(ctx, export)
+}
+
+func (s *authService) purgeExpiredExports(ctx context.Context) error {
+	keys, err := s.repo.DeleteExpiredExports(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("[Storage] failed to remove export %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// readFile membaca dan mendekripsi file milik user dari storage.
+func (s *authService) readFile(ctx context.Context, userID uuid.UUID, key string) ([]byte, error) {
+	r, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.keyring.Decrypt(ctx, userID, data)
+}
@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/dto"
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/entity"
 	"github.com/kenziehh/cashflow-be/internal/domain/auth/repository"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
 	"github.com/kenziehh/cashflow-be/pkg/errx"
 
 	"github.com/kenziehh/cashflow-be/pkg/bcrypt"
@@ -21,15 +24,33 @@ type AuthService interface {
 	Logout(ctx context.Context, token string) error
 	GetProfile(ctx context.Context, userID uuid.UUID) (*dto.UserProfile, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req *dto.UpdateProfileRequest) error
+	// DeleteAccount menjadwalkan penghapusan akun setelah masa tenggang
+	DeleteAccount(ctx context.Context, userID uuid.UUID, req *dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error)
+	CancelAccountDeletion(ctx context.Context, userID uuid.UUID) error
+	RequestExport(ctx context.Context, userID uuid.UUID) (*entity.AccountExport, error)
+	GetExport(ctx context.Context, userID, exportID uuid.UUID) (*entity.AccountExport, error)
+	// OpenExport mengembalikan isi arsip zip; pemanggil wajib menutup reader
+	OpenExport(ctx context.Context, userID, exportID uuid.UUID) (*entity.AccountExport, io.ReadCloser, error)
+	RunAccountWorker(ctx context.Context, interval time.Duration)
 }
 
 type authService struct {
-	repo repository.AuthRepository
+	repo          repository.AuthRepository
+	storage       storage.FileStorage
+	keyring       *encryption.Keyring
+	deletionGrace time.Duration
+
+	exportQueued chan struct{}
 }
 
-func NewAuthService(repo repository.AuthRepository) AuthService {
+func NewAuthService(repo repository.AuthRepository, storage storage.FileStorage, keyring *encryption.Keyring, deletionGrace time.Duration) AuthService {
 	return &authService{
-		repo: repo,
+		repo:          repo,
+		storage:       storage,
+		keyring:       keyring,
+		deletionGrace: deletionGrace,
+
+		exportQueued: make(chan struct{}, 1),
 	}
 }
 
@@ -107,9 +128,10 @@ func (s *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Au
 	return &dto.AuthResponse{
 		Token: token,
 		User: dto.UserProfile{
-			ID:    user.ID.String(),
-			Email: user.Email,
-			Name:  user.Name,
+			ID:                  user.ID.String(),
+			Email:               user.Email,
+			Name:                user.Name,
+			DeletionScheduledAt: user.DeletionScheduledAt,
 		},
 	}, nil
 }
@@ -125,9 +147,10 @@ func (s *authService) GetProfile(ctx context.Context, userID uuid.UUID) (*dto.Us
 	}
 
 	return &dto.UserProfile{
-		ID:    user.ID.String(),
-		Email: user.Email,
-		Name:  user.Name,
+		ID:                  user.ID.String(),
+		Email:               user.Email,
+		Name:                user.Name,
+		DeletionScheduledAt: user.DeletionScheduledAt,
 	}, nil
 }
 
@@ -0,0 +1,216 @@
+package dto
+
+import "time"
+
+const (
+	// BackupFormat menandai dokumen JSON sebagai backup aplikasi ini
+	BackupFormat = "cashflow-backup"
+	// BackupVersion adalah versi skema backup yang dibuat; restore menerima versi 1 sampai versi ini.
+	// Versi 2 menambahkan hutang/piutang, goal, tagihan dan rule beserta link ke transaksinya.
+	BackupVersion = 2
+
+	RestoreModeMerge   = "merge"
+	RestoreModeReplace = "replace"
+)
+
+// Backup adalah isi file backup (lihat bagian "Format Backup" di README). ID di dalam
+// backup hanya berlaku di dalam file tersebut; saat restore semua data mendapat ID baru
+// dan link antar data (cicilan hutang, setoran goal, pembayaran tagihan) dipetakan ke ID baru.
+type Backup struct {
+	Format       string              `json:"format" example:"cashflow-backup"`
+	Version      int                 `json:"version" example:"2"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Categories   []BackupCategory    `json:"categories"`
+	Budget       *BackupBudget       `json:"budget"`
+	Transactions []BackupTransaction `json:"transactions"`
+
+	// Sejak versi 2
+	Counterparties []BackupCounterparty `json:"counterparties"`
+	Debts          []BackupDebt         `json:"debts"`
+	Goals          []BackupGoal         `json:"goals"`
+	Bills          []BackupBill         `json:"bills"`
+	Rules          []BackupRule         `json:"rules"`
+	// first atau all, kosong jika user belum pernah mengaturnya
+	RuleMatchMode string `json:"rule_match_mode,omitempty" validate:"omitempty,oneof=first all"`
+}
+
+type BackupCategory struct {
+	ID   string `json:"id" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// BackupBudget adalah batas pengeluaran (maximum spend) user, null berarti tidak dibatasi.
+type BackupBudget struct {
+	DailyLimit   *float64 `json:"daily_limit" validate:"omitempty,gte=0,lt=10000000000"`
+	MonthlyLimit *float64 `json:"monthly_limit" validate:"omitempty,gte=0,lt=10000000000"`
+	YearlyLimit  *float64 `json:"yearly_limit" validate:"omitempty,gte=0,lt=10000000000"`
+}
+
+type BackupTransaction struct {
+	ID              string    `json:"id" validate:"required,max=64"`
+	TransactionType string    `json:"transaction_type" validate:"required,oneof=income expense"`
+	Amount          float64   `json:"amount" validate:"required,gt=0,lt=10000000000"`
+	CategoryID      string    `json:"category_id,omitempty"`
+	Period          string    `json:"period" validate:"required,oneof=daily weekly monthly yearly"`
+	Note            string    `json:"note"`
+	Date            string    `json:"date" validate:"required,datetime=2006-01-02"`
+	Tags            []string  `json:"tags" validate:"omitempty,dive,max=50"`
+	CreatedAt       time.Time `json:"created_at"`
+	// ID hutang di backup jika transaksi ini cicilan hutang/piutang (sejak versi 2)
+	DebtID string `json:"debt_id,omitempty" validate:"max=64"`
+}
+
+type BackupCounterparty struct {
+	ID      string `json:"id" validate:"required,max=64"`
+	Name    string `json:"name" validate:"required,max=100"`
+	Contact string `json:"contact,omitempty" validate:"max=100"`
+}
+
+type BackupDebt struct {
+	ID             string  `json:"id" validate:"required,max=64"`
+	CounterpartyID string  `json:"counterparty_id" validate:"required,max=64"`
+	Direction      string  `json:"direction" validate:"required,oneof=payable receivable"`
+	Principal      float64 `json:"principal" validate:"required,gt=0,lt=10000000000"`
+	Note           string  `json:"note"`
+	StartDate      string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	DueDate        *string `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type BackupGoal struct {
+	ID           string  `json:"id" validate:"required,max=64"`
+	Name         string  `json:"name" validate:"required,max=100"`
+	TargetAmount float64 `json:"target_amount" validate:"required,gt=0,lt=10000000000"`
+	TargetDate   string  `json:"target_date" validate:"required,datetime=2006-01-02"`
+	CategoryID   string  `json:"category_id,omitempty"`
+	// ID transaksi di backup yang di-link manual ke goal
+	TransactionIDs []string `json:"transaction_ids"`
+	// Transaksi dengan kategori goal dihitung sejak goal dibuat, jadi waktunya ikut dipulihkan
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BackupBill struct {
+	ID          string  `json:"id" validate:"required,max=64"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Amount      float64 `json:"amount" validate:"required,gt=0,lt=10000000000"`
+	CategoryID  string  `json:"category_id,omitempty"`
+	Recurrence  string  `json:"recurrence" validate:"required,oneof=none weekly monthly yearly"`
+	NextDueDate string  `json:"next_due_date" validate:"required,datetime=2006-01-02"`
+	DueDay      int     `json:"due_day,omitempty" validate:"omitempty,min=1,max=31"`
+	Note        string  `json:"note"`
+	Active      bool    `json:"active"`
+	// Pembayaran tagihan, transaction_id menunjuk transaksi di backup
+	Payments []BackupBillPayment `json:"payments"`
+}
+
+type BackupBillPayment struct {
+	TransactionID string    `json:"transaction_id"`
+	DueDate       string    `json:"due_date" validate:"required,datetime=2006-01-02"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+type BackupRule struct {
+	ID              string   `json:"id" validate:"required,max=64"`
+	Name            string   `json:"name" validate:"required,max=100"`
+	Priority        int      `json:"priority"`
+	Active          bool     `json:"active"`
+	NoteContains    string   `json:"note_contains,omitempty" validate:"max=255"`
+	NoteRegex       string   `json:"note_regex,omitempty" validate:"max=255"`
+	MinAmount       *float64 `json:"min_amount,omitempty" validate:"omitempty,gte=0,lt=10000000000"`
+	MaxAmount       *float64 `json:"max_amount,omitempty" validate:"omitempty,gte=0,lt=10000000000"`
+	TransactionType string   `json:"transaction_type,omitempty" validate:"omitempty,oneof=income expense"`
+	SetCategoryID   string   `json:"set_category_id,omitempty"`
+	AddTags         []string `json:"add_tags" validate:"omitempty,dive,max=50"`
+	NoteRewrite     *string  `json:"note_rewrite,omitempty" validate:"omitempty,max=255"`
+}
+
+type RestoreParams struct {
+	// merge (default) menambahkan data ke akun, replace memindahkan transaksi yang ada ke trash lebih dulu
+	Mode string `query:"mode" validate:"omitempty,oneof=merge replace"`
+	// Default true: hanya laporan yang dikembalikan, tidak ada data yang diubah
+	DryRun *bool `query:"dry_run"`
+}
+
+// RestorePlan adalah hasil pemetaan backup yang ditulis repository dalam satu transaksi
+// database. Semua ID di dalamnya sudah berupa ID baru atau ID data yang sudah ada di akun.
+type RestorePlan struct {
+	// Transaksi yang tidak punya link dan tidak berada di periode terkunci dipindah ke trash
+	Replace bool
+	// Kategori dari backup yang belum ada dan dibuat ulang
+	Categories     []BackupCategory
+	Budget         *BackupBudget
+	RuleMatchMode  string
+	Counterparties []BackupCounterparty
+	Debts          []BackupDebt
+	Goals          []BackupGoal
+	Bills          []BackupBill
+	Rules          []BackupRule
+	Transactions   []BackupTransaction
+	// Link goal dan tagihan ke transaksi, boleh menunjuk goal/tagihan/transaksi yang sudah ada
+	GoalContributions []RestoreGoalContribution
+	BillPayments      []RestoreBillPayment
+}
+
+type RestoreGoalContribution struct {
+	GoalID        string
+	TransactionID string
+}
+
+type RestoreBillPayment struct {
+	BillID        string
+	TransactionID string
+	DueDate       string
+	PaidAt        time.Time
+}
+
+const (
+	ConflictInvalid          = "invalid"
+	ConflictDuplicateID      = "duplicate_id"
+	ConflictDuplicate        = "duplicate"
+	ConflictUnknownCategory  = "unknown_category"
+	ConflictUnknownReference = "unknown_reference"
+	ConflictLockedPeriod     = "locked_period"
+	ConflictBudgetExists     = "budget_exists"
+)
+
+// RestoreConflict menjelaskan satu hal di backup yang tidak bisa dipulihkan apa adanya.
+type RestoreConflict struct {
+	// invalid, duplicate_id, duplicate, unknown_category, unknown_reference, locked_period atau budget_exists
+	Kind string `json:"kind" example:"duplicate"`
+	// ID data di dalam backup, kosong untuk konflik budget
+	SourceID string `json:"source_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// RestoreCount merangkum satu jenis data: yang dibuat baru, dan yang dicocokkan dengan data
+// yang sudah ada di akun (berdasarkan nama) sehingga dipakai apa adanya.
+type RestoreCount struct {
+	InBackup int `json:"in_backup"`
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+type RestoreReport struct {
+	Mode    string `json:"mode" example:"merge"`
+	DryRun  bool   `json:"dry_run"`
+	Version int    `json:"version"`
+	// Jumlah transaksi di backup, yang akan/sudah dibuat, dilewati, dan transaksi lama yang dipindah ke trash
+	TransactionsInBackup int `json:"transactions_in_backup"`
+	TransactionsCreated  int `json:"transactions_created"`
+	TransactionsSkipped  int `json:"transactions_skipped"`
+	TransactionsReplaced int `json:"transactions_replaced"`
+	// Transaksi lama yang tetap dipertahankan pada mode replace karena punya link (hutang, goal,
+	// tagihan, lampiran) atau berada di periode yang sudah direkonsiliasi
+	TransactionsKept int `json:"transactions_kept"`
+	// Kategori backup yang dicocokkan dengan kategori berbeda ID tetapi bernama sama
+	CategoriesRemapped int `json:"categories_remapped"`
+	// Kategori backup yang belum ada dan dibuat ulang
+	CategoriesCreated int          `json:"categories_created"`
+	Counterparties    RestoreCount `json:"counterparties"`
+	Debts             RestoreCount `json:"debts"`
+	Goals             RestoreCount `json:"goals"`
+	Bills             RestoreCount `json:"bills"`
+	Rules             RestoreCount `json:"rules"`
+	BudgetRestored    bool         `json:"budget_restored"`
+	// Selalu berisi array, kosong jika tidak ada konflik
+	Conflicts []RestoreConflict `json:"conflicts"`
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/backup/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/backup/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type BackupHandler struct {
+	service  service.BackupService
+	validate *validator.Validate
+}
+
+func NewBackupHandler(service service.BackupService) *BackupHandler {
+	return &BackupHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateBackup godoc
+// @Summary Download a backup
+// @Description Download the categories, maximum spend, active transactions, debts, goals, bills and rules as a versioned JSON backup that can be restored with POST /backup/restore. Attachment files are not included; use the account export for a full copy of the data.
+// @Tags backup
+// @Produce json
+// @Success 200 {object} dto.Backup
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /backup [get]
+func (h *BackupHandler) CreateBackup(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	backup, err := h.service.CreateBackup(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "cashflow-backup-"+backup.CreatedAt.Format("20060102")+".json"))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.JSON(backup)
+}
+
+// Restore godoc
+// @Summary Restore a backup
+// @Description Validate a backup file and restore it into the current account. Everything gets new IDs and the links between debts, goals, bills and their transactions are remapped. Categories are matched by ID, then by name, and missing ones are recreated; counterparties, goals, bills and rules that already exist with the same name are reused. merge adds the backup to the account and skips transactions that already exist; replace moves the current transactions to the trash first, except those linked to a debt, goal, bill, attachment or reconciliation and those in a locked period. By default this is a dry run that only returns the report of what would change and the conflicts; send dry_run=false to apply it.
+// @Tags backup
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Backup file"
+// @Param mode query string false "merge (default) or replace"
+// @Param dry_run query bool false "Only report, default true"
+// @Success 200 {object} response.Response{data=dto.RestoreReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /backup/restore [post]
+func (h *BackupHandler) Restore(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.RestoreParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return errx.NewBadRequestError("Backup file is required")
+	}
+	f, err := file.Open()
+	if err != nil {
+		return errx.NewBadRequestError("Failed to read uploaded file")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return errx.NewBadRequestError("Failed to read uploaded file")
+	}
+
+	report, err := h.service.Restore(c.Context(), userID, data, params)
+	if err != nil {
+		return err
+	}
+
+	message := "Backup restored successfully"
+	if report.DryRun {
+		message = "Backup checked successfully, nothing was changed"
+	}
+	return c.JSON(response.SuccessResponse(message, report))
+}
@@ -0,0 +1,561 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/backup/dto"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+)
+
+type BackupRepository interface {
+	// GetBackupData membaca kategori, budget, transaksi yang tidak ada di trash, hutang, goal,
+	// tagihan dan rule user dalam satu snapshot. ID yang dikembalikan adalah ID di database dan
+	// note transaksi sudah didekripsi.
+	GetBackupData(ctx context.Context, userID uuid.UUID) (*dto.Backup, error)
+	// GetKeptTransactionIDs mengembalikan transaksi aktif yang tidak disentuh mode replace karena
+	// punya link atau berada di periode yang dikunci
+	GetKeptTransactionIDs(ctx context.Context, userID uuid.UUID) (map[string]bool, error)
+	// GetLockedDates mengembalikan tanggal (YYYY-MM-DD) yang berada di periode rekonsiliasi terkunci
+	GetLockedDates(ctx context.Context, userID uuid.UUID, dates []string) (map[string]bool, error)
+	// Restore menulis hasil restore dalam satu transaksi database. Jika plan.Replace, transaksi
+	// yang bisa diganti dipindah ke trash lebih dulu dan jumlahnya dikembalikan.
+	Restore(ctx context.Context, userID uuid.UUID, plan *dto.RestorePlan) (int, error)
+}
+
+type backupRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewBackupRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) BackupRepository {
+	return &backupRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+// keptCondition adalah transaksi t yang dipertahankan mode replace. Transaksi ini direferensikan
+// data lain (hutang, goal, tagihan, lampiran, rekonsiliasi) yang tidak ikut diganti, atau
+// dilindungi trigger kunci rekonsiliasi.
+const keptCondition = `(
+	t.debt_id IS NOT NULL
+	OR EXISTS (SELECT 1 FROM goal_contributions gc WHERE gc.transaction_id = t.id)
+	OR EXISTS (SELECT 1 FROM bill_payments bp WHERE bp.transaction_id = t.id)
+	OR EXISTS (SELECT 1 FROM transaction_attachments ta WHERE ta.transaction_id = t.id)
+	OR EXISTS (SELECT 1 FROM reconciliation_lines rl WHERE rl.transaction_id = t.id)
+	OR is_period_locked(t.user_id, t.date)
+)`
+
+func (r *backupRepository) GetBackupData(ctx context.Context, userID uuid.UUID) (*dto.Backup, error) {
+	dbTx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	data := &dto.Backup{
+		Categories:     []dto.BackupCategory{},
+		Transactions:   []dto.BackupTransaction{},
+		Counterparties: []dto.BackupCounterparty{},
+		Debts:          []dto.BackupDebt{},
+		Goals:          []dto.BackupGoal{},
+		Bills:          []dto.BackupBill{},
+		Rules:          []dto.BackupRule{},
+	}
+
+	err = queryRows(ctx, dbTx, `SELECT id, name FROM categories ORDER BY name`, nil, func(row rowScanner) error {
+		var c dto.BackupCategory
+		if err := row.Scan(&c.ID, &c.Name); err != nil {
+			return err
+		}
+		data.Categories = append(data.Categories, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &dto.BackupBudget{}
+	err = dbTx.QueryRowContext(ctx,
+		`SELECT daily_limit, monthly_limit, yearly_limit FROM maximum_spends WHERE user_id = $1`, userID,
+	).Scan(&budget.DailyLimit, &budget.MonthlyLimit, &budget.YearlyLimit)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("[DB ERROR] GetBackupData failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	if err == nil {
+		data.Budget = budget
+	}
+
+	err = dbTx.QueryRowContext(ctx, `SELECT match_mode FROM rule_settings WHERE user_id = $1`, userID).Scan(&data.RuleMatchMode)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("[DB ERROR] GetBackupData failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, type, amount, COALESCE(category_id, ''), COALESCE(period, ''), COALESCE(note, ''),
+			TO_CHAR(date, 'YYYY-MM-DD'), tags, COALESCE(debt_id::TEXT, ''), created_at
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY date, created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var t dto.BackupTransaction
+		if err := row.Scan(
+			&t.ID, &t.TransactionType, &t.Amount, &t.CategoryID, &t.Period, &t.Note,
+			&t.Date, pq.Array(&t.Tags), &t.DebtID, &t.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		note, err := r.keyring.DecryptString(ctx, userID, t.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return err
+		}
+		t.Note = note
+		if t.Tags == nil {
+			t.Tags = []string{}
+		}
+		data.Transactions = append(data.Transactions, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, name, COALESCE(contact, '')
+		FROM counterparties
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var c dto.BackupCounterparty
+		if err := row.Scan(&c.ID, &c.Name, &c.Contact); err != nil {
+			return err
+		}
+		data.Counterparties = append(data.Counterparties, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, counterparty_id, direction, principal, COALESCE(note, ''),
+			TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(due_date, 'YYYY-MM-DD')
+		FROM debts
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var d dto.BackupDebt
+		if err := row.Scan(&d.ID, &d.CounterpartyID, &d.Direction, &d.Principal, &d.Note, &d.StartDate, &d.DueDate); err != nil {
+			return err
+		}
+
+		note, err := r.keyring.DecryptString(ctx, userID, d.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return err
+		}
+		d.Note = note
+		data.Debts = append(data.Debts, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hanya link ke transaksi yang ikut di backup (tidak ada di trash)
+	err = queryRows(ctx, dbTx, `
+		SELECT g.id, g.name, g.target_amount, TO_CHAR(g.target_date, 'YYYY-MM-DD'), COALESCE(g.category_id, ''),
+			ARRAY(
+				SELECT gc.transaction_id::TEXT
+				FROM goal_contributions gc
+				JOIN transactions t ON t.id = gc.transaction_id AND t.deleted_at IS NULL
+				WHERE gc.goal_id = g.id
+				ORDER BY gc.created_at
+			),
+			g.created_at
+		FROM goals g
+		WHERE g.user_id = $1
+		ORDER BY g.created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var g dto.BackupGoal
+		if err := row.Scan(&g.ID, &g.Name, &g.TargetAmount, &g.TargetDate, &g.CategoryID, pq.Array(&g.TransactionIDs), &g.CreatedAt); err != nil {
+			return err
+		}
+		if g.TransactionIDs == nil {
+			g.TransactionIDs = []string{}
+		}
+		data.Goals = append(data.Goals, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bills := map[string]int{}
+	err = queryRows(ctx, dbTx, `
+		SELECT id, name, amount, COALESCE(category_id, ''), recurrence, TO_CHAR(next_due_date, 'YYYY-MM-DD'),
+			due_day, COALESCE(note, ''), active
+		FROM bills
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		b := dto.BackupBill{Payments: []dto.BackupBillPayment{}}
+		if err := row.Scan(&b.ID, &b.Name, &b.Amount, &b.CategoryID, &b.Recurrence, &b.NextDueDate, &b.DueDay, &b.Note, &b.Active); err != nil {
+			return err
+		}
+
+		note, err := r.keyring.DecryptString(ctx, userID, b.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return err
+		}
+		b.Note = note
+		bills[b.ID] = len(data.Bills)
+		data.Bills = append(data.Bills, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT bp.bill_id, bp.transaction_id, TO_CHAR(bp.due_date, 'YYYY-MM-DD'), bp.paid_at
+		FROM bill_payments bp
+		JOIN bills b ON b.id = bp.bill_id
+		JOIN transactions t ON t.id = bp.transaction_id AND t.deleted_at IS NULL
+		WHERE b.user_id = $1
+		ORDER BY bp.due_date
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var billID string
+		var p dto.BackupBillPayment
+		if err := row.Scan(&billID, &p.TransactionID, &p.DueDate, &p.PaidAt); err != nil {
+			return err
+		}
+		i := bills[billID]
+		data.Bills[i].Payments = append(data.Bills[i].Payments, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, dbTx, `
+		SELECT id, name, priority, active, COALESCE(note_contains, ''), COALESCE(note_regex, ''),
+			min_amount, max_amount, COALESCE(transaction_type::TEXT, ''), COALESCE(set_category_id, ''),
+			add_tags, note_rewrite
+		FROM rules
+		WHERE user_id = $1
+		ORDER BY priority, created_at
+	`, []interface{}{userID}, func(row rowScanner) error {
+		var rule dto.BackupRule
+		if err := row.Scan(
+			&rule.ID, &rule.Name, &rule.Priority, &rule.Active, &rule.NoteContains, &rule.NoteRegex,
+			&rule.MinAmount, &rule.MaxAmount, &rule.TransactionType, &rule.SetCategoryID,
+			pq.Array(&rule.AddTags), &rule.NoteRewrite,
+		); err != nil {
+			return err
+		}
+		if rule.AddTags == nil {
+			rule.AddTags = []string{}
+		}
+		data.Rules = append(data.Rules, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *backupRepository) GetKeptTransactionIDs(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	query := `SELECT t.id FROM transactions t WHERE t.user_id = $1 AND t.deleted_at IS NULL AND ` + keptCondition
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetKeptTransactionIDs failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	kept := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		kept[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return kept, nil
+}
+
+func (r *backupRepository) GetLockedDates(ctx context.Context, userID uuid.UUID, dates []string) (map[string]bool, error) {
+	locked := map[string]bool{}
+	if len(dates) == 0 {
+		return locked, nil
+	}
+
+	query := `
+		SELECT DISTINCT TO_CHAR(d, 'YYYY-MM-DD')
+		FROM UNNEST($2::DATE[]) AS d
+		WHERE is_period_locked($1, d)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(dates))
+	if err != nil {
+		log.Printf("[DB ERROR] GetLockedDates failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		locked[date] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return locked, nil
+}
+
+func (r *backupRepository) Restore(ctx context.Context, userID uuid.UUID, plan *dto.RestorePlan) (int, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	replaced := 0
+	if plan.Replace {
+		result, err := dbTx.ExecContext(ctx, `
+			UPDATE transactions t
+			SET deleted_at = NOW(), version = version + 1
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL AND NOT `+keptCondition, userID)
+		if err != nil {
+			return 0, errx.FromWrite("Restore", err)
+		}
+		n, _ := result.RowsAffected()
+		replaced = int(n)
+	}
+
+	now := time.Now()
+	exec := func(query string, args ...interface{}) error {
+		if _, err := dbTx.ExecContext(ctx, query, args...); err != nil {
+			log.Printf("[DB ERROR] Restore failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+		return nil
+	}
+
+	for _, c := range plan.Categories {
+		if err := exec(`INSERT INTO categories (id, name) VALUES ($1, $2)`, c.ID, c.Name); err != nil {
+			return 0, err
+		}
+	}
+
+	if plan.Budget != nil {
+		err := exec(`
+			INSERT INTO maximum_spends (id, user_id, daily_limit, monthly_limit, yearly_limit)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id) DO UPDATE
+			SET daily_limit = EXCLUDED.daily_limit,
+				monthly_limit = EXCLUDED.monthly_limit,
+				yearly_limit = EXCLUDED.yearly_limit,
+				updated_at = NOW()
+		`, ulid.Make().String(), userID, plan.Budget.DailyLimit, plan.Budget.MonthlyLimit, plan.Budget.YearlyLimit)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if plan.RuleMatchMode != "" {
+		query := `
+			INSERT INTO rule_settings (user_id, match_mode, updated_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO NOTHING
+		`
+		if plan.Replace {
+			query = `
+				INSERT INTO rule_settings (user_id, match_mode, updated_at)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (user_id) DO UPDATE
+				SET match_mode = EXCLUDED.match_mode,
+					updated_at = EXCLUDED.updated_at
+			`
+		}
+		if err := exec(query, userID, plan.RuleMatchMode, now); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, c := range plan.Counterparties {
+		err := exec(`
+			INSERT INTO counterparties (id, user_id, name, contact, created_at, updated_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $5)
+		`, c.ID, userID, c.Name, c.Contact, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, d := range plan.Debts {
+		note, err := r.keyring.EncryptString(ctx, userID, d.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+			return 0, errx.ErrInternalServer
+		}
+
+		err = exec(`
+			INSERT INTO debts (id, user_id, counterparty_id, direction, principal, note, start_date, due_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		`, d.ID, userID, d.CounterpartyID, d.Direction, d.Principal, note, d.StartDate, d.DueDate, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, g := range plan.Goals {
+		err := exec(`
+			INSERT INTO goals (id, user_id, name, target_amount, target_date, category_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		`, g.ID, userID, g.Name, g.TargetAmount, g.TargetDate, g.CategoryID, g.CreatedAt, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, b := range plan.Bills {
+		note, err := r.keyring.EncryptString(ctx, userID, b.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+			return 0, errx.ErrInternalServer
+		}
+
+		err = exec(`
+			INSERT INTO bills (id, user_id, name, amount, category_id, recurrence, next_due_date, due_day, note, active, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, COALESCE(NULLIF($8, 0), EXTRACT(DAY FROM $7::DATE)), $9, $10, $11, $11)
+		`, b.ID, userID, b.Name, b.Amount, b.CategoryID, b.Recurrence, b.NextDueDate, b.DueDay, note, b.Active, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, rule := range plan.Rules {
+		err := exec(`
+			INSERT INTO rules (id, user_id, name, priority, active, note_contains, note_regex, min_amount, max_amount,
+				transaction_type, set_category_id, add_tags, note_rewrite, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9,
+				NULLIF($10, '')::transaction_type, NULLIF($11, ''), $12, $13, $14, $14)
+		`,
+			rule.ID, userID, rule.Name, rule.Priority, rule.Active, rule.NoteContains, rule.NoteRegex,
+			rule.MinAmount, rule.MaxAmount, rule.TransactionType, rule.SetCategoryID, pq.Array(rule.AddTags),
+			rule.NoteRewrite, now,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, `
+		INSERT INTO transactions (id, user_id, amount, type, category_id, note, period, date, created_at, updated_at, tags, debt_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11, NULLIF($12, '')::UUID)
+	`)
+	if err != nil {
+		log.Printf("[DB ERROR] Restore failed: %v\n", err)
+		return 0, errx.ErrDatabaseError
+	}
+	defer stmt.Close()
+
+	for _, t := range plan.Transactions {
+		note, err := r.keyring.EncryptString(ctx, userID, t.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+			return 0, errx.ErrInternalServer
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			t.ID, userID, t.Amount, t.TransactionType, t.CategoryID, note, t.Period, t.Date,
+			t.CreatedAt, now, pq.Array(t.Tags), t.DebtID,
+		); err != nil {
+			return 0, errx.FromWrite("Restore", err)
+		}
+	}
+
+	for _, c := range plan.GoalContributions {
+		err := exec(`
+			INSERT INTO goal_contributions (goal_id, transaction_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, c.GoalID, c.TransactionID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, p := range plan.BillPayments {
+		err := exec(`
+			INSERT INTO bill_payments (bill_id, transaction_id, due_date, paid_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, p.BillID, p.TransactionID, p.DueDate, p.PaidAt)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, errx.ErrDatabaseError
+	}
+
+	return replaced, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func queryRows(ctx context.Context, dbTx *sql.Tx, query string, args []interface{}, scan func(row rowScanner) error) error {
+	rows, err := dbTx.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("[DB ERROR] GetBackupData failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			log.Printf("[DB ERROR] GetBackupData failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/backup/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/backup/repository"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/oklog/ulid/v2"
+)
+
+type BackupService interface {
+	CreateBackup(ctx context.Context, userID uuid.UUID) (*dto.Backup, error)
+	// Restore memvalidasi file backup lalu memulihkannya ke akun user. Dengan dry run hanya
+	// laporan yang dikembalikan tanpa mengubah data.
+	Restore(ctx context.Context, userID uuid.UUID, data []byte, params dto.RestoreParams) (*dto.RestoreReport, error)
+}
+
+type backupService struct {
+	repo     repository.BackupRepository
+	validate *validator.Validate
+}
+
+func NewBackupService(repo repository.BackupRepository) BackupService {
+	return &backupService{
+		repo:     repo,
+		validate: validator.New(),
+	}
+}
+
+func (s *backupService) CreateBackup(ctx context.Context, userID uuid.UUID) (*dto.Backup, error) {
+	backup, err := s.repo.GetBackupData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	backup.Format = dto.BackupFormat
+	backup.Version = dto.BackupVersion
+	backup.CreatedAt = time.Now()
+	return backup, nil
+}
+
+// restoreState menyimpan pemetaan ID backup ke ID di akun (baru atau yang sudah ada) selama
+// satu restore, supaya link antar data bisa diarahkan ke ID yang benar.
+type restoreState struct {
+	report *dto.RestoreReport
+	plan   *dto.RestorePlan
+	// Transaksi di akun yang boleh "menutup" transaksi backup yang sama, per fingerprint
+	existing       map[string][]string
+	categories     *categoryPlanner
+	counterparties map[string]string
+	debts          map[string]string
+	transactions   map[string]string
+}
+
+func (s *backupService) Restore(ctx context.Context, userID uuid.UUID, data []byte, params dto.RestoreParams) (*dto.RestoreReport, error) {
+	backup, err := s.parseBackup(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := params.Mode
+	if mode == "" {
+		mode = dto.RestoreModeMerge
+	}
+	report := &dto.RestoreReport{
+		Mode:                 mode,
+		DryRun:               params.DryRun == nil || *params.DryRun,
+		Version:              backup.Version,
+		TransactionsInBackup: len(backup.Transactions),
+		Conflicts:            []dto.RestoreConflict{},
+	}
+
+	current, err := s.repo.GetBackupData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &restoreState{
+		report:         report,
+		plan:           &dto.RestorePlan{Replace: mode == dto.RestoreModeReplace},
+		existing:       map[string][]string{},
+		categories:     newCategoryPlanner(current.Categories, backup.Categories),
+		counterparties: map[string]string{},
+		debts:          map[string]string{},
+		transactions:   map[string]string{},
+	}
+
+	// Mode merge melewati transaksi yang sudah ada di akun. Mode replace memindahkan transaksi
+	// ke trash kecuali yang punya link atau berada di periode terkunci; transaksi tersebut tetap
+	// dipakai untuk mencocokkan transaksi backup supaya tidak tercatat dua kali. Setiap transaksi
+	// yang ada hanya bisa "menutup" satu transaksi backup, sehingga transaksi kembar yang memang
+	// tercatat dua kali tetap dipulihkan dua kali.
+	var kept map[string]bool
+	if st.plan.Replace {
+		if kept, err = s.repo.GetKeptTransactionIDs(ctx, userID); err != nil {
+			return nil, err
+		}
+		report.TransactionsKept = len(kept)
+		report.TransactionsReplaced = len(current.Transactions) - len(kept)
+	}
+	for _, t := range current.Transactions {
+		if !st.plan.Replace || kept[t.ID] {
+			key := fingerprint(t)
+			st.existing[key] = append(st.existing[key], t.ID)
+		}
+	}
+
+	s.planCounterparties(st, current.Counterparties, backup.Counterparties)
+	s.planDebts(st, current.Debts, backup.Debts)
+	if err := s.planTransactions(ctx, st, userID, backup.Transactions); err != nil {
+		return nil, err
+	}
+	s.planGoals(st, current.Goals, backup.Goals)
+	s.planBills(st, current.Bills, backup.Bills)
+	s.planRules(st, current.Rules, backup.Rules)
+
+	if backup.RuleMatchMode != "" {
+		if err := s.validate.Var(backup.RuleMatchMode, "oneof=first all"); err != nil {
+			addConflict(report, dto.ConflictInvalid, "", "Rule match mode is invalid and will not be restored")
+		} else {
+			st.plan.RuleMatchMode = backup.RuleMatchMode
+		}
+	}
+
+	budget := backup.Budget
+	if budget != nil {
+		if err := s.validate.Struct(budget); err != nil {
+			addConflict(report, dto.ConflictInvalid, "", "Budget is invalid and will not be restored: "+err.Error())
+			budget = nil
+		} else if !st.plan.Replace && current.Budget != nil {
+			addConflict(report, dto.ConflictBudgetExists, "", "The account already has a maximum spend, it will be kept")
+			budget = nil
+		}
+	}
+	st.plan.Budget = budget
+	report.BudgetRestored = budget != nil
+
+	st.plan.Categories = st.categories.created
+	report.CategoriesCreated = len(st.categories.created)
+	report.CategoriesRemapped = len(st.categories.remapped)
+
+	if report.DryRun {
+		return report, nil
+	}
+
+	replaced, err := s.repo.Restore(ctx, userID, st.plan)
+	if err != nil {
+		return nil, err
+	}
+	report.TransactionsReplaced = replaced
+
+	return report, nil
+}
+
+// planCounterparties memakai kontak dengan nama yang sama jika sudah ada, selain itu membuatnya.
+func (s *backupService) planCounterparties(st *restoreState, current, backup []dto.BackupCounterparty) {
+	byName := map[string]string{}
+	for _, c := range current {
+		byName[normalizeName(c.Name)] = c.ID
+	}
+
+	st.report.Counterparties.InBackup = len(backup)
+	for _, c := range backup {
+		if !s.checkEntity(st, c, c.ID, st.counterparties) {
+			continue
+		}
+		if id, ok := byName[normalizeName(c.Name)]; ok {
+			st.counterparties[c.ID] = id
+			st.report.Counterparties.Existing++
+			continue
+		}
+
+		sourceID := c.ID
+		c.ID = uuid.New().String()
+		st.counterparties[sourceID] = c.ID
+		st.plan.Counterparties = append(st.plan.Counterparties, c)
+		st.report.Counterparties.Created++
+	}
+}
+
+// planDebts memakai hutang yang sudah ada jika kontak, arah, pokok dan tanggal mulainya sama.
+func (s *backupService) planDebts(st *restoreState, current, backup []dto.BackupDebt) {
+	debtKey := func(d dto.BackupDebt) string {
+		return fmt.Sprintf("%s|%s|%.2f|%s", d.CounterpartyID, d.Direction, d.Principal, d.StartDate)
+	}
+	existing := map[string]string{}
+	for _, d := range current {
+		existing[debtKey(d)] = d.ID
+	}
+
+	st.report.Debts.InBackup = len(backup)
+	for _, d := range backup {
+		if !s.checkEntity(st, d, d.ID, st.debts) {
+			continue
+		}
+		counterpartyID, ok := st.counterparties[d.CounterpartyID]
+		if !ok {
+			addConflict(st.report, dto.ConflictUnknownReference, d.ID,
+				fmt.Sprintf("Counterparty %s is not in the backup, the debt will be skipped", d.CounterpartyID))
+			continue
+		}
+		d.CounterpartyID = counterpartyID
+
+		if id, ok := existing[debtKey(d)]; ok {
+			st.debts[d.ID] = id
+			st.report.Debts.Existing++
+			continue
+		}
+
+		sourceID := d.ID
+		d.ID = uuid.New().String()
+		st.debts[sourceID] = d.ID
+		st.plan.Debts = append(st.plan.Debts, d)
+		st.report.Debts.Created++
+	}
+}
+
+func (s *backupService) planTransactions(ctx context.Context, st *restoreState, userID uuid.UUID, backup []dto.BackupTransaction) error {
+	var dates []string
+	for _, t := range backup {
+		dates = append(dates, t.Date)
+	}
+	locked, err := s.repo.GetLockedDates(ctx, userID, validDates(dates))
+	if err != nil {
+		return err
+	}
+
+	seenIDs := map[string]bool{}
+	for _, t := range backup {
+		if err := s.validate.Struct(t); err != nil {
+			addConflict(st.report, dto.ConflictInvalid, t.ID, err.Error())
+			continue
+		}
+		if seenIDs[t.ID] {
+			addConflict(st.report, dto.ConflictDuplicateID, t.ID, "Transaction ID appears more than once in the backup")
+			continue
+		}
+		seenIDs[t.ID] = true
+
+		// Cicilan tanpa hutangnya akan terhitung sebagai pemasukan/pengeluaran biasa
+		if t.DebtID != "" {
+			debtID, ok := st.debts[t.DebtID]
+			if !ok {
+				addConflict(st.report, dto.ConflictUnknownReference, t.ID,
+					fmt.Sprintf("Debt %s is not restored, the repayment will be skipped", t.DebtID))
+				continue
+			}
+			t.DebtID = debtID
+		}
+
+		// Transaksi yang sudah ada dipakai sebagai target link goal dan tagihan
+		if key := fingerprint(t); len(st.existing[key]) > 0 {
+			st.transactions[t.ID] = st.existing[key][0]
+			st.existing[key] = st.existing[key][1:]
+			addConflict(st.report, dto.ConflictDuplicate, t.ID, "An identical transaction already exists and will be skipped")
+			continue
+		}
+
+		if locked[t.Date] {
+			addConflict(st.report, dto.ConflictLockedPeriod, t.ID,
+				fmt.Sprintf("%s is in a reconciled period, the transaction will be skipped", t.Date))
+			continue
+		}
+
+		t.CategoryID = st.resolveCategory(t.ID, t.CategoryID)
+
+		// ID di backup tidak dipakai ulang supaya tidak bentrok dengan transaksi yang sudah ada
+		sourceID := t.ID
+		t.ID = uuid.New().String()
+		st.transactions[sourceID] = t.ID
+		if t.Tags == nil {
+			t.Tags = []string{}
+		}
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = time.Now()
+		}
+		st.plan.Transactions = append(st.plan.Transactions, t)
+	}
+
+	st.report.TransactionsCreated = len(st.plan.Transactions)
+	st.report.TransactionsSkipped = len(backup) - len(st.plan.Transactions)
+	return nil
+}
+
+// planGoals memakai goal dengan nama yang sama jika sudah ada, lalu menyambungkan transaksi
+// yang di-link ke goal di backup.
+func (s *backupService) planGoals(st *restoreState, current, backup []dto.BackupGoal) {
+	byName := map[string]string{}
+	for _, g := range current {
+		byName[normalizeName(g.Name)] = g.ID
+	}
+
+	goals := map[string]string{}
+	st.report.Goals.InBackup = len(backup)
+	for _, g := range backup {
+		if !s.checkEntity(st, g, g.ID, goals) {
+			continue
+		}
+
+		goalID, ok := byName[normalizeName(g.Name)]
+		if ok {
+			st.report.Goals.Existing++
+		} else {
+			goalID = uuid.New().String()
+			created := g
+			created.ID = goalID
+			created.CategoryID = st.resolveCategory(g.ID, g.CategoryID)
+			created.TransactionIDs = nil
+			if created.CreatedAt.IsZero() {
+				created.CreatedAt = time.Now()
+			}
+			st.plan.Goals = append(st.plan.Goals, created)
+			st.report.Goals.Created++
+		}
+		goals[g.ID] = goalID
+
+		for _, transactionID := range g.TransactionIDs {
+			id, ok := st.transactions[transactionID]
+			if !ok {
+				addConflict(st.report, dto.ConflictUnknownReference, g.ID,
+					fmt.Sprintf("Linked transaction %s is not restored, the contribution will be skipped", transactionID))
+				continue
+			}
+			st.plan.GoalContributions = append(st.plan.GoalContributions, dto.RestoreGoalContribution{GoalID: goalID, TransactionID: id})
+		}
+	}
+}
+
+// planBills memakai tagihan dengan nama yang sama jika sudah ada, lalu menyambungkan
+// pembayarannya ke transaksi yang dipulihkan.
+func (s *backupService) planBills(st *restoreState, current, backup []dto.BackupBill) {
+	byName := map[string]string{}
+	for _, b := range current {
+		byName[normalizeName(b.Name)] = b.ID
+	}
+
+	bills := map[string]string{}
+	st.report.Bills.InBackup = len(backup)
+	for _, b := range backup {
+		if !s.checkEntity(st, b, b.ID, bills) {
+			continue
+		}
+
+		billID, ok := byName[normalizeName(b.Name)]
+		if ok {
+			st.report.Bills.Existing++
+		} else {
+			billID = uuid.New().String()
+			created := b
+			created.ID = billID
+			created.CategoryID = st.resolveCategory(b.ID, b.CategoryID)
+			created.Payments = nil
+			st.plan.Bills = append(st.plan.Bills, created)
+			st.report.Bills.Created++
+		}
+		bills[b.ID] = billID
+
+		for _, p := range b.Payments {
+			if err := s.validate.Struct(p); err != nil {
+				addConflict(st.report, dto.ConflictInvalid, b.ID, "Bill payment is invalid and will be skipped: "+err.Error())
+				continue
+			}
+			id, ok := st.transactions[p.TransactionID]
+			if !ok {
+				addConflict(st.report, dto.ConflictUnknownReference, b.ID,
+					fmt.Sprintf("Payment transaction %s is not restored, the payment will be skipped", p.TransactionID))
+				continue
+			}
+			if p.PaidAt.IsZero() {
+				p.PaidAt = time.Now()
+			}
+			st.plan.BillPayments = append(st.plan.BillPayments, dto.RestoreBillPayment{
+				BillID:        billID,
+				TransactionID: id,
+				DueDate:       p.DueDate,
+				PaidAt:        p.PaidAt,
+			})
+		}
+	}
+}
+
+// planRules memakai rule dengan nama yang sama jika sudah ada, selain itu membuatnya.
+func (s *backupService) planRules(st *restoreState, current, backup []dto.BackupRule) {
+	byName := map[string]bool{}
+	for _, r := range current {
+		byName[normalizeName(r.Name)] = true
+	}
+
+	rules := map[string]string{}
+	st.report.Rules.InBackup = len(backup)
+	for _, r := range backup {
+		if !s.checkEntity(st, r, r.ID, rules) {
+			continue
+		}
+		if r.NoteRegex != "" {
+			if _, err := regexp.Compile(r.NoteRegex); err != nil {
+				addConflict(st.report, dto.ConflictInvalid, r.ID, "note_regex is not a valid regular expression")
+				continue
+			}
+		}
+
+		if byName[normalizeName(r.Name)] {
+			rules[r.ID] = ""
+			st.report.Rules.Existing++
+			continue
+		}
+
+		sourceID := r.ID
+		r.ID = uuid.New().String()
+		rules[sourceID] = r.ID
+		r.SetCategoryID = st.resolveCategory(sourceID, r.SetCategoryID)
+		if r.AddTags == nil {
+			r.AddTags = []string{}
+		}
+		st.plan.Rules = append(st.plan.Rules, r)
+		st.report.Rules.Created++
+	}
+}
+
+// checkEntity memvalidasi satu data backup dan memastikan ID-nya belum dipakai data lain yang sejenis.
+func (s *backupService) checkEntity(st *restoreState, entity interface{}, id string, seen map[string]string) bool {
+	if err := s.validate.Struct(entity); err != nil {
+		addConflict(st.report, dto.ConflictInvalid, id, err.Error())
+		return false
+	}
+	if _, ok := seen[id]; ok {
+		addConflict(st.report, dto.ConflictDuplicateID, id, "ID appears more than once in the backup")
+		return false
+	}
+	return true
+}
+
+// resolveCategory memetakan kategori backup milik data sourceID, mencatat konflik jika tidak ditemukan.
+func (st *restoreState) resolveCategory(sourceID, categoryID string) string {
+	if categoryID == "" {
+		return ""
+	}
+	id, ok := st.categories.resolve(categoryID)
+	if !ok {
+		addConflict(st.report, dto.ConflictUnknownCategory, sourceID,
+			fmt.Sprintf("Category %s does not exist, it will be uncategorized", categoryID))
+	}
+	return id
+}
+
+// parseBackup memastikan file adalah backup aplikasi ini dengan versi yang didukung.
+func (s *backupService) parseBackup(data []byte) (*dto.Backup, error) {
+	var backup dto.Backup
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&backup); err != nil {
+		return nil, errx.NewBadRequestError("Backup file is not valid JSON")
+	}
+
+	if backup.Format != dto.BackupFormat {
+		return nil, errx.NewBadRequestError("File is not a cashflow backup")
+	}
+	if backup.Version < 1 || backup.Version > dto.BackupVersion {
+		return nil, errx.NewBadRequestError(fmt.Sprintf("Unsupported backup version %d", backup.Version))
+	}
+
+	return &backup, nil
+}
+
+func addConflict(r *dto.RestoreReport, kind, sourceID, message string) {
+	r.Conflicts = append(r.Conflicts, dto.RestoreConflict{Kind: kind, SourceID: sourceID, Message: message})
+}
+
+// categoryPlanner memetakan kategori di backup ke kategori yang ada: ID yang sama dipakai
+// langsung, selain itu dicari kategori dengan nama yang sama (tanpa membedakan huruf besar).
+// Kategori backup yang tidak ditemukan dibuat ulang, tetapi hanya jika dipakai data yang
+// dipulihkan karena kategori berlaku untuk semua user.
+type categoryPlanner struct {
+	byID    map[string]bool
+	byName  map[string]string
+	backup  map[string]string
+	mapping map[string]string
+	// ID backup yang dipetakan lewat nama
+	remapped map[string]bool
+	created  []dto.BackupCategory
+}
+
+func newCategoryPlanner(current, backup []dto.BackupCategory) *categoryPlanner {
+	p := &categoryPlanner{
+		byID:     map[string]bool{},
+		byName:   map[string]string{},
+		backup:   map[string]string{},
+		mapping:  map[string]string{},
+		remapped: map[string]bool{},
+	}
+	for _, c := range current {
+		p.byID[c.ID] = true
+		p.byName[normalizeName(c.Name)] = c.ID
+	}
+	for _, c := range backup {
+		name := strings.TrimSpace(c.Name)
+		if name != "" && len(name) <= 50 {
+			p.backup[c.ID] = name
+		}
+	}
+	return p
+}
+
+func (p *categoryPlanner) resolve(id string) (string, bool) {
+	if p.byID[id] {
+		return id, true
+	}
+	if mapped, ok := p.mapping[id]; ok {
+		return mapped, true
+	}
+
+	name, ok := p.backup[id]
+	if !ok {
+		return "", false
+	}
+	if existing, ok := p.byName[normalizeName(name)]; ok {
+		p.mapping[id] = existing
+		p.remapped[id] = true
+		return existing, true
+	}
+
+	created := dto.BackupCategory{ID: ulid.Make().String(), Name: name}
+	p.created = append(p.created, created)
+	p.byName[normalizeName(name)] = created.ID
+	p.mapping[id] = created.ID
+	return created.ID, true
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validDates membuang tanggal yang formatnya salah; transaksinya ditolak saat validasi.
+func validDates(dates []string) []string {
+	valid := make([]string, 0, len(dates))
+	for _, d := range dates {
+		if _, err := time.Parse("2006-01-02", d); err == nil {
+			valid = append(valid, d)
+		}
+	}
+	return valid
+}
+
+// fingerprint mengenali transaksi yang sama walaupun ID-nya berbeda.
+func fingerprint(t dto.BackupTransaction) string {
+	return fmt.Sprintf("%s|%s|%.2f|%s", t.Date, t.TransactionType, t.Amount, strings.TrimSpace(t.Note))
+}
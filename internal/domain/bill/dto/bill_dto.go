@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/entity"
+)
+
+type CreateBillRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	CategoryID  *string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Recurrence  string  `json:"recurrence" validate:"required,oneof=none weekly monthly yearly"`
+	NextDueDate string  `json:"next_due_date" validate:"required,datetime=2006-01-02"`
+	DueDay      int     `json:"due_day,omitempty" validate:"omitempty,min=1,max=31"`
+	Note        string  `json:"note,omitempty"`
+}
+
+type UpdateBillRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	CategoryID  *string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Recurrence  string  `json:"recurrence" validate:"required,oneof=none weekly monthly yearly"`
+	NextDueDate string  `json:"next_due_date" validate:"required,datetime=2006-01-02"`
+	DueDay      int     `json:"due_day,omitempty" validate:"omitempty,min=1,max=31"`
+	Note        string  `json:"note,omitempty"`
+	Active      bool    `json:"active"`
+}
+
+type MarkPaidRequest struct {
+	// Tanggal pembayaran, default hari ini
+	PaidDate string `json:"paid_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	// Nominal yang dibayar, default nominal tagihan
+	Amount float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+type UpcomingBillsParams struct {
+	Days int `query:"days" validate:"omitempty,min=1,max=366"`
+}
+
+type UpcomingBill struct {
+	BillID     string  `json:"bill_id"`
+	Name       string  `json:"name"`
+	Amount     float64 `json:"amount"`
+	CategoryID *string `json:"category_id,omitempty" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	DueDate    string  `json:"due_date"`
+	DaysUntil  int     `json:"days_until"`
+	Overdue    bool    `json:"overdue"`
+}
+
+type MarkPaidResponse struct {
+	Bill    entity.Bill        `json:"bill"`
+	Payment entity.BillPayment `json:"payment"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
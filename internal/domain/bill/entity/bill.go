@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RecurrenceNone    = "none"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+	RecurrenceYearly  = "yearly"
+)
+
+type Bill struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Amount      float64   `json:"amount"`
+	CategoryID  *string   `json:"category_id,omitempty" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Recurrence  string    `json:"recurrence"`
+	NextDueDate string    `json:"next_due_date"`
+	DueDay      int       `json:"due_day"`
+	Note        string    `json:"note"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type BillPayment struct {
+	BillID        uuid.UUID `json:"bill_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	DueDate       string    `json:"due_date"`
+	PaidAt        time.Time `json:"paid_at"`
+}
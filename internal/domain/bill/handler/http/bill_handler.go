@@ -0,0 +1,339 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type BillHandler struct {
+	service  service.BillService
+	validate *validator.Validate
+}
+
+func NewBillHandler(service service.BillService) *BillHandler {
+	return &BillHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateBill godoc
+// @Summary Create a bill
+// @Description Create a one-off or recurring bill with a due date. due_day is the day of the month the bill is due and defaults to the day of next_due_date; in shorter months the bill falls on the last day of the month and returns to due_day afterwards
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBillRequest true "Create bill request"
+// @Success 201 {object} response.Response{data=entity.Bill}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills [post]
+func (h *BillHandler) CreateBill(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.CreateBillRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateBill(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Bill created successfully", result))
+}
+
+// GetBills godoc
+// @Summary Get bills
+// @Description Get all bills of the authenticated user
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entity.Bill}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills [get]
+func (h *BillHandler) GetBills(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetBills(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Bills retrieved successfully", result))
+}
+
+// GetUpcomingBills godoc
+// @Summary Get upcoming bills
+// @Description Get unpaid bill due dates within the next N days, including overdue ones
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param days query int false "Number of days ahead" default(30)
+// @Success 200 {object} response.Response{data=[]dto.UpcomingBill}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/upcoming [get]
+func (h *BillHandler) GetUpcomingBills(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.UpcomingBillsParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.GetUpcomingBills(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Upcoming bills retrieved successfully", result))
+}
+
+// RotateCalendarToken godoc
+// @Summary Create calendar feed URL
+// @Description Generate a new secret iCalendar feed URL for the user's bills. Any previous URL stops working.
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.CalendarTokenResponse}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/calendar-token [post]
+func (h *BillHandler) RotateCalendarToken(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.RotateCalendarToken(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Calendar feed URL created successfully", result))
+}
+
+// GetCalendarFeed godoc
+// @Summary Bills iCalendar feed
+// @Description Public iCalendar feed of bill due dates, authenticated by the secret token in the URL
+// @Tags bills
+// @Produce text/calendar
+// @Param token path string true "Calendar token"
+// @Success 200 {string} string
+// @Failure 404 {object} response.Response
+// @Router /calendar/{token}/bills.ics [get]
+func (h *BillHandler) GetCalendarFeed(c *fiber.Ctx) error {
+	token := c.Params("token")
+	if strings.TrimSpace(token) == "" {
+		return errx.NewNotFoundError("Calendar not found")
+	}
+
+	feed, err := h.service.GetCalendarFeed(c.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="bills.ics"`)
+	return c.SendString(feed)
+}
+
+// GetBillByID godoc
+// @Summary Get bill by ID
+// @Description Get a bill by its ID
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param id path string true "Bill ID"
+// @Success 200 {object} response.Response{data=entity.Bill}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/{id} [get]
+func (h *BillHandler) GetBillByID(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseBillID(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.GetBillByID(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Bill retrieved successfully", result))
+}
+
+// UpdateBill godoc
+// @Summary Update a bill
+// @Description Update a bill by its ID. due_day is kept while next_due_date is unchanged, otherwise it defaults to the day of the new next_due_date
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param id path string true "Bill ID"
+// @Param request body dto.UpdateBillRequest true "Update bill request"
+// @Success 200 {object} response.Response{data=entity.Bill}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/{id} [put]
+func (h *BillHandler) UpdateBill(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseBillID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateBillRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.UpdateBill(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Bill updated successfully", result))
+}
+
+// DeleteBill godoc
+// @Summary Delete a bill
+// @Description Delete a bill by its ID. Expense transactions created from it are kept.
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param id path string true "Bill ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/{id} [delete]
+func (h *BillHandler) DeleteBill(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseBillID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.DeleteBill(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Bill deleted successfully", nil))
+}
+
+// MarkPaid godoc
+// @Summary Mark a bill as paid
+// @Description Create the expense transaction for the current due date and move the bill to its next due date
+// @Tags bills
+// @Accept json
+// @Produce json
+// @Param id path string true "Bill ID"
+// @Param request body dto.MarkPaidRequest false "Mark paid request"
+// @Success 200 {object} response.Response{data=dto.MarkPaidResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /bills/{id}/pay [post]
+func (h *BillHandler) MarkPaid(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseBillID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.MarkPaidRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errx.NewBadRequestError("Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.MarkPaid(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Bill marked as paid", result))
+}
+
+func parseBillID(c *fiber.Ctx) (uuid.UUID, error) {
+	idParam := c.Params("id")
+	if strings.TrimSpace(idParam) == "" {
+		return uuid.Nil, errx.NewBadRequestError("Bill ID is required")
+	}
+
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return uuid.Nil, errx.NewBadRequestError("Invalid bill ID format")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+type BillRepository interface {
+	CreateBill(ctx context.Context, bill *entity.Bill) error
+	GetBillByID(ctx context.Context, id uuid.UUID) (*entity.Bill, error)
+	GetBillsByUserID(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*entity.Bill, error)
+	UpdateBill(ctx context.Context, bill *entity.Bill) error
+	DeleteBill(ctx context.Context, id uuid.UUID) error
+	MarkPaid(ctx context.Context, bill *entity.Bill, payment *entity.BillPayment, amount float64, paidDate string) error
+	UpsertCalendarToken(ctx context.Context, userID uuid.UUID, tokenHash string) error
+	GetUserIDByCalendarToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+type billRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewBillRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) BillRepository {
+	return &billRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+const billColumns = `id, user_id, name, amount, category_id, recurrence, next_due_date, due_day, COALESCE(note, ''), active, created_at, updated_at`
+
+func (r *billRepository) CreateBill(ctx context.Context, bill *entity.Bill) error {
+	note, err := r.keyring.EncryptString(ctx, bill.UserID, bill.Note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	query := `
+		INSERT INTO bills (id, user_id, name, amount, category_id, recurrence, next_due_date, due_day, note, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		bill.ID,
+		bill.UserID,
+		bill.Name,
+		bill.Amount,
+		bill.CategoryID,
+		bill.Recurrence,
+		bill.NextDueDate,
+		bill.DueDay,
+		note,
+		bill.Active,
+		bill.CreatedAt,
+		bill.UpdatedAt,
+	)
+	if err != nil {
+		log.Println("[DB ERROR]:", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *billRepository) GetBillByID(ctx context.Context, id uuid.UUID) (*entity.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bills WHERE id = $1`
+
+	bill, err := scanBill(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrBillNotFound
+		}
+		log.Printf("[DB ERROR] GetBillByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	if bill.Note, err = r.keyring.DecryptString(ctx, bill.UserID, bill.Note); err != nil {
+		log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+		return nil, errx.ErrInternalServer
+	}
+
+	return bill, nil
+}
+
+func (r *billRepository) GetBillsByUserID(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]*entity.Bill, error) {
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE user_id = $1 AND (NOT $2 OR active)
+		ORDER BY next_due_date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, activeOnly)
+	if err != nil {
+		log.Printf("[DB ERROR] GetBillsByUserID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var bills []*entity.Bill
+	for rows.Next() {
+		bill, err := scanBill(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if bill.Note, err = r.keyring.DecryptString(ctx, bill.UserID, bill.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		bills = append(bills, bill)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return bills, nil
+}
+
+func (r *billRepository) UpdateBill(ctx context.Context, bill *entity.Bill) error {
+	note, err := r.keyring.EncryptString(ctx, bill.UserID, bill.Note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	query := `
+		UPDATE bills
+		SET name = $1, amount = $2, category_id = $3, recurrence = $4, next_due_date = $5, due_day = $6, note = $7, active = $8, updated_at = $9
+		WHERE id = $10
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		bill.Name,
+		bill.Amount,
+		bill.CategoryID,
+		bill.Recurrence,
+		bill.NextDueDate,
+		bill.DueDay,
+		note,
+		bill.Active,
+		bill.UpdatedAt,
+		bill.ID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] UpdateBill failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *billRepository) DeleteBill(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM bills WHERE id = $1`, id); err != nil {
+		return errx.FromWrite("DeleteBill", err)
+	}
+
+	return nil
+}
+
+// MarkPaid membuat transaksi expense, mencatat pembayaran dan memajukan jatuh
+// tempo tagihan (bill sudah berisi NextDueDate/Active yang baru) dalam satu SQL transaction.
+func (r *billRepository) MarkPaid(ctx context.Context, bill *entity.Bill, payment *entity.BillPayment, amount float64, paidDate string) error {
+	// Tagihan berulang dicatat dengan period sesuai recurrence agar ikut terbaca sebagai transaksi rutin
+	period := bill.Recurrence
+	if period == entity.RecurrenceNone {
+		period = "daily"
+	}
+
+	// Nama tagihan menjadi note transaksi sehingga ikut dienkripsi
+	note, err := r.keyring.EncryptString(ctx, bill.UserID, bill.Name)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO transactions (id, user_id, amount, type, category_id, note, period, date, created_at, updated_at)
+		VALUES ($1, $2, $3, 'expense', $4, $5, $6, $7, $8, $8)
+	`,
+		payment.TransactionID,
+		bill.UserID,
+		amount,
+		bill.CategoryID,
+		note,
+		period,
+		paidDate,
+		payment.PaidAt,
+	)
+	if err != nil {
+		return errx.FromWrite("MarkPaid insert transaction", err)
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO bill_payments (bill_id, transaction_id, due_date, paid_at)
+		VALUES ($1, $2, $3, $4)
+	`, payment.BillID, payment.TransactionID, payment.DueDate, payment.PaidAt)
+	if err != nil {
+		log.Printf("[DB ERROR] MarkPaid insert payment failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		UPDATE bills SET next_due_date = $1, active = $2, updated_at = $3 WHERE id = $4
+	`, bill.NextDueDate, bill.Active, bill.UpdatedAt, bill.ID)
+	if err != nil {
+		log.Printf("[DB ERROR] MarkPaid update bill failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *billRepository) UpsertCalendarToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	query := `
+		INSERT INTO calendar_tokens (user_id, token_hash, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash,
+			created_at = EXCLUDED.created_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, tokenHash); err != nil {
+		log.Printf("[DB ERROR] UpsertCalendarToken failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *billRepository) GetUserIDByCalendarToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id FROM calendar_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, errx.NewNotFoundError("Calendar not found")
+	}
+	if err != nil {
+		return uuid.Nil, errx.ErrDatabaseError
+	}
+
+	return userID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBill(row rowScanner) (*entity.Bill, error) {
+	bill := &entity.Bill{}
+	var categoryID sql.NullString
+	var nextDue time.Time
+	err := row.Scan(
+		&bill.ID,
+		&bill.UserID,
+		&bill.Name,
+		&bill.Amount,
+		&categoryID,
+		&bill.Recurrence,
+		&nextDue,
+		&bill.DueDay,
+		&bill.Note,
+		&bill.Active,
+		&bill.CreatedAt,
+		&bill.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bill.NextDueDate = nextDue.Format("2006-01-02")
+	if categoryID.Valid {
+		bill.CategoryID = &categoryID.String
+	}
+
+	return bill, nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/repository"
+	"github.com/kenziehh/cashflow-be/pkg/dateutil"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+const defaultUpcomingDays = 30
+
+type BillService interface {
+	CreateBill(ctx context.Context, userID uuid.UUID, req dto.CreateBillRequest) (*entity.Bill, error)
+	GetBills(ctx context.Context, userID uuid.UUID) ([]*entity.Bill, error)
+	GetBillByID(ctx context.Context, userID, id uuid.UUID) (*entity.Bill, error)
+	UpdateBill(ctx context.Context, userID, id uuid.UUID, req dto.UpdateBillRequest) (*entity.Bill, error)
+	DeleteBill(ctx context.Context, userID, id uuid.UUID) error
+	MarkPaid(ctx context.Context, userID, id uuid.UUID, req dto.MarkPaidRequest) (*dto.MarkPaidResponse, error)
+	GetUpcomingBills(ctx context.Context, userID uuid.UUID, params dto.UpcomingBillsParams) ([]dto.UpcomingBill, error)
+	RotateCalendarToken(ctx context.Context, userID uuid.UUID) (*dto.CalendarTokenResponse, error)
+	GetCalendarFeed(ctx context.Context, token string) (string, error)
+}
+
+type billService struct {
+	repo    repository.BillRepository
+	baseURL string
+}
+
+func NewBillService(repo repository.BillRepository, baseURL string) BillService {
+	return &billService{
+		repo:    repo,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *billService) CreateBill(ctx context.Context, userID uuid.UUID, req dto.CreateBillRequest) (*entity.Bill, error) {
+	dueDay, err := resolveDueDay(req.NextDueDate, req.DueDay)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	bill := &entity.Bill{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        req.Name,
+		Amount:      req.Amount,
+		CategoryID:  req.CategoryID,
+		Recurrence:  req.Recurrence,
+		NextDueDate: req.NextDueDate,
+		DueDay:      dueDay,
+		Note:        req.Note,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.CreateBill(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	return bill, nil
+}
+
+func (s *billService) GetBills(ctx context.Context, userID uuid.UUID) ([]*entity.Bill, error) {
+	return s.repo.GetBillsByUserID(ctx, userID, false)
+}
+
+func (s *billService) GetBillByID(ctx context.Context, userID, id uuid.UUID) (*entity.Bill, error) {
+	return s.getOwnedBill(ctx, userID, id)
+}
+
+func (s *billService) UpdateBill(ctx context.Context, userID, id uuid.UUID, req dto.UpdateBillRequest) (*entity.Bill, error) {
+	bill, err := s.getOwnedBill(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tanggal asli dipertahankan selama next_due_date tidak dipindah, mis. 28 Feb untuk tanggal 31
+	dueDay := req.DueDay
+	if dueDay == 0 && req.NextDueDate == bill.NextDueDate {
+		dueDay = bill.DueDay
+	}
+	if bill.DueDay, err = resolveDueDay(req.NextDueDate, dueDay); err != nil {
+		return nil, err
+	}
+
+	bill.Name = req.Name
+	bill.Amount = req.Amount
+	bill.CategoryID = req.CategoryID
+	bill.Recurrence = req.Recurrence
+	bill.NextDueDate = req.NextDueDate
+	bill.Note = req.Note
+	bill.Active = req.Active
+	bill.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateBill(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	return bill, nil
+}
+
+func (s *billService) DeleteBill(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwnedBill(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteBill(ctx, id)
+}
+
+func (s *billService) MarkPaid(ctx context.Context, userID, id uuid.UUID, req dto.MarkPaidRequest) (*dto.MarkPaidResponse, error) {
+	bill, err := s.getOwnedBill(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bill.Active {
+		return nil, errx.NewBadRequestError("Bill is no longer active")
+	}
+
+	now := time.Now()
+	paidDate := req.PaidDate
+	if paidDate == "" {
+		paidDate = now.Format(dateutil.Layout)
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = bill.Amount
+	}
+
+	payment := &entity.BillPayment{
+		BillID:        bill.ID,
+		TransactionID: uuid.New(),
+		DueDate:       bill.NextDueDate,
+		PaidAt:        now,
+	}
+
+	// Majukan jatuh tempo ke periode berikutnya, tagihan sekali bayar dinonaktifkan
+	if bill.Recurrence == entity.RecurrenceNone {
+		bill.Active = false
+	} else {
+		due, err := time.Parse(dateutil.Layout, bill.NextDueDate)
+		if err != nil {
+			return nil, errx.ErrInternalServer
+		}
+		bill.NextDueDate = dateutil.AddPeriodOnDay(due, bill.Recurrence, 1, bill.DueDay).Format(dateutil.Layout)
+	}
+	bill.UpdatedAt = now
+
+	if err := s.repo.MarkPaid(ctx, bill, payment, amount, paidDate); err != nil {
+		return nil, err
+	}
+
+	return &dto.MarkPaidResponse{
+		Bill:    *bill,
+		Payment: *payment,
+	}, nil
+}
+
+func (s *billService) GetUpcomingBills(ctx context.Context, userID uuid.UUID, params dto.UpcomingBillsParams) ([]dto.UpcomingBill, error) {
+	days := params.Days
+	if days == 0 {
+		days = defaultUpcomingDays
+	}
+
+	bills, err := s.repo.GetBillsByUserID(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	today := dateutil.Today()
+	until := today.AddDate(0, 0, days)
+
+	upcoming := []dto.UpcomingBill{}
+	for _, bill := range bills {
+		for _, due := range occurrences(bill, until) {
+			daysUntil := int(due.Sub(today).Hours() / 24)
+			upcoming = append(upcoming, dto.UpcomingBill{
+				BillID:     bill.ID.String(),
+				Name:       bill.Name,
+				Amount:     bill.Amount,
+				CategoryID: bill.CategoryID,
+				DueDate:    due.Format(dateutil.Layout),
+				DaysUntil:  daysUntil,
+				Overdue:    daysUntil < 0,
+			})
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DueDate < upcoming[j].DueDate
+	})
+	return upcoming, nil
+}
+
+func (s *billService) RotateCalendarToken(ctx context.Context, userID uuid.UUID) (*dto.CalendarTokenResponse, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, errx.ErrInternalServer
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := s.repo.UpsertCalendarToken(ctx, userID, hashToken(token)); err != nil {
+		return nil, err
+	}
+
+	return &dto.CalendarTokenResponse{
+		Token: token,
+		URL:   fmt.Sprintf("%s/api/v1/calendar/%s/bills.ics", s.baseURL, token),
+	}, nil
+}
+
+func (s *billService) GetCalendarFeed(ctx context.Context, token string) (string, error) {
+	userID, err := s.repo.GetUserIDByCalendarToken(ctx, hashToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	bills, err := s.repo.GetBillsByUserID(ctx, userID, true)
+	if err != nil {
+		return "", err
+	}
+
+	return buildICS(bills, time.Now()), nil
+}
+
+func (s *billService) getOwnedBill(ctx context.Context, userID, id uuid.UUID) (*entity.Bill, error) {
+	bill, err := s.repo.GetBillByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if bill.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this bill")
+	}
+
+	return bill, nil
+}
+
+// occurrences mengembalikan semua jatuh tempo bill sampai tanggal until,
+// dimulai dari NextDueDate (bisa sudah lewat jika belum dibayar).
+func occurrences(bill *entity.Bill, until time.Time) []time.Time {
+	first, err := time.Parse(dateutil.Layout, bill.NextDueDate)
+	if err != nil {
+		return nil
+	}
+
+	if bill.Recurrence == entity.RecurrenceNone {
+		if first.After(until) {
+			return nil
+		}
+		return []time.Time{first}
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		due := dateutil.AddPeriodOnDay(first, bill.Recurrence, n, bill.DueDay)
+		if due.After(until) {
+			break
+		}
+		dates = append(dates, due)
+	}
+	return dates
+}
+
+// resolveDueDay mengembalikan tanggal jatuh tempo tiap bulan. Jika dueDay dikirim, nextDueDate
+// harus jatuh di tanggal itu, atau di hari terakhir bulan jika bulannya lebih pendek.
+func resolveDueDay(nextDueDate string, dueDay int) (int, error) {
+	due, err := time.Parse(dateutil.Layout, nextDueDate)
+	if err != nil {
+		return 0, errx.NewBadRequestError("Invalid next_due_date")
+	}
+	if dueDay == 0 {
+		return due.Day(), nil
+	}
+
+	lastDay := due.AddDate(0, 1, -due.Day()).Day()
+	if due.Day() != min(dueDay, lastDay) {
+		return 0, errx.NewBadRequestError("next_due_date must fall on due_day")
+	}
+	return dueDay, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
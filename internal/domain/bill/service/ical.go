@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kenziehh/cashflow-be/internal/domain/bill/entity"
+	"github.com/kenziehh/cashflow-be/pkg/dateutil"
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+var rruleByRecurrence = map[string]string{
+	entity.RecurrenceWeekly:  "FREQ=WEEKLY",
+	entity.RecurrenceMonthly: "FREQ=MONTHLY",
+	entity.RecurrenceYearly:  "FREQ=YEARLY",
+}
+
+// buildICS menyusun feed iCalendar (RFC 5545) berisi satu event sepanjang hari
+// per tagihan aktif, dengan RRULE untuk tagihan berulang dan alarm H-1.
+func buildICS(bills []*entity.Bill, now time.Time) string {
+	var b strings.Builder
+	writeLine := func(format string, args ...interface{}) {
+		b.WriteString(foldLine(fmt.Sprintf(format, args...)))
+		b.WriteString("\r\n")
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Cash Flow//Bills//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:Bills")
+
+	for _, bill := range bills {
+		due, err := time.Parse(dateutil.Layout, bill.NextDueDate)
+		if err != nil {
+			continue
+		}
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:%s@cashflow", bill.ID)
+		writeLine("DTSTAMP:%s", stamp)
+		writeLine("DTSTART;VALUE=DATE:%s", due.Format("20060102"))
+		writeLine("DTEND;VALUE=DATE:%s", due.AddDate(0, 0, 1).Format("20060102"))
+		writeLine("SUMMARY:%s", icsEscaper.Replace(fmt.Sprintf("%s (%.2f)", bill.Name, bill.Amount)))
+		if bill.Note != "" {
+			writeLine("DESCRIPTION:%s", icsEscaper.Replace(bill.Note))
+		}
+		if rule, ok := rruleByRecurrence[bill.Recurrence]; ok {
+			writeLine("RRULE:%s", rule+monthEndRule(bill.Recurrence, due, bill.DueDay))
+		}
+		writeLine("BEGIN:VALARM")
+		writeLine("ACTION:DISPLAY")
+		writeLine("DESCRIPTION:%s", icsEscaper.Replace(bill.Name+" is due tomorrow"))
+		writeLine("TRIGGER:-P1D")
+		writeLine("END:VALARM")
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+// monthEndRule melengkapi RRULE untuk tanggal jatuh tempo yang tidak ada di setiap bulan.
+// Tanpa ini kalender melewati bulan tersebut, sedangkan aplikasi memakai hari terakhir bulan itu
+// lalu kembali ke tanggal asli (due_day) di bulan berikutnya. Tanggal 31 berarti selalu akhir
+// bulan (BYMONTHDAY=-1); tanggal 29 dan 30 memakai tanggal terbesar yang ada di bulan itu.
+func monthEndRule(recurrence string, due time.Time, dueDay int) string {
+	switch recurrence {
+	case entity.RecurrenceMonthly:
+		if dueDay == 31 {
+			return ";BYMONTHDAY=-1"
+		}
+		if dueDay > 28 {
+			days := make([]string, 0, dueDay-27)
+			for d := 28; d <= dueDay; d++ {
+				days = append(days, fmt.Sprint(d))
+			}
+			return ";BYMONTHDAY=" + strings.Join(days, ",") + ";BYSETPOS=-1"
+		}
+	case entity.RecurrenceYearly:
+		// Hanya 29 Februari yang tidak ada setiap tahun
+		if due.Month() == time.February && dueDay == 29 {
+			return ";BYMONTH=2;BYMONTHDAY=-1"
+		}
+	}
+	return ""
+}
+
+// foldLine memecah baris yang lebih dari 75 octet menjadi beberapa baris yang diawali spasi
+// (RFC 5545 bagian 3.1), tanpa memotong karakter UTF-8 di tengah.
+func foldLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Baris lanjutan sudah memakai satu octet untuk spasi
+		width = limit - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
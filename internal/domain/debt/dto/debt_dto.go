@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/entity"
+)
+
+type CreateCounterpartyRequest struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	Contact string `json:"contact,omitempty" validate:"max=100"`
+}
+
+type CreateDebtRequest struct {
+	CounterpartyID string  `json:"counterparty_id" validate:"required,uuid"`
+	Direction      string  `json:"direction" validate:"required,oneof=payable receivable"`
+	Principal      float64 `json:"principal" validate:"required,gt=0"`
+	Note           string  `json:"note,omitempty"`
+	StartDate      string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	DueDate        *string `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type UpdateDebtRequest struct {
+	Principal float64 `json:"principal" validate:"required,gt=0"`
+	Note      string  `json:"note,omitempty"`
+	DueDate   *string `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type CreateRepaymentRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Date   string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Note   string  `json:"note,omitempty"`
+}
+
+type DebtListParams struct {
+	Direction string `query:"direction" validate:"omitempty,oneof=payable receivable"`
+	Status    string `query:"status" validate:"omitempty,oneof=open overdue settled"`
+}
+
+type DebtDetailResponse struct {
+	entity.Debt
+	Repayments []entity.Repayment `json:"repayments"`
+}
+
+type DebtSummaryResponse struct {
+	TotalPayable      float64 `json:"total_payable"`
+	TotalReceivable   float64 `json:"total_receivable"`
+	OverduePayable    float64 `json:"overdue_payable"`
+	OverdueReceivable float64 `json:"overdue_receivable"`
+}
+
+type DebtReminder struct {
+	Debt    entity.Debt `json:"debt"`
+	DaysDue int         `json:"days_due"`
+	Message string      `json:"message"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DirectionPayable    = "payable"    // hutang: uang yang kita pinjam
+	DirectionReceivable = "receivable" // piutang: uang yang kita pinjamkan
+
+	StatusOpen    = "open"
+	StatusOverdue = "overdue"
+	StatusSettled = "settled"
+)
+
+type Counterparty struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Contact   string    `json:"contact,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Debt struct {
+	ID               uuid.UUID `json:"id"`
+	UserID           uuid.UUID `json:"user_id"`
+	CounterpartyID   uuid.UUID `json:"counterparty_id"`
+	CounterpartyName string    `json:"counterparty_name"`
+	Direction        string    `json:"direction"`
+	Principal        float64   `json:"principal"`
+	Repaid           float64   `json:"repaid"`
+	Outstanding      float64   `json:"outstanding"`
+	Status           string    `json:"status"`
+	Note             string    `json:"note"`
+	StartDate        string    `json:"start_date"`
+	DueDate          *string   `json:"due_date,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type Repayment struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Date          string    `json:"date"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,376 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type DebtHandler struct {
+	service  service.DebtService
+	validate *validator.Validate
+}
+
+func NewDebtHandler(service service.DebtService) *DebtHandler {
+	return &DebtHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateCounterparty godoc
+// @Summary Create a counterparty
+// @Description Create a person the user lends money to or borrows money from
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateCounterpartyRequest true "Create counterparty request"
+// @Success 201 {object} response.Response{data=entity.Counterparty}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/counterparties [post]
+func (h *DebtHandler) CreateCounterparty(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.CreateCounterpartyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateCounterparty(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Counterparty created successfully", result))
+}
+
+// GetCounterparties godoc
+// @Summary Get counterparties
+// @Description Get all counterparties of the authenticated user
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entity.Counterparty}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/counterparties [get]
+func (h *DebtHandler) GetCounterparties(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetCounterparties(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Counterparties retrieved successfully", result))
+}
+
+// CreateDebt godoc
+// @Summary Record a debt or receivable
+// @Description Record money borrowed from (payable) or lent to (receivable) a counterparty
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateDebtRequest true "Create debt request"
+// @Success 201 {object} response.Response{data=entity.Debt}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts [post]
+func (h *DebtHandler) CreateDebt(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.CreateDebtRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateDebt(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Debt created successfully", result))
+}
+
+// GetDebts godoc
+// @Summary Get debts and receivables
+// @Description Get debts and receivables of the authenticated user with outstanding balance and status
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param direction query string false "Direction" Enums(payable, receivable)
+// @Param status query string false "Status" Enums(open, overdue, settled)
+// @Success 200 {object} response.Response{data=[]entity.Debt}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts [get]
+func (h *DebtHandler) GetDebts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.DebtListParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.GetDebts(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Debts retrieved successfully", result))
+}
+
+// GetDebtSummary godoc
+// @Summary Get debt summary
+// @Description Get total outstanding payables and receivables of the authenticated user
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.DebtSummaryResponse}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/summary [get]
+func (h *DebtHandler) GetDebtSummary(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetSummary(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Debt summary retrieved successfully", result))
+}
+
+// GetReminders godoc
+// @Summary Get debt reminders
+// @Description Get open debts and receivables that are overdue or due within a few days
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.DebtReminder}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/reminders [get]
+func (h *DebtHandler) GetReminders(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetReminders(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reminders retrieved successfully", result))
+}
+
+// GetDebtByID godoc
+// @Summary Get debt by ID
+// @Description Get a debt or receivable with its repayments
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param id path string true "Debt ID"
+// @Success 200 {object} response.Response{data=dto.DebtDetailResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/{id} [get]
+func (h *DebtHandler) GetDebtByID(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseDebtID(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.GetDebtByID(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Debt retrieved successfully", result))
+}
+
+// UpdateDebt godoc
+// @Summary Update a debt
+// @Description Update principal, note or due date of a debt or receivable
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param id path string true "Debt ID"
+// @Param request body dto.UpdateDebtRequest true "Update debt request"
+// @Success 200 {object} response.Response{data=entity.Debt}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/{id} [put]
+func (h *DebtHandler) UpdateDebt(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseDebtID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateDebtRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.UpdateDebt(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Debt updated successfully", result))
+}
+
+// DeleteDebt godoc
+// @Summary Delete a debt
+// @Description Delete a debt or receivable. Debts that already have repayments (including repayments in the trash) cannot be deleted
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param id path string true "Debt ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/{id} [delete]
+func (h *DebtHandler) DeleteDebt(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseDebtID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.DeleteDebt(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Debt deleted successfully", nil))
+}
+
+// AddRepayment godoc
+// @Summary Record a repayment
+// @Description Record a partial or full repayment. It is stored as a linked transaction without a category that is excluded from income/expense summaries, budgets and forecasts. Fails when the amount exceeds the outstanding balance.
+// @Tags debts
+// @Accept json
+// @Produce json
+// @Param id path string true "Debt ID"
+// @Param request body dto.CreateRepaymentRequest true "Repayment request"
+// @Success 201 {object} response.Response{data=dto.DebtDetailResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /debts/{id}/repayments [post]
+func (h *DebtHandler) AddRepayment(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseDebtID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreateRepaymentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.AddRepayment(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Repayment recorded successfully", result))
+}
+
+func parseDebtID(c *fiber.Ctx) (uuid.UUID, error) {
+	idParam := c.Params("id")
+	if strings.TrimSpace(idParam) == "" {
+		return uuid.Nil, errx.NewBadRequestError("Debt ID is required")
+	}
+
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return uuid.Nil, errx.NewBadRequestError("Invalid debt ID format")
+	}
+
+	return id, nil
+}
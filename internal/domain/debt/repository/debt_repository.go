@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/oklog/ulid/v2"
+)
+
+type DebtRepository interface {
+	CreateCounterparty(ctx context.Context, cp *entity.Counterparty) error
+	GetCounterpartyByID(ctx context.Context, id uuid.UUID) (*entity.Counterparty, error)
+	GetCounterpartiesByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Counterparty, error)
+	CreateDebt(ctx context.Context, debt *entity.Debt) error
+	GetDebtByID(ctx context.Context, id uuid.UUID) (*entity.Debt, error)
+	GetDebtsByUserID(ctx context.Context, userID uuid.UUID, direction string) ([]*entity.Debt, error)
+	GetDebtsDueBefore(ctx context.Context, date time.Time) ([]*entity.Debt, error)
+	UpdateDebt(ctx context.Context, debt *entity.Debt) error
+	DeleteDebt(ctx context.Context, id uuid.UUID) error
+	CreateRepayment(ctx context.Context, debt *entity.Debt, repayment *entity.Repayment) error
+	GetRepayments(ctx context.Context, debtID uuid.UUID) ([]entity.Repayment, error)
+	CreateAlertOnce(ctx context.Context, userID uuid.UUID, alertType, message string) error
+}
+
+type debtRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewDebtRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) DebtRepository {
+	return &debtRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+const debtSelect = `
+	SELECT d.id, d.user_id, d.counterparty_id, c.name, d.direction, d.principal,
+		COALESCE(SUM(t.amount), 0), COALESCE(d.note, ''), d.start_date, d.due_date,
+		d.created_at, d.updated_at
+	FROM debts d
+	JOIN counterparties c ON c.id = d.counterparty_id
+	LEFT JOIN transactions t ON t.debt_id = d.id AND t.deleted_at IS NULL
+`
+
+func (r *debtRepository) CreateCounterparty(ctx context.Context, cp *entity.Counterparty) error {
+	query := `
+		INSERT INTO counterparties (id, user_id, name, contact, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, cp.ID, cp.UserID, cp.Name, cp.Contact, cp.CreatedAt, cp.UpdatedAt)
+	if err != nil {
+		log.Println("[DB ERROR]:", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *debtRepository) GetCounterpartyByID(ctx context.Context, id uuid.UUID) (*entity.Counterparty, error) {
+	query := `
+		SELECT id, user_id, name, COALESCE(contact, ''), created_at, updated_at
+		FROM counterparties
+		WHERE id = $1
+	`
+
+	cp := &entity.Counterparty{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&cp.ID,
+		&cp.UserID,
+		&cp.Name,
+		&cp.Contact,
+		&cp.CreatedAt,
+		&cp.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errx.ErrCounterpartyNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetCounterpartyByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return cp, nil
+}
+
+func (r *debtRepository) GetCounterpartiesByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Counterparty, error) {
+	query := `
+		SELECT id, user_id, name, COALESCE(contact, ''), created_at, updated_at
+		FROM counterparties
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetCounterpartiesByUserID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var counterparties []*entity.Counterparty
+	for rows.Next() {
+		cp := &entity.Counterparty{}
+		if err := rows.Scan(&cp.ID, &cp.UserID, &cp.Name, &cp.Contact, &cp.CreatedAt, &cp.UpdatedAt); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		counterparties = append(counterparties, cp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return counterparties, nil
+}
+
+func (r *debtRepository) CreateDebt(ctx context.Context, debt *entity.Debt) error {
+	note, err := r.keyring.EncryptString(ctx, debt.UserID, debt.Note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	query := `
+		INSERT INTO debts (id, user_id, counterparty_id, direction, principal, note, start_date, due_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		debt.ID,
+		debt.UserID,
+		debt.CounterpartyID,
+		debt.Direction,
+		debt.Principal,
+		note,
+		debt.StartDate,
+		debt.DueDate,
+		debt.CreatedAt,
+		debt.UpdatedAt,
+	)
+	if err != nil {
+		log.Println("[DB ERROR]:", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *debtRepository) GetDebtByID(ctx context.Context, id uuid.UUID) (*entity.Debt, error) {
+	query := debtSelect + `
+		WHERE d.id = $1
+		GROUP BY d.id, c.name
+	`
+
+	debt, err := scanDebt(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrDebtNotFound
+		}
+		log.Printf("[DB ERROR] GetDebtByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	if debt.Note, err = r.keyring.DecryptString(ctx, debt.UserID, debt.Note); err != nil {
+		log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+		return nil, errx.ErrInternalServer
+	}
+
+	return debt, nil
+}
+
+func (r *debtRepository) GetDebtsByUserID(ctx context.Context, userID uuid.UUID, direction string) ([]*entity.Debt, error) {
+	query := debtSelect + `
+		WHERE d.user_id = $1 AND ($2 = '' OR d.direction::TEXT = $2)
+		GROUP BY d.id, c.name
+		ORDER BY d.due_date ASC NULLS LAST, d.created_at DESC
+	`
+
+	return r.queryDebts(ctx, query, userID, direction)
+}
+
+func (r *debtRepository) GetDebtsDueBefore(ctx context.Context, date time.Time) ([]*entity.Debt, error) {
+	query := debtSelect + `
+		WHERE d.due_date IS NOT NULL AND d.due_date <= $1
+		GROUP BY d.id, c.name
+		HAVING d.principal > COALESCE(SUM(t.amount), 0)
+	`
+
+	return r.queryDebts(ctx, query, date)
+}
+
+func (r *debtRepository) queryDebts(ctx context.Context, query string, args ...interface{}) ([]*entity.Debt, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("[DB ERROR] queryDebts failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var debts []*entity.Debt
+	for rows.Next() {
+		debt, err := scanDebt(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if debt.Note, err = r.keyring.DecryptString(ctx, debt.UserID, debt.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		debts = append(debts, debt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return debts, nil
+}
+
+func (r *debtRepository) UpdateDebt(ctx context.Context, debt *entity.Debt) error {
+	note, err := r.keyring.EncryptString(ctx, debt.UserID, debt.Note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	query := `
+		UPDATE debts
+		SET principal = $1, note = $2, due_date = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	_, err = r.db.ExecContext(ctx, query, debt.Principal, note, debt.DueDate, debt.UpdatedAt, debt.ID)
+	if err != nil {
+		log.Printf("[DB ERROR] UpdateDebt failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// DeleteDebt menghapus hutang yang belum punya cicilan. Cicilan di trash ikut dihitung karena
+// baris transaksinya masih menunjuk ke hutang sampai dihapus permanen. Baris hutang dikunci
+// seperti di CreateRepayment supaya cicilan baru tidak masuk di antara pengecekan dan penghapusan.
+func (r *debtRepository) DeleteDebt(ctx context.Context, id uuid.UUID) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	var hasRepayments bool
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM transactions WHERE debt_id = d.id)
+		FROM debts d WHERE d.id = $1 FOR UPDATE OF d
+	`, id).Scan(&hasRepayments)
+	if err == sql.ErrNoRows {
+		return errx.ErrDebtNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteDebt failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	if hasRepayments {
+		return errx.ErrDebtHasRepayments
+	}
+
+	if _, err := dbTx.ExecContext(ctx, `DELETE FROM debts WHERE id = $1`, id); err != nil {
+		return errx.FromWrite("DeleteDebt", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.FromWrite("DeleteDebt", err)
+	}
+
+	return nil
+}
+
+// CreateRepayment mencatat cicilan sebagai transaksi yang terhubung ke hutang. Baris hutang
+// dikunci selama sisa saldo dihitung dan cicilan disimpan, supaya dua cicilan bersamaan tidak
+// bisa melebihi sisa saldo.
+func (r *debtRepository) CreateRepayment(ctx context.Context, debt *entity.Debt, repayment *entity.Repayment) error {
+	// Bayar hutang = uang keluar, terima cicilan piutang = uang masuk
+	txType := "expense"
+	if debt.Direction == entity.DirectionReceivable {
+		txType = "income"
+	}
+
+	// Note transaksi disimpan terenkripsi seperti transaksi biasa
+	note, err := r.keyring.EncryptString(ctx, debt.UserID, repayment.Note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return errx.ErrInternalServer
+	}
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	var principal float64
+	err = dbTx.QueryRowContext(ctx, `SELECT principal FROM debts WHERE id = $1 FOR UPDATE`, debt.ID).Scan(&principal)
+	if err == sql.ErrNoRows {
+		return errx.ErrDebtNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] CreateRepayment failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	// Dibaca setelah kunci didapat, jadi cicilan yang baru di-commit transaksi lain ikut terhitung
+	var paid float64
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE debt_id = $1 AND deleted_at IS NULL
+	`, debt.ID).Scan(&paid)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateRepayment failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if outstanding := principal - paid; repayment.Amount > outstanding {
+		return errx.NewBadRequestError(fmt.Sprintf("Repayment exceeds outstanding balance of %.2f", outstanding))
+	}
+
+	// Cicilan bukan pengeluaran atau pemasukan baru, hanya memindahkan uang yang sudah tercatat
+	// sebagai hutang, jadi sengaja tanpa kategori supaya tidak masuk budget dan rata-rata belanja
+	// per kategori. Period 'daily' menandai transaksi sekali jalan yang tidak diproyeksikan ulang.
+	query := `
+		INSERT INTO transactions (id, user_id, amount, type, category_id, note, period, date, created_at, updated_at, debt_id)
+		VALUES ($1, $2, $3, $4, NULL, $5, 'daily', $6, $7, $7, $8)
+	`
+
+	_, err = dbTx.ExecContext(ctx, query,
+		repayment.TransactionID,
+		debt.UserID,
+		repayment.Amount,
+		txType,
+		note,
+		repayment.Date,
+		repayment.CreatedAt,
+		debt.ID,
+	)
+	if err != nil {
+		return errx.FromWrite("CreateRepayment", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.FromWrite("CreateRepayment", err)
+	}
+
+	return nil
+}
+
+func (r *debtRepository) GetRepayments(ctx context.Context, debtID uuid.UUID) ([]entity.Repayment, error) {
+	query := `
+		SELECT id, user_id, amount, date, COALESCE(note, ''), created_at
+		FROM transactions
+		WHERE debt_id = $1 AND deleted_at IS NULL
+		ORDER BY date ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, debtID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetRepayments failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	repayments := []entity.Repayment{}
+	for rows.Next() {
+		var rp entity.Repayment
+		var userID uuid.UUID
+		var date time.Time
+		if err := rows.Scan(&rp.TransactionID, &userID, &rp.Amount, &date, &rp.Note, &rp.CreatedAt); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if rp.Note, err = r.keyring.DecryptString(ctx, userID, rp.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		rp.Date = date.Format("2006-01-02")
+		repayments = append(repayments, rp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return repayments, nil
+}
+
+func (r *debtRepository) CreateAlertOnce(ctx context.Context, userID uuid.UUID, alertType, message string) error {
+	// Satu alert yang sama hanya dibuat sekali per hari
+	query := `
+		INSERT INTO alerts (id, user_id, message, type, triggered_at)
+		SELECT $1, $2, $3::VARCHAR, $4::VARCHAR, NOW()
+		WHERE NOT EXISTS (
+			SELECT 1 FROM alerts
+			WHERE user_id = $2 AND type = $4 AND message = $3 AND triggered_at::DATE = CURRENT_DATE
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ulid.Make().String(), userID, message, alertType)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateAlertOnce failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDebt(row rowScanner) (*entity.Debt, error) {
+	debt := &entity.Debt{}
+	var startDate time.Time
+	var dueDate sql.NullTime
+	err := row.Scan(
+		&debt.ID,
+		&debt.UserID,
+		&debt.CounterpartyID,
+		&debt.CounterpartyName,
+		&debt.Direction,
+		&debt.Principal,
+		&debt.Repaid,
+		&debt.Note,
+		&startDate,
+		&dueDate,
+		&debt.CreatedAt,
+		&debt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	debt.StartDate = startDate.Format("2006-01-02")
+	if dueDate.Valid {
+		d := dueDate.Time.Format("2006-01-02")
+		debt.DueDate = &d
+	}
+
+	return debt, nil
+}
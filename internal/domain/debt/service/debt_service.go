@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/debt/repository"
+	"github.com/kenziehh/cashflow-be/pkg/dateutil"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// reminderWindowDays adalah jumlah hari sebelum jatuh tempo saat reminder mulai dikirim
+const reminderWindowDays = 3
+
+type DebtService interface {
+	CreateCounterparty(ctx context.Context, userID uuid.UUID, req dto.CreateCounterpartyRequest) (*entity.Counterparty, error)
+	GetCounterparties(ctx context.Context, userID uuid.UUID) ([]*entity.Counterparty, error)
+	CreateDebt(ctx context.Context, userID uuid.UUID, req dto.CreateDebtRequest) (*entity.Debt, error)
+	GetDebts(ctx context.Context, userID uuid.UUID, params dto.DebtListParams) ([]*entity.Debt, error)
+	GetDebtByID(ctx context.Context, userID, id uuid.UUID) (*dto.DebtDetailResponse, error)
+	UpdateDebt(ctx context.Context, userID, id uuid.UUID, req dto.UpdateDebtRequest) (*entity.Debt, error)
+	DeleteDebt(ctx context.Context, userID, id uuid.UUID) error
+	AddRepayment(ctx context.Context, userID, id uuid.UUID, req dto.CreateRepaymentRequest) (*dto.DebtDetailResponse, error)
+	GetSummary(ctx context.Context, userID uuid.UUID) (dto.DebtSummaryResponse, error)
+	GetReminders(ctx context.Context, userID uuid.UUID) ([]dto.DebtReminder, error)
+	SendDueReminders(ctx context.Context) error
+}
+
+type debtService struct {
+	repo repository.DebtRepository
+}
+
+func NewDebtService(repo repository.DebtRepository) DebtService {
+	return &debtService{
+		repo: repo,
+	}
+}
+
+func (s *debtService) CreateCounterparty(ctx context.Context, userID uuid.UUID, req dto.CreateCounterpartyRequest) (*entity.Counterparty, error) {
+	now := time.Now()
+
+	cp := &entity.Counterparty{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      req.Name,
+		Contact:   req.Contact,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.CreateCounterparty(ctx, cp); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+func (s *debtService) GetCounterparties(ctx context.Context, userID uuid.UUID) ([]*entity.Counterparty, error) {
+	return s.repo.GetCounterpartiesByUserID(ctx, userID)
+}
+
+func (s *debtService) CreateDebt(ctx context.Context, userID uuid.UUID, req dto.CreateDebtRequest) (*entity.Debt, error) {
+	counterpartyID, err := uuid.Parse(req.CounterpartyID)
+	if err != nil {
+		return nil, errx.NewBadRequestError("Invalid counterparty ID format")
+	}
+
+	cp, err := s.repo.GetCounterpartyByID(ctx, counterpartyID)
+	if err != nil {
+		return nil, err
+	}
+	if cp.UserID != userID {
+		return nil, errx.ErrCounterpartyNotFound
+	}
+
+	now := time.Now()
+	debt := &entity.Debt{
+		ID:               uuid.New(),
+		UserID:           userID,
+		CounterpartyID:   cp.ID,
+		CounterpartyName: cp.Name,
+		Direction:        req.Direction,
+		Principal:        req.Principal,
+		Note:             req.Note,
+		StartDate:        req.StartDate,
+		DueDate:          req.DueDate,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.repo.CreateDebt(ctx, debt); err != nil {
+		return nil, err
+	}
+
+	applyStatus(debt, now)
+	return debt, nil
+}
+
+func (s *debtService) GetDebts(ctx context.Context, userID uuid.UUID, params dto.DebtListParams) ([]*entity.Debt, error) {
+	debts, err := s.repo.GetDebtsByUserID(ctx, userID, params.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*entity.Debt, 0, len(debts))
+	for _, debt := range debts {
+		applyStatus(debt, now)
+		if params.Status != "" && debt.Status != params.Status {
+			continue
+		}
+		result = append(result, debt)
+	}
+
+	return result, nil
+}
+
+func (s *debtService) GetDebtByID(ctx context.Context, userID, id uuid.UUID) (*dto.DebtDetailResponse, error) {
+	debt, err := s.getOwnedDebt(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildDetailResponse(ctx, debt)
+}
+
+func (s *debtService) UpdateDebt(ctx context.Context, userID, id uuid.UUID, req dto.UpdateDebtRequest) (*entity.Debt, error) {
+	debt, err := s.getOwnedDebt(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Principal < debt.Repaid {
+		return nil, errx.NewBadRequestError("Principal cannot be lower than the amount already repaid")
+	}
+
+	debt.Principal = req.Principal
+	debt.Note = req.Note
+	debt.DueDate = req.DueDate
+	debt.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateDebt(ctx, debt); err != nil {
+		return nil, err
+	}
+
+	applyStatus(debt, debt.UpdatedAt)
+	return debt, nil
+}
+
+func (s *debtService) DeleteDebt(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwnedDebt(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteDebt(ctx, id)
+}
+
+func (s *debtService) AddRepayment(ctx context.Context, userID, id uuid.UUID, req dto.CreateRepaymentRequest) (*dto.DebtDetailResponse, error) {
+	debt, err := s.getOwnedDebt(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	repayment := &entity.Repayment{
+		TransactionID: uuid.New(),
+		Amount:        req.Amount,
+		Date:          req.Date,
+		Note:          req.Note,
+		CreatedAt:     time.Now(),
+	}
+
+	// Sisa saldo diperiksa di repository sambil mengunci hutang
+	if err := s.repo.CreateRepayment(ctx, debt, repayment); err != nil {
+		return nil, err
+	}
+
+	debt, err = s.getOwnedDebt(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildDetailResponse(ctx, debt)
+}
+
+func (s *debtService) GetSummary(ctx context.Context, userID uuid.UUID) (dto.DebtSummaryResponse, error) {
+	debts, err := s.repo.GetDebtsByUserID(ctx, userID, "")
+	if err != nil {
+		return dto.DebtSummaryResponse{}, err
+	}
+
+	now := time.Now()
+	var summary dto.DebtSummaryResponse
+	for _, debt := range debts {
+		applyStatus(debt, now)
+		overdue := debt.Status == entity.StatusOverdue
+		if debt.Direction == entity.DirectionPayable {
+			summary.TotalPayable += debt.Outstanding
+			if overdue {
+				summary.OverduePayable += debt.Outstanding
+			}
+		} else {
+			summary.TotalReceivable += debt.Outstanding
+			if overdue {
+				summary.OverdueReceivable += debt.Outstanding
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+func (s *debtService) GetReminders(ctx context.Context, userID uuid.UUID) ([]dto.DebtReminder, error) {
+	debts, err := s.repo.GetDebtsByUserID(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reminders := []dto.DebtReminder{}
+	for _, debt := range debts {
+		applyStatus(debt, now)
+		if reminder, ok := buildReminder(debt, now); ok {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	return reminders, nil
+}
+
+// SendDueReminders mencatat alert untuk semua hutang/piutang yang jatuh tempo
+// dalam reminderWindowDays hari atau sudah lewat. Dipanggil berkala dari main.
+func (s *debtService) SendDueReminders(ctx context.Context) error {
+	now := time.Now()
+	debts, err := s.repo.GetDebtsDueBefore(ctx, dateutil.TruncateDate(now).AddDate(0, 0, reminderWindowDays))
+	if err != nil {
+		return err
+	}
+
+	for _, debt := range debts {
+		applyStatus(debt, now)
+		reminder, ok := buildReminder(debt, now)
+		if !ok {
+			continue
+		}
+		if err := s.repo.CreateAlertOnce(ctx, debt.UserID, "debt_due", reminder.Message); err != nil {
+			log.Printf("[DebtReminder] failed to create alert for debt %s: %v", debt.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *debtService) getOwnedDebt(ctx context.Context, userID, id uuid.UUID) (*entity.Debt, error) {
+	debt, err := s.repo.GetDebtByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if debt.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this debt")
+	}
+
+	applyStatus(debt, time.Now())
+	return debt, nil
+}
+
+func (s *debtService) buildDetailResponse(ctx context.Context, debt *entity.Debt) (*dto.DebtDetailResponse, error) {
+	repayments, err := s.repo.GetRepayments(ctx, debt.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DebtDetailResponse{
+		Debt:       *debt,
+		Repayments: repayments,
+	}, nil
+}
+
+func applyStatus(debt *entity.Debt, now time.Time) {
+	debt.Outstanding = math.Round((debt.Principal-debt.Repaid)*100) / 100
+
+	switch {
+	case debt.Outstanding <= 0:
+		debt.Outstanding = 0
+		debt.Status = entity.StatusSettled
+	case debt.DueDate != nil && *debt.DueDate < dateutil.TruncateDate(now).Format("2006-01-02"):
+		debt.Status = entity.StatusOverdue
+	default:
+		debt.Status = entity.StatusOpen
+	}
+}
+
+func buildReminder(debt *entity.Debt, now time.Time) (dto.DebtReminder, bool) {
+	if debt.Status == entity.StatusSettled || debt.DueDate == nil {
+		return dto.DebtReminder{}, false
+	}
+
+	due, err := time.Parse("2006-01-02", *debt.DueDate)
+	if err != nil {
+		return dto.DebtReminder{}, false
+	}
+
+	daysDue := int(due.Sub(dateutil.TruncateDate(now)).Hours() / 24)
+	if daysDue > reminderWindowDays {
+		return dto.DebtReminder{}, false
+	}
+
+	var message string
+	switch {
+	case debt.Direction == entity.DirectionPayable && daysDue < 0:
+		message = fmt.Sprintf("Debt to %s of %.2f is %d day(s) overdue", debt.CounterpartyName, debt.Outstanding, -daysDue)
+	case debt.Direction == entity.DirectionPayable:
+		message = fmt.Sprintf("Debt to %s of %.2f is due in %d day(s)", debt.CounterpartyName, debt.Outstanding, daysDue)
+	case daysDue < 0:
+		message = fmt.Sprintf("Loan to %s of %.2f is %d day(s) overdue", debt.CounterpartyName, debt.Outstanding, -daysDue)
+	default:
+		message = fmt.Sprintf("Loan to %s of %.2f is due in %d day(s)", debt.CounterpartyName, debt.Outstanding, daysDue)
+	}
+
+	return dto.DebtReminder{
+		Debt:    *debt,
+		DaysDue: daysDue,
+		Message: message,
+	}, true
+}
@@ -0,0 +1,34 @@
+package dto
+
+type ForecastParams struct {
+	Days      int      `query:"days" validate:"omitempty,min=1,max=365"`
+	Threshold *float64 `query:"threshold"`
+}
+
+type SetThresholdRequest struct {
+	LowBalanceThreshold float64 `json:"low_balance_threshold"`
+}
+
+type ForecastDay struct {
+	Date           string  `json:"date"`
+	Expected       float64 `json:"expected"`
+	Low            float64 `json:"low"`
+	High           float64 `json:"high"`
+	RecurringNet   float64 `json:"recurring_net"`
+	BelowThreshold bool    `json:"below_threshold"`
+	AtRisk         bool    `json:"at_risk"`
+}
+
+type CategoryAverage struct {
+	CategoryID   string  `json:"category_id"`
+	DailyAverage float64 `json:"daily_average"`
+	DailyStdDev  float64 `json:"daily_std_dev"`
+}
+
+type ForecastResponse struct {
+	CurrentBalance       float64           `json:"current_balance"`
+	Threshold            float64           `json:"threshold"`
+	Days                 []ForecastDay     `json:"days"`
+	DiscretionaryAverage []CategoryAverage `json:"discretionary_average"`
+	FirstShortfallDate   *string           `json:"first_shortfall_date"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ForecastSetting struct {
+	UserID              uuid.UUID `json:"user_id"`
+	LowBalanceThreshold float64   `json:"low_balance_threshold"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// RecurringItem is the latest occurrence of a transaction series whose period
+// is weekly, monthly or yearly. It is projected forward from LastDate.
+type RecurringItem struct {
+	TransactionType string    `json:"transaction_type"`
+	CategoryID      string    `json:"category_id"`
+	Period          string    `json:"period"`
+	Amount          float64   `json:"amount"`
+	Note            string    `json:"note"`
+	LastDate        time.Time `json:"last_date"`
+}
+
+// DailyCategorySpend is the total discretionary expense of one category on one day.
+type DailyCategorySpend struct {
+	CategoryID string
+	Date       time.Time
+	Amount     float64
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type ForecastHandler struct {
+	service  service.ForecastService
+	validate *validator.Validate
+}
+
+func NewForecastHandler(service service.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetForecast godoc
+// @Summary Get cash-flow forecast
+// @Description Project the daily balance for the next N days from the current balance, recurring transactions and historical discretionary spend. Each recurring series (same type, category, period and note) is projected from its latest amount; series with no occurrence in the last two periods are treated as stopped
+// @Tags forecast
+// @Accept json
+// @Produce json
+// @Param days query int false "Number of days to project" default(30)
+// @Param threshold query number false "Low balance threshold, overrides the saved one"
+// @Success 200 {object} response.Response{data=dto.ForecastResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /forecast [get]
+func (h *ForecastHandler) GetForecast(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.ForecastParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.GetForecast(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Forecast retrieved successfully", result))
+}
+
+// SetThreshold godoc
+// @Summary Set low balance threshold
+// @Description Set the balance below which forecast days are flagged
+// @Tags forecast
+// @Accept json
+// @Produce json
+// @Param request body dto.SetThresholdRequest true "Threshold request"
+// @Success 200 {object} response.Response{data=entity.ForecastSetting}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /forecast/threshold [put]
+func (h *ForecastHandler) SetThreshold(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.SetThresholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	result, err := h.service.SetThreshold(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Threshold updated successfully", result))
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+type ForecastRepository interface {
+	GetCurrentBalance(ctx context.Context, userID uuid.UUID) (float64, error)
+	GetRecurringItems(ctx context.Context, userID uuid.UUID) ([]entity.RecurringItem, error)
+	GetDailyCategorySpend(ctx context.Context, userID uuid.UUID, since time.Time) ([]entity.DailyCategorySpend, error)
+	GetThreshold(ctx context.Context, userID uuid.UUID) (float64, error)
+	UpsertThreshold(ctx context.Context, setting *entity.ForecastSetting) error
+}
+
+type forecastRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewForecastRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) ForecastRepository {
+	return &forecastRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+func (r *forecastRepository) GetCurrentBalance(ctx context.Context, userID uuid.UUID) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END), 0)
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL AND date <= CURRENT_DATE
+	`
+
+	var balance float64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&balance); err != nil {
+		log.Printf("[DB ERROR] GetCurrentBalance failed: %v\n", err)
+		return 0, errx.ErrDatabaseError
+	}
+
+	return balance, nil
+}
+
+// GetRecurringItems mengembalikan satu item per seri transaksi rutin, yaitu transaksi terakhir
+// dengan type, kategori, period dan note yang sama. Note membedakan item di kategori yang sama
+// (sewa dan listrik di "Bills"), sedangkan amount tidak ikut jadi kunci supaya seri yang
+// nominalnya berubah (misalnya gaji naik) tetap satu seri. Transaksi tanpa note dibedakan dengan
+// amount-nya. Note terenkripsi, jadi pengelompokan dilakukan setelah didekripsi.
+// Seri yang tidak muncul lagi selama dua periode dianggap sudah berhenti dan tidak diproyeksikan.
+func (r *forecastRepository) GetRecurringItems(ctx context.Context, userID uuid.UUID) ([]entity.RecurringItem, error) {
+	query := `
+		SELECT type, COALESCE(category_id, ''), period, amount, COALESCE(note, ''), date
+		FROM transactions
+		WHERE user_id = $1 AND debt_id IS NULL AND deleted_at IS NULL AND period IN ('weekly', 'monthly', 'yearly')
+			AND date >= CURRENT_DATE - CASE period
+				WHEN 'weekly' THEN INTERVAL '14 days'
+				WHEN 'monthly' THEN INTERVAL '2 months'
+				ELSE INTERVAL '2 years'
+			END
+		ORDER BY date DESC, created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetRecurringItems failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var items []entity.RecurringItem
+	seen := map[string]bool{}
+	for rows.Next() {
+		var item entity.RecurringItem
+		if err := rows.Scan(
+			&item.TransactionType,
+			&item.CategoryID,
+			&item.Period,
+			&item.Amount,
+			&item.Note,
+			&item.LastDate,
+		); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if item.Note, err = r.keyring.DecryptString(ctx, userID, item.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+
+		// Baris diurutkan dari yang terbaru, jadi baris pertama tiap seri adalah kemunculan terakhirnya
+		key := recurringSeriesKey(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return items, nil
+}
+
+func recurringSeriesKey(item entity.RecurringItem) string {
+	series := strings.Join(strings.Fields(strings.ToLower(item.Note)), " ")
+	if series == "" {
+		series = strconv.FormatFloat(item.Amount, 'f', 2, 64)
+	}
+	return strings.Join([]string{item.TransactionType, item.CategoryID, item.Period, series}, "|")
+}
+
+func (r *forecastRepository) GetDailyCategorySpend(ctx context.Context, userID uuid.UUID, since time.Time) ([]entity.DailyCategorySpend, error) {
+	query := `
+		SELECT COALESCE(category_id, ''), date, SUM(amount)
+		FROM transactions
+		WHERE user_id = $1
+			AND type = 'expense'
+			AND debt_id IS NULL
+			AND deleted_at IS NULL
+			AND (period = 'daily' OR period IS NULL)
+			AND date >= $2 AND date <= CURRENT_DATE
+		GROUP BY category_id, date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		log.Printf("[DB ERROR] GetDailyCategorySpend failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var spends []entity.DailyCategorySpend
+	for rows.Next() {
+		var s entity.DailyCategorySpend
+		if err := rows.Scan(&s.CategoryID, &s.Date, &s.Amount); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		spends = append(spends, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return spends, nil
+}
+
+func (r *forecastRepository) GetThreshold(ctx context.Context, userID uuid.UUID) (float64, error) {
+	query := `
+		SELECT low_balance_threshold
+		FROM forecast_settings
+		WHERE user_id = $1
+	`
+
+	var threshold float64
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&threshold)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetThreshold failed: %v\n", err)
+		return 0, errx.ErrDatabaseError
+	}
+
+	return threshold, nil
+}
+
+func (r *forecastRepository) UpsertThreshold(ctx context.Context, setting *entity.ForecastSetting) error {
+	query := `
+		INSERT INTO forecast_settings (user_id, low_balance_threshold, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET low_balance_threshold = EXCLUDED.low_balance_threshold,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, setting.UserID, setting.LowBalanceThreshold, setting.UpdatedAt)
+	if err != nil {
+		log.Printf("[DB ERROR] UpsertThreshold failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
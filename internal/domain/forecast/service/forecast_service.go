@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/forecast/repository"
+	"github.com/kenziehh/cashflow-be/pkg/dateutil"
+)
+
+const (
+	defaultForecastDays = 30
+	historyDays         = 90
+	// bandZScore memberi interval ~80% di sekitar nilai expected
+	bandZScore = 1.28
+)
+
+type ForecastService interface {
+	GetForecast(ctx context.Context, userID uuid.UUID, params dto.ForecastParams) (*dto.ForecastResponse, error)
+	SetThreshold(ctx context.Context, userID uuid.UUID, req dto.SetThresholdRequest) (*entity.ForecastSetting, error)
+}
+
+type forecastService struct {
+	repo repository.ForecastRepository
+}
+
+func NewForecastService(repo repository.ForecastRepository) ForecastService {
+	return &forecastService{
+		repo: repo,
+	}
+}
+
+func (s *forecastService) GetForecast(ctx context.Context, userID uuid.UUID, params dto.ForecastParams) (*dto.ForecastResponse, error) {
+	days := params.Days
+	if days == 0 {
+		days = defaultForecastDays
+	}
+
+	threshold, err := s.repo.GetThreshold(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Threshold != nil {
+		threshold = *params.Threshold
+	}
+
+	balance, err := s.repo.GetCurrentBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring, err := s.repo.GetRecurringItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := dateutil.TruncateDate(time.Now())
+	since := today.AddDate(0, 0, -(historyDays - 1))
+	spends, err := s.repo.GetDailyCategorySpend(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	averages := discretionaryAverages(spends, historyDays)
+	var dailyMean, dailyVariance float64
+	for _, avg := range averages {
+		dailyMean += avg.DailyAverage
+		dailyVariance += avg.DailyStdDev * avg.DailyStdDev
+	}
+
+	recurringByDate := projectRecurring(recurring, today, today.AddDate(0, 0, days))
+
+	resp := &dto.ForecastResponse{
+		CurrentBalance:       balance,
+		Threshold:            threshold,
+		Days:                 make([]dto.ForecastDay, 0, days),
+		DiscretionaryAverage: averages,
+	}
+
+	expected := balance
+	variance := 0.0
+	for i := 1; i <= days; i++ {
+		date := today.AddDate(0, 0, i)
+		key := date.Format("2006-01-02")
+
+		net := recurringByDate[key]
+		expected += net - dailyMean
+		variance += dailyVariance
+		spread := bandZScore * math.Sqrt(variance)
+
+		day := dto.ForecastDay{
+			Date:           key,
+			Expected:       round2(expected),
+			Low:            round2(expected - spread),
+			High:           round2(expected + spread),
+			RecurringNet:   round2(net),
+			BelowThreshold: expected < threshold,
+			AtRisk:         expected-spread < threshold,
+		}
+		if day.BelowThreshold && resp.FirstShortfallDate == nil {
+			d := key
+			resp.FirstShortfallDate = &d
+		}
+		resp.Days = append(resp.Days, day)
+	}
+
+	return resp, nil
+}
+
+func (s *forecastService) SetThreshold(ctx context.Context, userID uuid.UUID, req dto.SetThresholdRequest) (*entity.ForecastSetting, error) {
+	setting := &entity.ForecastSetting{
+		UserID:              userID,
+		LowBalanceThreshold: req.LowBalanceThreshold,
+		UpdatedAt:           time.Now(),
+	}
+
+	if err := s.repo.UpsertThreshold(ctx, setting); err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+// discretionaryAverages menghitung rata-rata dan standar deviasi pengeluaran harian
+// per kategori, termasuk hari tanpa transaksi sebagai nol.
+func discretionaryAverages(spends []entity.DailyCategorySpend, windowDays int) []dto.CategoryAverage {
+	type stat struct{ sum, sumSq float64 }
+	stats := map[string]*stat{}
+	for _, sp := range spends {
+		st, ok := stats[sp.CategoryID]
+		if !ok {
+			st = &stat{}
+			stats[sp.CategoryID] = st
+		}
+		st.sum += sp.Amount
+		st.sumSq += sp.Amount * sp.Amount
+	}
+
+	n := float64(windowDays)
+	averages := make([]dto.CategoryAverage, 0, len(stats))
+	for categoryID, st := range stats {
+		mean := st.sum / n
+		variance := st.sumSq/n - mean*mean
+		if variance < 0 {
+			variance = 0
+		}
+		averages = append(averages, dto.CategoryAverage{
+			CategoryID:   categoryID,
+			DailyAverage: round2(mean),
+			DailyStdDev:  round2(math.Sqrt(variance)),
+		})
+	}
+
+	sort.Slice(averages, func(i, j int) bool {
+		return averages[i].DailyAverage > averages[j].DailyAverage
+	})
+
+	return averages
+}
+
+// projectRecurring mengembalikan net amount (income - expense) per tanggal
+// untuk setiap kemunculan item recurring dalam rentang (from, to].
+func projectRecurring(items []entity.RecurringItem, from, to time.Time) map[string]float64 {
+	result := map[string]float64{}
+	for _, item := range items {
+		sign := -1.0
+		if item.TransactionType == "income" {
+			sign = 1.0
+		}
+
+		anchor := dateutil.TruncateDate(item.LastDate)
+		for n := 1; ; n++ {
+			next := dateutil.AddPeriod(anchor, item.Period, n)
+			if next.After(to) {
+				break
+			}
+			if next.After(from) {
+				result[next.Format("2006-01-02")] += sign * item.Amount
+			}
+		}
+	}
+	return result
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/entity"
+)
+
+type CreateGoalRequest struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	TargetAmount float64 `json:"target_amount" validate:"required,gt=0"`
+	TargetDate   string  `json:"target_date" validate:"required,datetime=2006-01-02"`
+	CategoryID   *string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+}
+
+type UpdateGoalRequest struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	TargetAmount float64 `json:"target_amount" validate:"required,gt=0"`
+	TargetDate   string  `json:"target_date" validate:"required,datetime=2006-01-02"`
+	CategoryID   *string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+}
+
+type AddContributionRequest struct {
+	TransactionID string `json:"transaction_id" validate:"required,uuid"`
+}
+
+type GoalProgress struct {
+	SavedAmount                 float64 `json:"saved_amount"`
+	RemainingAmount             float64 `json:"remaining_amount"`
+	Percent                     float64 `json:"percent"`
+	MonthsLeft                  int     `json:"months_left"`
+	RequiredMonthlyContribution float64 `json:"required_monthly_contribution"`
+	AverageMonthlyContribution  float64 `json:"average_monthly_contribution"`
+	ProjectedCompletionDate     *string `json:"projected_completion_date"`
+	OnTrack                     bool    `json:"on_track"`
+	Completed                   bool    `json:"completed"`
+}
+
+type GoalResponse struct {
+	entity.Goal
+	Progress GoalProgress `json:"progress"`
+}
+
+type GoalDetailResponse struct {
+	GoalResponse
+	Contributions []entity.Contribution `json:"contributions"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Goal struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Name         string    `json:"name"`
+	TargetAmount float64   `json:"target_amount"`
+	TargetDate   string    `json:"target_date"`
+	CategoryID   *string   `json:"category_id,omitempty" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Contribution adalah transaksi yang dihitung sebagai setoran ke goal, baik
+// karena di-link manual maupun karena kategorinya sama dengan kategori goal.
+// Amount negatif untuk transaksi income, yaitu penarikan dari tabungan.
+type Contribution struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Date          string    `json:"date"`
+	Note          string    `json:"note"`
+	Linked        bool      `json:"linked"`
+}
@@ -0,0 +1,289 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type GoalHandler struct {
+	service  service.GoalService
+	validate *validator.Validate
+}
+
+func NewGoalHandler(service service.GoalService) *GoalHandler {
+	return &GoalHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateGoal godoc
+// @Summary Create a savings goal
+// @Description Create a savings goal with a target amount, target date and optional linked category
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateGoalRequest true "Create goal request"
+// @Success 201 {object} response.Response{data=dto.GoalResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals [post]
+func (h *GoalHandler) CreateGoal(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.CreateGoalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateGoal(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Goal created successfully", result))
+}
+
+// GetGoals godoc
+// @Summary Get savings goals
+// @Description Get all savings goals of the authenticated user with their progress
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.GoalResponse}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals [get]
+func (h *GoalHandler) GetGoals(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetGoals(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Goals retrieved successfully", result))
+}
+
+// GetGoalByID godoc
+// @Summary Get savings goal by ID
+// @Description Get a savings goal with its progress and contributions. Expenses count as money set aside for the goal; income counts as a withdrawal and has a negative amount.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Success 200 {object} response.Response{data=dto.GoalDetailResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals/{id} [get]
+func (h *GoalHandler) GetGoalByID(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseGoalID(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.GetGoalByID(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Goal retrieved successfully", result))
+}
+
+// UpdateGoal godoc
+// @Summary Update a savings goal
+// @Description Update a savings goal by its ID
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Param request body dto.UpdateGoalRequest true "Update goal request"
+// @Success 200 {object} response.Response{data=dto.GoalResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals/{id} [put]
+func (h *GoalHandler) UpdateGoal(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseGoalID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.UpdateGoalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.UpdateGoal(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Goal updated successfully", result))
+}
+
+// DeleteGoal godoc
+// @Summary Delete a savings goal
+// @Description Delete a savings goal by its ID. Linked transactions are kept.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals/{id} [delete]
+func (h *GoalHandler) DeleteGoal(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseGoalID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.DeleteGoal(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Goal deleted successfully", nil))
+}
+
+// AddContribution godoc
+// @Summary Link a transaction to a goal
+// @Description Record an existing transaction as a contribution to the goal. An expense adds to the saved amount and an income is subtracted from it.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Param request body dto.AddContributionRequest true "Contribution request"
+// @Success 200 {object} response.Response{data=dto.GoalDetailResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals/{id}/contributions [post]
+func (h *GoalHandler) AddContribution(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseGoalID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.AddContributionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.AddContribution(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Contribution added successfully", result))
+}
+
+// RemoveContribution godoc
+// @Summary Unlink a transaction from a goal
+// @Description Remove a manually linked contribution from the goal
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Param transactionId path string true "Transaction ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /goals/{id}/contributions/{transactionId} [delete]
+func (h *GoalHandler) RemoveContribution(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseGoalID(c)
+	if err != nil {
+		return err
+	}
+
+	transactionID, err := uuid.Parse(c.Params("transactionId"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	if err := h.service.RemoveContribution(c.Context(), userID, id, transactionID); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Contribution removed successfully", nil))
+}
+
+func parseGoalID(c *fiber.Ctx) (uuid.UUID, error) {
+	idParam := c.Params("id")
+	if strings.TrimSpace(idParam) == "" {
+		return uuid.Nil, errx.NewBadRequestError("Goal ID is required")
+	}
+
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return uuid.Nil, errx.NewBadRequestError("Invalid goal ID format")
+	}
+
+	return id, nil
+}
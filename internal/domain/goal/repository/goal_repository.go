@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+type GoalRepository interface {
+	CreateGoal(ctx context.Context, goal *entity.Goal) error
+	GetGoalByID(ctx context.Context, id uuid.UUID) (*entity.Goal, error)
+	GetGoalsByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Goal, error)
+	UpdateGoal(ctx context.Context, goal *entity.Goal) error
+	DeleteGoal(ctx context.Context, id uuid.UUID) error
+	TransactionBelongsToUser(ctx context.Context, transactionID, userID uuid.UUID) (bool, error)
+	AddContribution(ctx context.Context, goalID, transactionID uuid.UUID) error
+	RemoveContribution(ctx context.Context, goalID, transactionID uuid.UUID) error
+	GetContributions(ctx context.Context, goal *entity.Goal) ([]entity.Contribution, error)
+}
+
+type goalRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewGoalRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) GoalRepository {
+	return &goalRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+func (r *goalRepository) CreateGoal(ctx context.Context, goal *entity.Goal) error {
+	query := `
+		INSERT INTO goals (id, user_id, name, target_amount, target_date, category_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		goal.ID,
+		goal.UserID,
+		goal.Name,
+		goal.TargetAmount,
+		goal.TargetDate,
+		goal.CategoryID,
+		goal.CreatedAt,
+		goal.UpdatedAt,
+	)
+	if err != nil {
+		log.Println("[DB ERROR]:", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *goalRepository) GetGoalByID(ctx context.Context, id uuid.UUID) (*entity.Goal, error) {
+	query := `
+		SELECT id, user_id, name, target_amount, target_date, category_id, created_at, updated_at
+		FROM goals
+		WHERE id = $1
+	`
+
+	goal, err := scanGoal(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrGoalNotFound
+		}
+		log.Printf("[DB ERROR] GetGoalByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return goal, nil
+}
+
+func (r *goalRepository) GetGoalsByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Goal, error) {
+	query := `
+		SELECT id, user_id, name, target_amount, target_date, category_id, created_at, updated_at
+		FROM goals
+		WHERE user_id = $1
+		ORDER BY target_date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetGoalsByUserID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var goals []*entity.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return goals, nil
+}
+
+func (r *goalRepository) UpdateGoal(ctx context.Context, goal *entity.Goal) error {
+	query := `
+		UPDATE goals
+		SET name = $1, target_amount = $2, target_date = $3, category_id = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		goal.Name,
+		goal.TargetAmount,
+		goal.TargetDate,
+		goal.CategoryID,
+		goal.UpdatedAt,
+		goal.ID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] UpdateGoal failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *goalRepository) DeleteGoal(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM goals WHERE id = $1`, id)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *goalRepository) TransactionBelongsToUser(ctx context.Context, transactionID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM transactions WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`,
+		transactionID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return false, errx.ErrDatabaseError
+	}
+
+	return exists, nil
+}
+
+func (r *goalRepository) AddContribution(ctx context.Context, goalID, transactionID uuid.UUID) error {
+	query := `
+		INSERT INTO goal_contributions (goal_id, transaction_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, goalID, transactionID); err != nil {
+		log.Printf("[DB ERROR] AddContribution failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *goalRepository) RemoveContribution(ctx context.Context, goalID, transactionID uuid.UUID) error {
+	query := `
+		DELETE FROM goal_contributions
+		WHERE goal_id = $1 AND transaction_id = $2
+	`
+
+	res, err := r.db.ExecContext(ctx, query, goalID, transactionID)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.NewNotFoundError("Contribution not found")
+	}
+
+	return nil
+}
+
+func (r *goalRepository) GetContributions(ctx context.Context, goal *entity.Goal) ([]entity.Contribution, error) {
+	// Transaksi yang di-link manual, ditambah transaksi dengan kategori goal sejak goal dibuat.
+	// Menyisihkan uang ke tabungan dicatat sebagai expense sehingga menambah progres, sedangkan
+	// income (uang ditarik kembali dari tabungan) mengurangi progres.
+	query := `
+		SELECT t.id, CASE WHEN t.type = 'income' THEN -t.amount ELSE t.amount END, t.date, COALESCE(t.note, ''), gc.goal_id IS NOT NULL
+		FROM transactions t
+		LEFT JOIN goal_contributions gc ON gc.transaction_id = t.id AND gc.goal_id = $1
+		WHERE t.user_id = $2
+			AND t.deleted_at IS NULL
+			AND (
+				gc.goal_id IS NOT NULL
+				OR ($3::CHAR(26) IS NOT NULL AND t.category_id = $3 AND t.date >= $4::DATE)
+			)
+		ORDER BY t.date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, goal.ID, goal.UserID, goal.CategoryID, goal.CreatedAt)
+	if err != nil {
+		log.Printf("[DB ERROR] GetContributions failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var contributions []entity.Contribution
+	for rows.Next() {
+		var c entity.Contribution
+		var date time.Time
+		if err := rows.Scan(&c.TransactionID, &c.Amount, &date, &c.Note, &c.Linked); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if c.Note, err = r.keyring.DecryptString(ctx, goal.UserID, c.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		c.Date = date.Format("2006-01-02")
+		contributions = append(contributions, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return contributions, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGoal(row rowScanner) (*entity.Goal, error) {
+	goal := &entity.Goal{}
+	var targetDate time.Time
+	var categoryID sql.NullString
+	err := row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Name,
+		&goal.TargetAmount,
+		&targetDate,
+		&categoryID,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.TargetDate = targetDate.Format("2006-01-02")
+	if categoryID.Valid {
+		goal.CategoryID = &categoryID.String
+	}
+
+	return goal, nil
+}
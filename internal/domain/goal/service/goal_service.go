@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/goal/repository"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+const daysPerMonth = 30.4375
+
+type GoalService interface {
+	CreateGoal(ctx context.Context, userID uuid.UUID, req dto.CreateGoalRequest) (*dto.GoalResponse, error)
+	GetGoals(ctx context.Context, userID uuid.UUID) ([]dto.GoalResponse, error)
+	GetGoalByID(ctx context.Context, userID, id uuid.UUID) (*dto.GoalDetailResponse, error)
+	UpdateGoal(ctx context.Context, userID, id uuid.UUID, req dto.UpdateGoalRequest) (*dto.GoalResponse, error)
+	DeleteGoal(ctx context.Context, userID, id uuid.UUID) error
+	AddContribution(ctx context.Context, userID, id uuid.UUID, req dto.AddContributionRequest) (*dto.GoalDetailResponse, error)
+	RemoveContribution(ctx context.Context, userID, id, transactionID uuid.UUID) error
+}
+
+type goalService struct {
+	repo repository.GoalRepository
+}
+
+func NewGoalService(repo repository.GoalRepository) GoalService {
+	return &goalService{
+		repo: repo,
+	}
+}
+
+func (s *goalService) CreateGoal(ctx context.Context, userID uuid.UUID, req dto.CreateGoalRequest) (*dto.GoalResponse, error) {
+	now := time.Now()
+
+	goal := &entity.Goal{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		TargetDate:   req.TargetDate,
+		CategoryID:   req.CategoryID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.repo.CreateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, goal)
+}
+
+func (s *goalService) GetGoals(ctx context.Context, userID uuid.UUID) ([]dto.GoalResponse, error) {
+	goals, err := s.repo.GetGoalsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.GoalResponse, 0, len(goals))
+	for _, goal := range goals {
+		resp, err := s.buildResponse(ctx, goal)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *resp)
+	}
+
+	return result, nil
+}
+
+func (s *goalService) GetGoalByID(ctx context.Context, userID, id uuid.UUID) (*dto.GoalDetailResponse, error) {
+	goal, err := s.getOwnedGoal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildDetailResponse(ctx, goal)
+}
+
+func (s *goalService) UpdateGoal(ctx context.Context, userID, id uuid.UUID, req dto.UpdateGoalRequest) (*dto.GoalResponse, error) {
+	goal, err := s.getOwnedGoal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Name = req.Name
+	goal.TargetAmount = req.TargetAmount
+	goal.TargetDate = req.TargetDate
+	goal.CategoryID = req.CategoryID
+	goal.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, goal)
+}
+
+func (s *goalService) DeleteGoal(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwnedGoal(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteGoal(ctx, id)
+}
+
+func (s *goalService) AddContribution(ctx context.Context, userID, id uuid.UUID, req dto.AddContributionRequest) (*dto.GoalDetailResponse, error) {
+	goal, err := s.getOwnedGoal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionID, err := uuid.Parse(req.TransactionID)
+	if err != nil {
+		return nil, errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	owned, err := s.repo.TransactionBelongsToUser(ctx, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, errx.ErrTransactionNotFound
+	}
+
+	if err := s.repo.AddContribution(ctx, goal.ID, transactionID); err != nil {
+		return nil, err
+	}
+
+	return s.buildDetailResponse(ctx, goal)
+}
+
+func (s *goalService) RemoveContribution(ctx context.Context, userID, id, transactionID uuid.UUID) error {
+	goal, err := s.getOwnedGoal(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.RemoveContribution(ctx, goal.ID, transactionID)
+}
+
+func (s *goalService) getOwnedGoal(ctx context.Context, userID, id uuid.UUID) (*entity.Goal, error) {
+	goal, err := s.repo.GetGoalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if goal.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this goal")
+	}
+
+	return goal, nil
+}
+
+func (s *goalService) buildResponse(ctx context.Context, goal *entity.Goal) (*dto.GoalResponse, error) {
+	contributions, err := s.repo.GetContributions(ctx, goal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GoalResponse{
+		Goal:     *goal,
+		Progress: calculateProgress(goal, contributions, time.Now()),
+	}, nil
+}
+
+func (s *goalService) buildDetailResponse(ctx context.Context, goal *entity.Goal) (*dto.GoalDetailResponse, error) {
+	contributions, err := s.repo.GetContributions(ctx, goal)
+	if err != nil {
+		return nil, err
+	}
+	if contributions == nil {
+		contributions = []entity.Contribution{}
+	}
+
+	return &dto.GoalDetailResponse{
+		GoalResponse: dto.GoalResponse{
+			Goal:     *goal,
+			Progress: calculateProgress(goal, contributions, time.Now()),
+		},
+		Contributions: contributions,
+	}, nil
+}
+
+// calculateProgress menghitung progres goal, setoran bulanan yang dibutuhkan
+// agar tercapai tepat waktu, dan proyeksi tanggal selesai berdasarkan
+// rata-rata setoran sejak kontribusi pertama.
+func calculateProgress(goal *entity.Goal, contributions []entity.Contribution, now time.Time) dto.GoalProgress {
+	var saved float64
+	var firstDate time.Time
+	for _, c := range contributions {
+		saved += c.Amount
+		if d, err := time.Parse("2006-01-02", c.Date); err == nil && (firstDate.IsZero() || d.Before(firstDate)) {
+			firstDate = d
+		}
+	}
+
+	remaining := math.Max(goal.TargetAmount-saved, 0)
+	progress := dto.GoalProgress{
+		SavedAmount:     round2(saved),
+		RemainingAmount: round2(remaining),
+		Percent:         round2(math.Max(math.Min(saved/goal.TargetAmount*100, 100), 0)),
+		Completed:       remaining == 0,
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	targetDate, _ := time.Parse("2006-01-02", goal.TargetDate)
+
+	daysLeft := targetDate.Sub(today).Hours() / 24
+	if daysLeft > 0 {
+		progress.MonthsLeft = int(math.Ceil(daysLeft / daysPerMonth))
+		progress.RequiredMonthlyContribution = round2(remaining / float64(progress.MonthsLeft))
+	} else {
+		progress.RequiredMonthlyContribution = round2(remaining)
+	}
+
+	if progress.Completed {
+		progress.OnTrack = true
+		return progress
+	}
+
+	if !firstDate.IsZero() && saved > 0 {
+		elapsedMonths := math.Max(today.Sub(firstDate).Hours()/24/daysPerMonth, 1)
+		avg := saved / elapsedMonths
+		progress.AverageMonthlyContribution = round2(avg)
+
+		projected := today.AddDate(0, 0, int(math.Ceil(remaining/avg*daysPerMonth))).Format("2006-01-02")
+		progress.ProjectedCompletionDate = &projected
+		progress.OnTrack = projected <= goal.TargetDate
+	}
+
+	return progress
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+)
+
+type RuleRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Priority int    `json:"priority"`
+	Active   *bool  `json:"active,omitempty"`
+
+	NoteContains    string   `json:"note_contains,omitempty" validate:"max=255"`
+	NoteRegex       string   `json:"note_regex,omitempty" validate:"max=255"`
+	MinAmount       *float64 `json:"min_amount,omitempty" validate:"omitempty,gte=0"`
+	MaxAmount       *float64 `json:"max_amount,omitempty" validate:"omitempty,gte=0"`
+	TransactionType string   `json:"transaction_type,omitempty" validate:"omitempty,oneof=income expense"`
+
+	SetCategoryID *string  `json:"set_category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	AddTags       []string `json:"add_tags,omitempty" validate:"omitempty,dive,max=50"`
+	NoteRewrite   *string  `json:"note_rewrite,omitempty" validate:"omitempty,max=255"`
+}
+
+type UpdateSettingsRequest struct {
+	MatchMode string `json:"match_mode" validate:"required,oneof=first all"`
+}
+
+type ApplyRulesRequest struct {
+	// Jika true hanya mengembalikan preview perubahan tanpa menyimpan
+	DryRun    bool   `json:"dry_run"`
+	StartDate string `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type RuleChange struct {
+	TransactionID uuid.UUID     `json:"transaction_id"`
+	MatchedRules  []uuid.UUID   `json:"matched_rules"`
+	Before        entity.Target `json:"before"`
+	After         entity.Target `json:"after"`
+}
+
+type ApplyRulesResponse struct {
+	DryRun  bool         `json:"dry_run"`
+	Scanned int          `json:"scanned"`
+	Changed int          `json:"changed"`
+	Changes []RuleChange `json:"changes"`
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MatchModeFirst = "first" // berhenti di rule pertama yang cocok
+	MatchModeAll   = "all"   // jalankan semua rule yang cocok sesuai urutan priority
+)
+
+type Rule struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Name     string    `json:"name"`
+	Priority int       `json:"priority"`
+	Active   bool      `json:"active"`
+
+	// Kondisi, semua yang diisi harus terpenuhi
+	NoteContains    string   `json:"note_contains,omitempty"`
+	NoteRegex       string   `json:"note_regex,omitempty"`
+	MinAmount       *float64 `json:"min_amount,omitempty"`
+	MaxAmount       *float64 `json:"max_amount,omitempty"`
+	TransactionType string   `json:"transaction_type,omitempty"`
+
+	// Aksi
+	SetCategoryID *string  `json:"set_category_id,omitempty" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	AddTags       []string `json:"add_tags"`
+	NoteRewrite   *string  `json:"note_rewrite,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Settings struct {
+	UserID    uuid.UUID `json:"user_id"`
+	MatchMode string    `json:"match_mode"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Target adalah bagian transaksi yang dibaca dan diubah oleh rule.
+type Target struct {
+	TransactionType string   `json:"transaction_type"`
+	Amount          float64  `json:"amount"`
+	Note            string   `json:"note"`
+	CategoryID      string   `json:"category_id"`
+	Tags            []string `json:"tags"`
+}
+
+type TransactionTarget struct {
+	TransactionID uuid.UUID
+	// Versi transaksi saat dibaca, update ditolak jika transaksi sudah diubah di antaranya
+	Version int
+	Target  Target
+}
@@ -0,0 +1,279 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type RuleHandler struct {
+	service  service.RuleService
+	validate *validator.Validate
+}
+
+func NewRuleHandler(service service.RuleService) *RuleHandler {
+	return &RuleHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// CreateRule godoc
+// @Summary Create an auto-categorization rule
+// @Description Create a rule that sets category, tags or note of matching transactions
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body dto.RuleRequest true "Rule request"
+// @Success 201 {object} response.Response{data=entity.Rule}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules [post]
+func (h *RuleHandler) CreateRule(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.RuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateRule(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Rule created successfully", result))
+}
+
+// GetRules godoc
+// @Summary Get auto-categorization rules
+// @Description Get all rules of the authenticated user ordered by priority
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entity.Rule}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules [get]
+func (h *RuleHandler) GetRules(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetRules(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Rules retrieved successfully", result))
+}
+
+// UpdateRule godoc
+// @Summary Update an auto-categorization rule
+// @Description Update a rule by its ID
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body dto.RuleRequest true "Rule request"
+// @Success 200 {object} response.Response{data=entity.Rule}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules/{id} [put]
+func (h *RuleHandler) UpdateRule(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseRuleID(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.RuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.UpdateRule(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Rule updated successfully", result))
+}
+
+// DeleteRule godoc
+// @Summary Delete an auto-categorization rule
+// @Description Delete a rule by its ID
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules/{id} [delete]
+func (h *RuleHandler) DeleteRule(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := parseRuleID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.DeleteRule(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Rule deleted successfully", nil))
+}
+
+// GetSettings godoc
+// @Summary Get rule settings
+// @Description Get whether rules run with first-match or all-match semantics
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entity.Settings}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules/settings [get]
+func (h *RuleHandler) GetSettings(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetSettings(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Rule settings retrieved successfully", result))
+}
+
+// UpdateSettings godoc
+// @Summary Update rule settings
+// @Description Set first-match or all-match semantics for the user's rules
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateSettingsRequest true "Settings request"
+// @Success 200 {object} response.Response{data=entity.Settings}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules/settings [put]
+func (h *RuleHandler) UpdateSettings(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.UpdateSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.UpdateSettings(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Rule settings updated successfully", result))
+}
+
+// ApplyRules godoc
+// @Summary Apply rules to existing transactions
+// @Description Run the user's rules against existing transactions. Debt repayments are left out. With dry_run the changes are only previewed. Every changed transaction gets a revision like a normal edit; if one of them was edited while the rules ran nothing is saved and 412 is returned.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param request body dto.ApplyRulesRequest true "Apply rules request"
+// @Success 200 {object} response.Response{data=dto.ApplyRulesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /rules/apply [post]
+func (h *RuleHandler) ApplyRules(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.ApplyRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.ApplyToHistory(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	message := "Rules applied successfully"
+	if req.DryRun {
+		message = "Rule preview generated successfully"
+	}
+
+	return c.JSON(response.SuccessResponse(message, result))
+}
+
+func parseRuleID(c *fiber.Ctx) (uuid.UUID, error) {
+	idParam := c.Params("id")
+	if strings.TrimSpace(idParam) == "" {
+		return uuid.Nil, errx.NewBadRequestError("Rule ID is required")
+	}
+
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return uuid.Nil, errx.NewBadRequestError("Invalid rule ID format")
+	}
+
+	return id, nil
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule *entity.Rule) error
+	GetRuleByID(ctx context.Context, id uuid.UUID) (*entity.Rule, error)
+	GetRulesByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Rule, error)
+	UpdateRule(ctx context.Context, rule *entity.Rule) error
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+	GetMatchMode(ctx context.Context, userID uuid.UUID) (string, error)
+	UpsertSettings(ctx context.Context, settings *entity.Settings) error
+	GetTransactionTargets(ctx context.Context, userID uuid.UUID, startDate, endDate string) ([]entity.TransactionTarget, error)
+	UpdateTransactionTargets(ctx context.Context, userID uuid.UUID, targets []entity.TransactionTarget) error
+}
+
+type ruleRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewRuleRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) RuleRepository {
+	return &ruleRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+const ruleColumns = `id, user_id, name, priority, active, COALESCE(note_contains, ''), COALESCE(note_regex, ''),
+	min_amount, max_amount, COALESCE(transaction_type::TEXT, ''), set_category_id, add_tags, note_rewrite,
+	created_at, updated_at`
+
+func (r *ruleRepository) CreateRule(ctx context.Context, rule *entity.Rule) error {
+	query := `
+		INSERT INTO rules (id, user_id, name, priority, active, note_contains, note_regex, min_amount, max_amount,
+			transaction_type, set_category_id, add_tags, note_rewrite, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.UserID,
+		rule.Name,
+		rule.Priority,
+		rule.Active,
+		nullIfEmpty(rule.NoteContains),
+		nullIfEmpty(rule.NoteRegex),
+		rule.MinAmount,
+		rule.MaxAmount,
+		nullIfEmpty(rule.TransactionType),
+		rule.SetCategoryID,
+		pq.Array(rule.AddTags),
+		rule.NoteRewrite,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateRule failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *ruleRepository) GetRuleByID(ctx context.Context, id uuid.UUID) (*entity.Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM rules WHERE id = $1`
+
+	rule, err := scanRule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrRuleNotFound
+		}
+		log.Printf("[DB ERROR] GetRuleByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return rule, nil
+}
+
+func (r *ruleRepository) GetRulesByUserID(ctx context.Context, userID uuid.UUID) ([]*entity.Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM rules WHERE user_id = $1 ORDER BY priority ASC, created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetRulesByUserID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	rules := []*entity.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return rules, nil
+}
+
+func (r *ruleRepository) UpdateRule(ctx context.Context, rule *entity.Rule) error {
+	query := `
+		UPDATE rules
+		SET name = $1, priority = $2, active = $3, note_contains = $4, note_regex = $5, min_amount = $6,
+			max_amount = $7, transaction_type = $8, set_category_id = $9, add_tags = $10, note_rewrite = $11,
+			updated_at = $12
+		WHERE id = $13
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Priority,
+		rule.Active,
+		nullIfEmpty(rule.NoteContains),
+		nullIfEmpty(rule.NoteRegex),
+		rule.MinAmount,
+		rule.MaxAmount,
+		nullIfEmpty(rule.TransactionType),
+		rule.SetCategoryID,
+		pq.Array(rule.AddTags),
+		rule.NoteRewrite,
+		rule.UpdatedAt,
+		rule.ID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] UpdateRule failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *ruleRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM rules WHERE id = $1`, id); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *ruleRepository) GetMatchMode(ctx context.Context, userID uuid.UUID) (string, error) {
+	var mode string
+	err := r.db.QueryRowContext(ctx, `SELECT match_mode FROM rule_settings WHERE user_id = $1`, userID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return entity.MatchModeFirst, nil
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetMatchMode failed: %v\n", err)
+		return "", errx.ErrDatabaseError
+	}
+
+	return mode, nil
+}
+
+func (r *ruleRepository) UpsertSettings(ctx context.Context, settings *entity.Settings) error {
+	query := `
+		INSERT INTO rule_settings (user_id, match_mode, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET match_mode = EXCLUDED.match_mode,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, settings.UserID, settings.MatchMode, settings.UpdatedAt); err != nil {
+		log.Printf("[DB ERROR] UpsertSettings failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// GetTransactionTargets membaca transaksi yang akan diproses rule. Pembayaran hutang dilewati
+// karena hanya boleh diubah lewat endpoint debt.
+func (r *ruleRepository) GetTransactionTargets(ctx context.Context, userID uuid.UUID, startDate, endDate string) ([]entity.TransactionTarget, error) {
+	query := `
+		SELECT id, version, type, amount, COALESCE(note, ''), COALESCE(category_id, ''), tags
+		FROM transactions
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+			AND debt_id IS NULL
+			AND ($2 = '' OR date >= $2::DATE)
+			AND ($3 = '' OR date <= $3::DATE)
+		ORDER BY date ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("[DB ERROR] GetTransactionTargets failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var targets []entity.TransactionTarget
+	for rows.Next() {
+		var tt entity.TransactionTarget
+		if err := rows.Scan(
+			&tt.TransactionID,
+			&tt.Version,
+			&tt.Target.TransactionType,
+			&tt.Target.Amount,
+			&tt.Target.Note,
+			&tt.Target.CategoryID,
+			pq.Array(&tt.Target.Tags),
+		); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if tt.Target.Note, err = r.keyring.DecryptString(ctx, userID, tt.Target.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		targets = append(targets, tt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return targets, nil
+}
+
+// UpdateTransactionTargets menyimpan hasil rule ke transaksi lama. Seperti edit biasa, kondisi
+// sebelum perubahan dicatat sebagai revisi dan update ditolak jika transaksi sudah diubah sejak dibaca.
+func (r *ruleRepository) UpdateTransactionTargets(ctx context.Context, userID uuid.UUID, targets []entity.TransactionTarget) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Note di transaksi sudah terenkripsi, jadi snapshot revisi bisa disalin langsung dari barisnya
+	revisionStmt, err := dbTx.PrepareContext(ctx, `
+		INSERT INTO transaction_revisions (id, transaction_id, version, changed_by, snapshot, created_at)
+		SELECT $1, t.id,
+			COALESCE((SELECT MAX(version) FROM transaction_revisions WHERE transaction_id = t.id), 0) + 1,
+			$2,
+			JSON_BUILD_OBJECT(
+				'transaction_type', t.type,
+				'amount', t.amount,
+				'category_id', COALESCE(t.category_id, ''),
+				'period', COALESCE(t.period, ''),
+				'note', COALESCE(t.note, ''),
+				'date', TO_CHAR(t.date, 'YYYY-MM-DD'),
+				'tags', t.tags
+			),
+			NOW()
+		FROM transactions t
+		WHERE t.id = $3 AND t.user_id = $2 AND t.version = $4 AND t.deleted_at IS NULL
+	`)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer revisionStmt.Close()
+
+	stmt, err := dbTx.PrepareContext(ctx, `
+		UPDATE transactions
+		SET category_id = $1, note = $2, tags = $3, updated_at = NOW(), version = version + 1
+		WHERE id = $4 AND user_id = $5 AND version = $6
+	`)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer stmt.Close()
+
+	for _, tt := range targets {
+		res, err := revisionStmt.ExecContext(ctx, uuid.New(), userID, tt.TransactionID, tt.Version)
+		if err != nil {
+			log.Printf("[DB ERROR] CreateRevision failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return errx.ErrVersionMismatch
+		}
+
+		note, err := r.keyring.EncryptString(ctx, userID, tt.Target.Note)
+		if err != nil {
+			log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+			return errx.ErrInternalServer
+		}
+
+		res, err = stmt.ExecContext(ctx,
+			nullIfEmpty(tt.Target.CategoryID),
+			note,
+			pq.Array(tt.Target.Tags),
+			tt.TransactionID,
+			userID,
+			tt.Version,
+		)
+		if err != nil {
+			return errx.FromWrite("UpdateTransactionTargets", err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return errx.ErrVersionMismatch
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRule(row rowScanner) (*entity.Rule, error) {
+	rule := &entity.Rule{}
+	var minAmount, maxAmount sql.NullFloat64
+	var setCategoryID, noteRewrite sql.NullString
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.Active,
+		&rule.NoteContains,
+		&rule.NoteRegex,
+		&minAmount,
+		&maxAmount,
+		&rule.TransactionType,
+		&setCategoryID,
+		pq.Array(&rule.AddTags),
+		&noteRewrite,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if minAmount.Valid {
+		rule.MinAmount = &minAmount.Float64
+	}
+	if maxAmount.Valid {
+		rule.MaxAmount = &maxAmount.Float64
+	}
+	if setCategoryID.Valid {
+		rule.SetCategoryID = &setCategoryID.String
+	}
+	if noteRewrite.Valid {
+		rule.NoteRewrite = &noteRewrite.String
+	}
+
+	return rule, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+)
+
+type compiledRule struct {
+	rule  *entity.Rule
+	regex *regexp.Regexp
+}
+
+// Engine menjalankan kumpulan rule milik satu user terhadap transaksi.
+// Regex dikompilasi sekali sehingga aman dipakai untuk ribuan transaksi.
+type Engine struct {
+	rules []compiledRule
+	mode  string
+}
+
+func NewEngine(rules []*entity.Rule, mode string) *Engine {
+	sorted := make([]*entity.Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Active {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	compiled := make([]compiledRule, 0, len(sorted))
+	for _, r := range sorted {
+		cr := compiledRule{rule: r}
+		if r.NoteRegex != "" {
+			re, err := regexp.Compile("(?i)" + r.NoteRegex)
+			if err != nil {
+				// Rule dengan regex rusak dilewati, validasi utama ada saat create/update
+				continue
+			}
+			cr.regex = re
+		}
+		compiled = append(compiled, cr)
+	}
+
+	if mode != entity.MatchModeAll {
+		mode = entity.MatchModeFirst
+	}
+
+	return &Engine{rules: compiled, mode: mode}
+}
+
+// Apply mengembalikan target setelah rule dijalankan beserta ID rule yang cocok.
+// Jika keepCategory true, kategori yang sudah terisi tidak ditimpa.
+func (e *Engine) Apply(target entity.Target, keepCategory bool) (entity.Target, []uuid.UUID) {
+	result := target
+	result.Tags = append([]string{}, target.Tags...)
+
+	var matched []uuid.UUID
+	for _, cr := range e.rules {
+		if !cr.matches(result) {
+			continue
+		}
+		matched = append(matched, cr.rule.ID)
+		cr.apply(&result, keepCategory && target.CategoryID != "")
+
+		if e.mode == entity.MatchModeFirst {
+			break
+		}
+	}
+
+	return result, matched
+}
+
+func (cr compiledRule) matches(t entity.Target) bool {
+	r := cr.rule
+	if r.TransactionType != "" && r.TransactionType != t.TransactionType {
+		return false
+	}
+	if r.MinAmount != nil && t.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && t.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.NoteContains != "" && !strings.Contains(strings.ToLower(t.Note), strings.ToLower(r.NoteContains)) {
+		return false
+	}
+	if cr.regex != nil && !cr.regex.MatchString(t.Note) {
+		return false
+	}
+	return true
+}
+
+func (cr compiledRule) apply(t *entity.Target, keepCategory bool) {
+	r := cr.rule
+	if r.SetCategoryID != nil && !keepCategory {
+		t.CategoryID = *r.SetCategoryID
+	}
+
+	for _, tag := range r.AddTags {
+		if !containsTag(t.Tags, tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+
+	if r.NoteRewrite != nil {
+		// Dengan regex, rewrite boleh memakai capture group seperti $1
+		if cr.regex != nil {
+			t.Note = cr.regex.ReplaceAllString(t.Note, *r.NoteRewrite)
+		} else {
+			t.Note = *r.NoteRewrite
+		}
+	}
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func targetsEqual(a, b entity.Target) bool {
+	if a.CategoryID != b.CategoryID || a.Note != b.Note || len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/rule/repository"
+	suggestionService "github.com/kenziehh/cashflow-be/internal/domain/suggestion/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+type RuleService interface {
+	CreateRule(ctx context.Context, userID uuid.UUID, req dto.RuleRequest) (*entity.Rule, error)
+	GetRules(ctx context.Context, userID uuid.UUID) ([]*entity.Rule, error)
+	UpdateRule(ctx context.Context, userID, id uuid.UUID, req dto.RuleRequest) (*entity.Rule, error)
+	DeleteRule(ctx context.Context, userID, id uuid.UUID) error
+	GetSettings(ctx context.Context, userID uuid.UUID) (*entity.Settings, error)
+	UpdateSettings(ctx context.Context, userID uuid.UUID, req dto.UpdateSettingsRequest) (*entity.Settings, error)
+	// NewEngine memuat rule aktif milik user, dipakai saat create transaksi dan import
+	NewEngine(ctx context.Context, userID uuid.UUID) (*Engine, error)
+	ApplyToHistory(ctx context.Context, userID uuid.UUID, req dto.ApplyRulesRequest) (*dto.ApplyRulesResponse, error)
+}
+
+type ruleService struct {
+	repo        repository.RuleRepository
+	suggestions suggestionService.SuggestionService
+}
+
+func NewRuleService(repo repository.RuleRepository, suggestions suggestionService.SuggestionService) RuleService {
+	return &ruleService{
+		repo:        repo,
+		suggestions: suggestions,
+	}
+}
+
+func (s *ruleService) CreateRule(ctx context.Context, userID uuid.UUID, req dto.RuleRequest) (*entity.Rule, error) {
+	if err := validateRuleRequest(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &entity.Rule{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	fillRule(rule, req, now)
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *ruleService) GetRules(ctx context.Context, userID uuid.UUID) ([]*entity.Rule, error) {
+	return s.repo.GetRulesByUserID(ctx, userID)
+}
+
+func (s *ruleService) UpdateRule(ctx context.Context, userID, id uuid.UUID, req dto.RuleRequest) (*entity.Rule, error) {
+	rule, err := s.getOwnedRule(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRuleRequest(req); err != nil {
+		return nil, err
+	}
+
+	fillRule(rule, req, time.Now())
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *ruleService) DeleteRule(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwnedRule(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteRule(ctx, id)
+}
+
+func (s *ruleService) GetSettings(ctx context.Context, userID uuid.UUID) (*entity.Settings, error) {
+	mode, err := s.repo.GetMatchMode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Settings{UserID: userID, MatchMode: mode}, nil
+}
+
+func (s *ruleService) UpdateSettings(ctx context.Context, userID uuid.UUID, req dto.UpdateSettingsRequest) (*entity.Settings, error) {
+	settings := &entity.Settings{
+		UserID:    userID,
+		MatchMode: req.MatchMode,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.repo.UpsertSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *ruleService) NewEngine(ctx context.Context, userID uuid.UUID) (*Engine, error) {
+	rules, err := s.repo.GetRulesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := s.repo.GetMatchMode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEngine(rules, mode), nil
+}
+
+func (s *ruleService) ApplyToHistory(ctx context.Context, userID uuid.UUID, req dto.ApplyRulesRequest) (*dto.ApplyRulesResponse, error) {
+	engine, err := s.NewEngine(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := s.repo.GetTransactionTargets(ctx, userID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ApplyRulesResponse{
+		DryRun:  req.DryRun,
+		Scanned: len(targets),
+		Changes: []dto.RuleChange{},
+	}
+
+	var updates []entity.TransactionTarget
+	for _, tt := range targets {
+		after, matched := engine.Apply(tt.Target, false)
+		if len(matched) == 0 || targetsEqual(tt.Target, after) {
+			continue
+		}
+
+		resp.Changes = append(resp.Changes, dto.RuleChange{
+			TransactionID: tt.TransactionID,
+			MatchedRules:  matched,
+			Before:        tt.Target,
+			After:         after,
+		})
+		updates = append(updates, entity.TransactionTarget{TransactionID: tt.TransactionID, Version: tt.Version, Target: after})
+	}
+	resp.Changed = len(updates)
+
+	if !req.DryRun && len(updates) > 0 {
+		if err := s.repo.UpdateTransactionTargets(ctx, userID, updates); err != nil {
+			return nil, err
+		}
+
+		// Banyak kategori berubah sekaligus, lebih murah melatih ulang model saran kategori
+		if err := s.suggestions.Retrain(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *ruleService) getOwnedRule(ctx context.Context, userID, id uuid.UUID) (*entity.Rule, error) {
+	rule, err := s.repo.GetRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this rule")
+	}
+
+	return rule, nil
+}
+
+func validateRuleRequest(req dto.RuleRequest) error {
+	if req.NoteRegex != "" {
+		if _, err := regexp.Compile(req.NoteRegex); err != nil {
+			return errx.NewBadRequestError("note_regex is not a valid regular expression")
+		}
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return errx.NewBadRequestError("min_amount must not be greater than max_amount")
+	}
+
+	if req.SetCategoryID == nil && len(req.AddTags) == 0 && req.NoteRewrite == nil {
+		return errx.NewBadRequestError("Rule must have at least one action")
+	}
+
+	return nil
+}
+
+func fillRule(rule *entity.Rule, req dto.RuleRequest, now time.Time) {
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.Active = req.Active == nil || *req.Active
+	rule.NoteContains = req.NoteContains
+	rule.NoteRegex = req.NoteRegex
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.TransactionType = req.TransactionType
+	rule.SetCategoryID = req.SetCategoryID
+	rule.AddTags = req.AddTags
+	if rule.AddTags == nil {
+		rule.AddTags = []string{}
+	}
+	rule.NoteRewrite = req.NoteRewrite
+	rule.UpdatedAt = now
+}
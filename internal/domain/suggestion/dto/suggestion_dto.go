@@ -0,0 +1,20 @@
+package dto
+
+type SuggestCategoryRequest struct {
+	Note   string  `json:"note" validate:"required,max=500"`
+	Amount float64 `json:"amount" validate:"gte=0"`
+	Date   string  `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Limit  int     `json:"limit,omitempty" validate:"omitempty,min=1,max=10"`
+}
+
+type CategorySuggestion struct {
+	CategoryID   string  `json:"category_id" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	CategoryName string  `json:"category_name"`
+	Confidence   float64 `json:"confidence"`
+}
+
+type SuggestCategoryResponse struct {
+	// Jumlah transaksi berkategori yang menjadi dasar model
+	TrainedOn   int                  `json:"trained_on"`
+	Suggestions []CategorySuggestion `json:"suggestions"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+// Sample adalah transaksi berkategori yang dipakai untuk melatih model.
+type Sample struct {
+	CategoryID string
+	Note       string
+	Amount     float64
+	Date       string
+}
+
+// Document adalah sample yang sudah diubah menjadi token fitur.
+type Document struct {
+	CategoryID string
+	Tokens     []string
+}
+
+type Class struct {
+	CategoryID   string
+	CategoryName string
+	DocCount     int
+	TokenCount   int
+}
+
+// Model berisi statistik naive Bayes milik satu user. TokenCounts hanya
+// memuat token yang relevan untuk query, dikelompokkan per category ID.
+type Model struct {
+	Documents   int
+	Vocabulary  int
+	Classes     []Class
+	TokenCounts map[string]map[string]int
+}
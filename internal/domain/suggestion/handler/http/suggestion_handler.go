@@ -0,0 +1,59 @@
+package http
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+type SuggestionHandler struct {
+	service  service.SuggestionService
+	validate *validator.Validate
+}
+
+func NewSuggestionHandler(service service.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// SuggestCategory godoc
+// @Summary Suggest a category for a transaction
+// @Description Rank categories for a note, amount and date using a model trained on the user's own categorized transactions
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body dto.SuggestCategoryRequest true "Suggest category request"
+// @Success 200 {object} response.Response{data=dto.SuggestCategoryResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/suggest-category [post]
+func (h *SuggestionHandler) SuggestCategory(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.SuggestCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.SuggestCategory(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Category suggestions retrieved successfully", result))
+}
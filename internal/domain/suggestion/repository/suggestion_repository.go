@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+type SuggestionRepository interface {
+	IsTrained(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetTrainingSamples(ctx context.Context, userID uuid.UUID) ([]entity.Sample, error)
+	ReplaceModel(ctx context.Context, userID uuid.UUID, docs []entity.Document) error
+	AddDocument(ctx context.Context, userID uuid.UUID, doc entity.Document, delta int) error
+	GetModel(ctx context.Context, userID uuid.UUID, tokens []string) (*entity.Model, error)
+}
+
+type suggestionRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewSuggestionRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) SuggestionRepository {
+	return &suggestionRepository{
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
+	}
+}
+
+func (r *suggestionRepository) IsTrained(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM category_model_states WHERE user_id = $1)`, userID,
+	).Scan(&exists)
+	if err != nil {
+		log.Printf("[DB ERROR] IsTrained failed: %v\n", err)
+		return false, errx.ErrDatabaseError
+	}
+
+	return exists, nil
+}
+
+func (r *suggestionRepository) GetTrainingSamples(ctx context.Context, userID uuid.UUID) ([]entity.Sample, error) {
+	query := `
+		SELECT category_id, COALESCE(note, ''), amount, date
+		FROM transactions
+		WHERE user_id = $1 AND category_id IS NOT NULL AND debt_id IS NULL AND deleted_at IS NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetTrainingSamples failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var samples []entity.Sample
+	for rows.Next() {
+		var s entity.Sample
+		var date time.Time
+		if err := rows.Scan(&s.CategoryID, &s.Note, &s.Amount, &date); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if s.Note, err = r.keyring.DecryptString(ctx, userID, s.Note); err != nil {
+			log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+			return nil, errx.ErrInternalServer
+		}
+		s.Date = date.Format("2006-01-02")
+		samples = append(samples, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return samples, nil
+}
+
+// ReplaceModel menghapus model lama dan menyimpan hasil training penuh dalam satu transaksi.
+func (r *suggestionRepository) ReplaceModel(ctx context.Context, userID uuid.UUID, docs []entity.Document) error {
+	classes := map[string]*entity.Class{}
+	tokens := map[string]map[string]int{}
+	for _, doc := range docs {
+		c, ok := classes[doc.CategoryID]
+		if !ok {
+			c = &entity.Class{CategoryID: doc.CategoryID}
+			classes[doc.CategoryID] = c
+			tokens[doc.CategoryID] = map[string]int{}
+		}
+		c.DocCount++
+		c.TokenCount += len(doc.Tokens)
+		for _, t := range doc.Tokens {
+			tokens[doc.CategoryID][t]++
+		}
+	}
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	for _, table := range []string{"category_model_tokens", "category_model_classes", "category_model_states"} {
+		if _, err := dbTx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			log.Printf("[DB ERROR] ReplaceModel failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	classStmt, err := dbTx.PrepareContext(ctx, `
+		INSERT INTO category_model_classes (user_id, category_id, doc_count, token_count)
+		VALUES ($1, $2, $3, $4)
+	`)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer classStmt.Close()
+
+	tokenStmt, err := dbTx.PrepareContext(ctx, `
+		INSERT INTO category_model_tokens (user_id, category_id, token, count)
+		VALUES ($1, $2, $3, $4)
+	`)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer tokenStmt.Close()
+
+	for categoryID, c := range classes {
+		if _, err := classStmt.ExecContext(ctx, userID, categoryID, c.DocCount, c.TokenCount); err != nil {
+			log.Printf("[DB ERROR] ReplaceModel failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+		for token, count := range tokens[categoryID] {
+			if _, err := tokenStmt.ExecContext(ctx, userID, categoryID, token, count); err != nil {
+				log.Printf("[DB ERROR] ReplaceModel failed: %v\n", err)
+				return errx.ErrDatabaseError
+			}
+		}
+	}
+
+	if _, err := dbTx.ExecContext(ctx,
+		`INSERT INTO category_model_states (user_id, documents, trained_at) VALUES ($1, $2, $3)`,
+		userID, len(docs), time.Now(),
+	); err != nil {
+		log.Printf("[DB ERROR] ReplaceModel failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// AddDocument menambah (delta 1) atau mengurangi (delta -1) satu dokumen dari model.
+// Jika model user belum pernah dilatih, perubahan diabaikan karena training penuh
+// berikutnya akan membaca seluruh riwayat.
+func (r *suggestionRepository) AddDocument(ctx context.Context, userID uuid.UUID, doc entity.Document, delta int) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE category_model_states
+		SET documents = GREATEST(documents + $2, 0)
+		WHERE user_id = $1
+	`, userID, delta)
+	if err != nil {
+		log.Printf("[DB ERROR] AddDocument failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+
+	if _, err := dbTx.ExecContext(ctx, `
+		INSERT INTO category_model_classes (user_id, category_id, doc_count, token_count)
+		VALUES ($1, $2, GREATEST($3, 0), GREATEST($4, 0))
+		ON CONFLICT (user_id, category_id) DO UPDATE
+		SET doc_count = GREATEST(category_model_classes.doc_count + $3, 0),
+			token_count = GREATEST(category_model_classes.token_count + $4, 0)
+	`, userID, doc.CategoryID, delta, delta*len(doc.Tokens)); err != nil {
+		log.Printf("[DB ERROR] AddDocument failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if _, err := dbTx.ExecContext(ctx, `
+		INSERT INTO category_model_tokens (user_id, category_id, token, count)
+		SELECT $1, $2, t, GREATEST($4, 0) FROM UNNEST($3::TEXT[]) AS t
+		ON CONFLICT (user_id, category_id, token) DO UPDATE
+		SET count = GREATEST(category_model_tokens.count + $4, 0)
+	`, userID, doc.CategoryID, pq.Array(doc.Tokens), delta); err != nil {
+		log.Printf("[DB ERROR] AddDocument failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	// Bersihkan baris yang sudah nol supaya ukuran vocabulary tetap akurat
+	if delta < 0 {
+		if _, err := dbTx.ExecContext(ctx,
+			`DELETE FROM category_model_tokens WHERE user_id = $1 AND category_id = $2 AND count = 0`,
+			userID, doc.CategoryID,
+		); err != nil {
+			return errx.ErrDatabaseError
+		}
+		if _, err := dbTx.ExecContext(ctx,
+			`DELETE FROM category_model_classes WHERE user_id = $1 AND category_id = $2 AND doc_count = 0`,
+			userID, doc.CategoryID,
+		); err != nil {
+			return errx.ErrDatabaseError
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *suggestionRepository) GetModel(ctx context.Context, userID uuid.UUID, tokens []string) (*entity.Model, error) {
+	model := &entity.Model{TokenCounts: map[string]map[string]int{}}
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT s.documents, (SELECT COUNT(DISTINCT token) FROM category_model_tokens WHERE user_id = $1)
+		FROM category_model_states s
+		WHERE s.user_id = $1
+	`, userID).Scan(&model.Documents, &model.Vocabulary)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model, nil
+		}
+		log.Printf("[DB ERROR] GetModel failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT mc.category_id, c.name, mc.doc_count, mc.token_count
+		FROM category_model_classes mc
+		JOIN categories c ON c.id = mc.category_id
+		WHERE mc.user_id = $1 AND mc.doc_count > 0
+	`, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetModel failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.Class
+		if err := rows.Scan(&c.CategoryID, &c.CategoryName, &c.DocCount, &c.TokenCount); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		model.Classes = append(model.Classes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	if len(tokens) == 0 {
+		return model, nil
+	}
+
+	tokenRows, err := r.db.QueryContext(ctx, `
+		SELECT category_id, token, count
+		FROM category_model_tokens
+		WHERE user_id = $1 AND token = ANY($2)
+	`, userID, pq.Array(tokens))
+	if err != nil {
+		log.Printf("[DB ERROR] GetModel failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer tokenRows.Close()
+
+	for tokenRows.Next() {
+		var categoryID, token string
+		var count int
+		if err := tokenRows.Scan(&categoryID, &token, &count); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if model.TokenCounts[categoryID] == nil {
+			model.TokenCounts[categoryID] = map[string]int{}
+		}
+		model.TokenCounts[categoryID][token] = count
+	}
+	if err := tokenRows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return model, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/entity"
+)
+
+const maxTokenLength = 64
+
+// Features mengubah transaksi menjadi token unik: kata dari note, bucket nominal
+// (skala log2) dan hari dalam minggu. Angka murni dibuang karena biasanya nomor
+// invoice atau tanggal yang tidak berulang.
+func Features(note string, amount float64, date string) []string {
+	seen := map[string]bool{}
+	var tokens []string
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			tokens = append(tokens, t)
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(note), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) < 2 || isNumeric(w) {
+			continue
+		}
+		if r := []rune(w); len(r) > maxTokenLength {
+			w = string(r[:maxTokenLength])
+		}
+		add(w)
+	}
+
+	if amount > 0 {
+		add("#amt" + strconv.Itoa(int(math.Log2(amount+1))))
+	}
+
+	if d, err := time.Parse("2006-01-02", date); err == nil {
+		add("#dow" + strconv.Itoa(int(d.Weekday())))
+	}
+
+	return tokens
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Classify menghitung skor multinomial naive Bayes dengan Laplace smoothing,
+// lalu menormalkan skor log menjadi confidence yang totalnya 1.
+func Classify(model *entity.Model, tokens []string, limit int) []dto.CategorySuggestion {
+	if model.Documents == 0 || len(model.Classes) == 0 {
+		return []dto.CategorySuggestion{}
+	}
+
+	vocab := float64(model.Vocabulary + 1)
+	scores := make([]float64, len(model.Classes))
+	best := math.Inf(-1)
+	for i, c := range model.Classes {
+		score := math.Log(float64(c.DocCount) / float64(model.Documents))
+		denom := float64(c.TokenCount) + vocab
+		counts := model.TokenCounts[c.CategoryID]
+		for _, t := range tokens {
+			score += math.Log((float64(counts[t]) + 1) / denom)
+		}
+		scores[i] = score
+		if score > best {
+			best = score
+		}
+	}
+
+	var total float64
+	for i := range scores {
+		scores[i] = math.Exp(scores[i] - best)
+		total += scores[i]
+	}
+
+	suggestions := make([]dto.CategorySuggestion, len(model.Classes))
+	for i, c := range model.Classes {
+		suggestions[i] = dto.CategorySuggestion{
+			CategoryID:   c.CategoryID,
+			CategoryName: c.CategoryName,
+			Confidence:   math.Round(scores[i]/total*10000) / 10000,
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/suggestion/repository"
+)
+
+const defaultSuggestionLimit = 3
+
+type SuggestionService interface {
+	SuggestCategory(ctx context.Context, userID uuid.UUID, req dto.SuggestCategoryRequest) (*dto.SuggestCategoryResponse, error)
+	// Learn dan Forget memperbarui model secara incremental saat transaksi berubah
+	Learn(ctx context.Context, userID uuid.UUID, sample entity.Sample) error
+	Forget(ctx context.Context, userID uuid.UUID, sample entity.Sample) error
+	Retrain(ctx context.Context, userID uuid.UUID) error
+}
+
+type suggestionService struct {
+	repo repository.SuggestionRepository
+}
+
+func NewSuggestionService(repo repository.SuggestionRepository) SuggestionService {
+	return &suggestionService{
+		repo: repo,
+	}
+}
+
+func (s *suggestionService) SuggestCategory(ctx context.Context, userID uuid.UUID, req dto.SuggestCategoryRequest) (*dto.SuggestCategoryResponse, error) {
+	trained, err := s.repo.IsTrained(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Model dilatih penuh dari riwayat saat pertama kali dibutuhkan
+	if !trained {
+		if err := s.Retrain(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := Features(req.Note, req.Amount, req.Date)
+	model, err := s.repo.GetModel(ctx, userID, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSuggestionLimit
+	}
+
+	return &dto.SuggestCategoryResponse{
+		TrainedOn:   model.Documents,
+		Suggestions: Classify(model, tokens, limit),
+	}, nil
+}
+
+func (s *suggestionService) Learn(ctx context.Context, userID uuid.UUID, sample entity.Sample) error {
+	return s.update(ctx, userID, sample, 1)
+}
+
+func (s *suggestionService) Forget(ctx context.Context, userID uuid.UUID, sample entity.Sample) error {
+	return s.update(ctx, userID, sample, -1)
+}
+
+func (s *suggestionService) Retrain(ctx context.Context, userID uuid.UUID) error {
+	samples, err := s.repo.GetTrainingSamples(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]entity.Document, 0, len(samples))
+	for _, sample := range samples {
+		docs = append(docs, toDocument(sample))
+	}
+
+	return s.repo.ReplaceModel(ctx, userID, docs)
+}
+
+func (s *suggestionService) update(ctx context.Context, userID uuid.UUID, sample entity.Sample, delta int) error {
+	if sample.CategoryID == "" {
+		return nil
+	}
+
+	return s.repo.AddDocument(ctx, userID, toDocument(sample), delta)
+}
+
+func toDocument(sample entity.Sample) entity.Document {
+	return entity.Document{
+		CategoryID: sample.CategoryID,
+		Tokens:     Features(sample.Note, sample.Amount, sample.Date),
+	}
+}
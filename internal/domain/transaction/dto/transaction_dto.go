@@ -1,17 +1,29 @@
 package dto
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	categoryEntity "github.com/kenziehh/cashflow-be/internal/domain/category/entity"
+	maximumSpendEntity "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/entity"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/importer"
 )
 
 type CreateTransactionRequest struct {
 	TransactionType string  `json:"transaction_type" validate:"required,oneof=income expense"`
 	Amount          float64 `json:"amount" validate:"required,gt=0"`
-	CategoryID      string  `json:"category_id" validate:"required,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	// Boleh kosong, kategori akan diisi oleh rule auto-categorization jika ada yang cocok
+	CategoryID      string  `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
 	Note            string  `json:"note,omitempty"`
 	Period          string  `json:"period" validate:"required,oneof=daily weekly monthly yearly"`
 	Date            string  `json:"date" validate:"required,datetime=2006-01-02"`
-	ProofFile       string  `json:"proof_file,omitempty"`
+	Tags            []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+	// Wajib true untuk tetap menyimpan transaksi yang terdeteksi mirip duplikat
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
 }
 
 type UpdateTransactionRequest struct {
@@ -22,10 +34,89 @@ type UpdateTransactionRequest struct {
 	Note            string  `json:"note,omitempty"`
 	Period          string  `json:"period" validate:"required,oneof=daily weekly monthly yearly"`
 	Date            string  `json:"date" validate:"required,datetime=2006-01-02"`
-	ProofFile       string  `json:"proof_file,omitempty"`
+	Tags            []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+}
+
+// AttachmentUpload adalah isi file multipart yang akan disimpan sebagai lampiran transaksi.
+type AttachmentUpload struct {
+	Filename string
+	Data     []byte
+}
+
+type CreateAttachmentLinkRequest struct {
+	// Masa berlaku dalam detik, default 15 menit dan maksimal 7 hari
+	ExpiresIn int `json:"expires_in,omitempty" validate:"omitempty,min=60,max=604800" example:"900"`
+	// Link hanya bisa dipakai sekali lalu otomatis dicabut
+	OneTime bool `json:"one_time,omitempty"`
+}
+
+type AttachmentLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	OneTime   bool      `json:"one_time"`
+}
+
+// SignedAttachmentParams adalah query string dari URL yang dibuat CreateAttachmentLink.
+type SignedAttachmentParams struct {
+	Expires   int64  `query:"expires"`
+	Nonce     string `query:"nonce"`
+	Signature string `query:"sig"`
+	Size      string `query:"size" validate:"omitempty,oneof=thumb medium original"`
+}
+
+type AttachmentDownloadParams struct {
+	Size string `query:"size" validate:"omitempty,oneof=thumb medium original"`
+}
+
+
+
+// PatchTransactionRequest mengikuti JSON Merge Patch (RFC 7396): field yang tidak dikirim
+// tidak berubah, sedangkan field bernilai null dicatat di Cleared untuk dikosongkan.
+type PatchTransactionRequest struct {
+	TransactionType *string   `json:"transaction_type,omitempty" validate:"omitempty,oneof=income expense"`
+	Amount          *float64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	CategoryID      *string   `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Note            *string   `json:"note,omitempty"`
+	Period          *string   `json:"period,omitempty" validate:"omitempty,oneof=daily weekly monthly yearly"`
+	Date            *string   `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Tags            *[]string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+
+	Cleared map[string]bool `json:"-" swaggerignore:"true"`
 }
 
+func (p *PatchTransactionRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
 
+	targets := map[string]interface{}{
+		"transaction_type": &p.TransactionType,
+		"amount":           &p.Amount,
+		"category_id":      &p.CategoryID,
+		"note":             &p.Note,
+		"period":           &p.Period,
+		"date":             &p.Date,
+		"tags":             &p.Tags,
+	}
+
+	p.Cleared = map[string]bool{}
+	for key, raw := range fields {
+		target, ok := targets[key]
+		if !ok {
+			return fmt.Errorf("unknown field %q", key)
+		}
+		if string(bytes.TrimSpace(raw)) == "null" {
+			p.Cleared[key] = true
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return fmt.Errorf("invalid value for %q", key)
+		}
+	}
+
+	return nil
+}
 
 type PaginationMeta struct {
 	CurrentPage  int `json:"current_page"`
@@ -57,4 +148,261 @@ type SummaryTransactionResponse struct {
 	TotalExpenseMonthly float64 `json:"total_expense_monthly"`
 	TotalIncomeDaily    float64 `json:"total_income_daily"`
 	TotalExpenseDaily   float64 `json:"total_expense_daily"`
-}
\ No newline at end of file
+}
+
+type DuplicateCandidate struct {
+	Transaction    *entity.Transaction `json:"transaction"`
+	NoteSimilarity float64             `json:"note_similarity"`
+	DaysApart      int                 `json:"days_apart"`
+}
+
+type DuplicateScanParams struct {
+	Days      int    `query:"days" validate:"omitempty,min=0,max=30"`
+	StartDate string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type DuplicatePair struct {
+	First          *entity.Transaction `json:"first"`
+	Second         *entity.Transaction `json:"second"`
+	NoteSimilarity float64             `json:"note_similarity"`
+	DaysApart      int                 `json:"days_apart"`
+}
+
+type TrashedTransaction struct {
+	*entity.Transaction
+	// Waktu transaksi akan dihapus permanen dari trash
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+type MergeTransactionsRequest struct {
+	// Transaksi duplikat yang akan digabung lalu dihapus
+	DuplicateID string `json:"duplicate_id" validate:"required,uuid"`
+	// Versi transaksi duplikat (nilai ETag) yang diharapkan
+	DuplicateVersion int `json:"duplicate_version" validate:"required,min=1"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type RevisionDiff struct {
+	Version   int             `json:"version"`
+	ChangedBy *uuid.UUID      `json:"changed_by,omitempty"`
+	ChangedAt time.Time       `json:"changed_at"`
+	Before    entity.Snapshot `json:"before"`
+	Changes   []FieldChange   `json:"changes"`
+}
+
+type BatchOperation struct {
+	Op string `json:"op" validate:"required,oneof=create update delete"`
+	// Wajib untuk update dan delete
+	ID string `json:"id,omitempty" validate:"omitempty,uuid"`
+	// Versi transaksi (nilai ETag) yang diharapkan, wajib untuk update dan delete
+	Version int                       `json:"version,omitempty" validate:"omitempty,min=1"`
+	Create  *CreateTransactionRequest `json:"create,omitempty"`
+	Update  *UpdateTransactionRequest `json:"update,omitempty"`
+}
+
+type BatchTransactionRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=100"`
+}
+
+type BatchOperationResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	// Kode status seperti endpoint tunggal; 424 berarti operasi dibatalkan karena operasi lain gagal
+	Status      int                  `json:"status"`
+	Transaction *entity.Transaction  `json:"transaction,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	Duplicates  []DuplicateCandidate `json:"duplicates,omitempty"`
+}
+
+type RevertTransactionRequest struct {
+	// Versi revisi yang kondisinya akan dipulihkan
+	Version int `json:"version" validate:"required,min=1"`
+}
+
+type SyncParams struct {
+	// Token dari response sync sebelumnya, kosong untuk sync penuh
+	Since string `query:"since"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=1000"`
+}
+
+type SyncTransactionChanges struct {
+	Created []*entity.Transaction `json:"created"`
+	Updated []*entity.Transaction `json:"updated"`
+	Deleted []string              `json:"deleted"`
+}
+
+type SyncCategoryChanges struct {
+	Created []*categoryEntity.Category `json:"created"`
+	Updated []*categoryEntity.Category `json:"updated"`
+	Deleted []string                   `json:"deleted"`
+}
+
+type SyncBudgetChanges struct {
+	Created []*maximumSpendEntity.MaximumSpend `json:"created"`
+	Updated []*maximumSpendEntity.MaximumSpend `json:"updated"`
+	Deleted []string                           `json:"deleted"`
+}
+
+type SyncResponse struct {
+	Transactions SyncTransactionChanges `json:"transactions"`
+	Categories   SyncCategoryChanges    `json:"categories"`
+	Budgets      SyncBudgetChanges      `json:"budgets"`
+	// Dikirim sebagai since pada sync berikutnya
+	Token string `json:"token"`
+	// true jika masih ada perubahan yang belum terkirim, panggil sync lagi dengan token baru
+	HasMore bool `json:"has_more"`
+}
+
+type ImportParams struct {
+	// Salah satu format yang didukung: ofx, qfx, qif, moneylover, wallet atau spendee
+	Format string `query:"format" validate:"required" example:"moneylover"`
+	// Dipakai untuk baris yang kategorinya tidak bisa ditentukan dari mapping maupun rule
+	DefaultCategoryID string `query:"default_category_id" validate:"omitempty,ulid"`
+	// Hanya hitung hasil import tanpa menyimpan transaksi
+	DryRun bool `query:"dry_run"`
+}
+
+type ImportResult struct {
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	// Jumlah transaksi yang terbaca dari file
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	// Transaksi dengan external ID yang sudah pernah diimport, termasuk yang ada di trash
+	AlreadyImported int `json:"already_imported"`
+	// Transaksi yang dilewati karena kategorinya tidak bisa ditentukan. Tambahkan mapping
+	// untuk unmapped_categories lalu import ulang file yang sama; transaksi yang sudah
+	// masuk tidak akan diduplikasi.
+	Uncategorized      int      `json:"uncategorized"`
+	// Transaksi yang dilewati karena tanggalnya ada di periode rekonsiliasi yang dikunci
+	Locked             int      `json:"locked"`
+	UnmappedCategories []string `json:"unmapped_categories"`
+	// Baris yang tidak bisa dibaca
+	Errors []importer.RowError `json:"errors"`
+}
+
+type CategoryMappingRequest struct {
+	Source         string `json:"source" validate:"required,max=20" example:"moneylover"`
+	SourceCategory string `json:"source_category" validate:"required,max=100" example:"Food & Beverage"`
+	CategoryID     string `json:"category_id" validate:"required,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+}
+
+type CategoryMappingParams struct {
+	// Kosong untuk semua sumber
+	Source string `query:"source"`
+}
+
+type DeleteCategoryMappingParams struct {
+	Source         string `query:"source" validate:"required"`
+	SourceCategory string `query:"source_category" validate:"required"`
+}
+
+type CreateReconciliationParams struct {
+	// Format rekening koran, salah satu format import (misalnya ofx atau moneylover)
+	Format    string `query:"format" validate:"required" example:"ofx"`
+	Account   string `query:"account" validate:"required,max=100" example:"BCA 1234"`
+	StartDate string `query:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `query:"end_date" validate:"required,datetime=2006-01-02"`
+}
+
+// ReconciliationMatch adalah pasangan baris rekening koran dan transaksi. Pasangan yang belum
+// dikonfirmasi adalah saran; kirim ke endpoint matches untuk mengonfirmasinya.
+type ReconciliationMatch struct {
+	Line        *entity.StatementLine `json:"line"`
+	Transaction *entity.Transaction   `json:"transaction"`
+	// 1 untuk pasangan dengan external ID yang sama, selain itu dari selisih tanggal dan kemiripan note
+	Score     float64 `json:"score"`
+	Confirmed bool    `json:"confirmed"`
+}
+
+type ReconciliationReport struct {
+	Reconciliation     *entity.Reconciliation `json:"reconciliation"`
+	Matched            []ReconciliationMatch  `json:"matched"`
+	UnmatchedStatement []*entity.StatementLine `json:"unmatched_statement"`
+	// Transaksi di periode yang tidak punya pasangan di rekening koran
+	UnmatchedLedger []*entity.Transaction `json:"unmatched_ledger"`
+	// Total bersih (pemasukan dikurangi pengeluaran) rekening koran dan transaksi di periode
+	StatementNet float64 `json:"statement_net"`
+	LedgerNet    float64 `json:"ledger_net"`
+	// Hanya diisi saat rekening koran diunggah: baris di luar periode dan baris yang tidak terbaca
+	OutsidePeriod int                 `json:"outside_period,omitempty"`
+	Errors        []importer.RowError `json:"errors,omitempty"`
+}
+
+type StatementMatch struct {
+	LineID        string `json:"line_id" validate:"required,uuid"`
+	TransactionID string `json:"transaction_id" validate:"required,uuid"`
+}
+
+type ConfirmMatchesRequest struct {
+	Matches []StatementMatch `json:"matches" validate:"required,min=1,max=500,dive"`
+}
+
+type CreateFromStatementRequest struct {
+	// Kosong berarti semua baris yang tidak punya pasangan maupun saran pasangan
+	LineIDs []string `json:"line_ids,omitempty" validate:"omitempty,max=500,dive,uuid"`
+	// Dipakai untuk baris yang kategorinya tidak bisa ditentukan dari mapping maupun rule
+	CategoryID string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+}
+
+type QuickAddRequest struct {
+	Text string `json:"text" validate:"required,max=200" example:"makan siang 35rb kemarin"`
+	// Tanggal hari ini di perangkat user, acuan tanggal relatif seperti "kemarin". Default tanggal server.
+	Today string `json:"today,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	// Kategori pilihan user, menggantikan kategori hasil tebakan
+	CategoryID string `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	// false hanya mengembalikan draft untuk dikonfirmasi, true langsung menyimpan transaksi
+	Create           bool `json:"create,omitempty"`
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+}
+
+type QuickAddResponse struct {
+	// Bisa dikirim apa adanya ke POST /transactions setelah dikonfirmasi user
+	Draft        CreateTransactionRequest `json:"draft"`
+	CategoryName string                   `json:"category_name,omitempty"`
+	// request, rule, category_name atau model; kosong jika kategori tidak ditemukan
+	CategorySource string `json:"category_source,omitempty"`
+	// Confidence model saran kategori, hanya untuk category_source model
+	Confidence float64 `json:"confidence,omitempty"`
+	// Field draft yang tidak terbaca dari teks dan harus dilengkapi user (amount, category_id)
+	Missing []string `json:"missing"`
+	// Hanya diisi jika create true
+	Transaction *entity.Transaction `json:"transaction,omitempty"`
+}
+
+type ReceiptDraftParams struct {
+	// Default pending
+	Status string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+}
+
+// ApproveReceiptRequest mengoreksi isi draft sebelum disimpan; field kosong memakai nilai draft.
+type ApproveReceiptRequest struct {
+	TransactionType string   `json:"transaction_type,omitempty" validate:"omitempty,oneof=income expense"`
+	Amount          float64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	CategoryID      string   `json:"category_id,omitempty" validate:"omitempty,ulid" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Note            *string  `json:"note,omitempty"`
+	Date            string   `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Tags            []string `json:"tags,omitempty" validate:"omitempty,dive,max=50"`
+	// Wajib true untuk tetap menyimpan transaksi yang terdeteksi mirip duplikat
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+}
+
+type ReceiptInboxResponse struct {
+	// Kosong jika listener SMTP tidak aktif
+	Address string `json:"address,omitempty" example:"0f8fad5bd9cb469fa16570867728950e.3a1c9b2e7f4d6a80@struk.example.com"`
+	Enabled bool   `json:"enabled"`
+	// Pengirim yang punya template khusus; pengirim lain dibaca dengan template generic
+	Templates []ReceiptTemplate `json:"templates"`
+}
+
+type ReceiptTemplate struct {
+	Name     string   `json:"name"`
+	Merchant string   `json:"merchant"`
+	Domains  []string `json:"domains"`
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID            uuid.UUID `json:"id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	// Key berbasis hash isi file, bisa dipakai bersama oleh beberapa lampiran
+	StorageKey string `json:"-"`
+	// Versi kecil dalam JPEG, kosong untuk file non-gambar atau yang belum diproses
+	ThumbnailKey string     `json:"-"`
+	MediumKey    string     `json:"-"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+const (
+	SizeThumb    = "thumb"
+	SizeMedium   = "medium"
+	SizeOriginal = "original"
+)
+
+// Variant mengembalikan lampiran untuk ukuran yang diminta. Jika versi kecil belum
+// tersedia, lampiran aslinya yang dikembalikan.
+func (a *Attachment) Variant(size string) *Attachment {
+	key := ""
+	switch size {
+	case SizeThumb:
+		key = a.ThumbnailKey
+	case SizeMedium:
+		key = a.MediumKey
+	}
+	if key == "" {
+		return a
+	}
+
+	variant := *a
+	variant.StorageKey = key
+	variant.ContentType = "image/jpeg"
+	variant.Filename = strings.TrimSuffix(a.Filename, filepath.Ext(a.Filename)) + "_" + size + ".jpg"
+	return &variant
+}
@@ -0,0 +1,15 @@
+package entity
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchWrite adalah satu operasi batch yang sudah divalidasi dan siap dieksekusi.
+// Untuk update dan delete, Transaction.Version berisi versi yang diharapkan di database.
+type BatchWrite struct {
+	Op          string
+	Transaction *Transaction
+	Revision    *Revision
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// CategoryMapping memetakan nama kategori di aplikasi sumber import ke kategori kita.
+type CategoryMapping struct {
+	// Nama format import, misalnya moneylover atau qif
+	Source string `json:"source" example:"moneylover"`
+	// Nama kategori di sumber dalam bentuk ternormalisasi (huruf kecil)
+	SourceCategory string    `json:"source_category" example:"food & beverage"`
+	CategoryID     string    `json:"category_id" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	CategoryName   string    `json:"category_name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
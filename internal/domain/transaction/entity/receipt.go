@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReceiptDraft adalah transaksi hasil pembacaan email struk yang menunggu ditinjau user.
+// Amount nil jika total tidak ditemukan di email.
+type ReceiptDraft struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// upload atau smtp
+	Source   string `json:"source"`
+	Template string `json:"template"`
+	// Message-ID email atau hash isinya
+	MessageKey      string   `json:"-"`
+	Sender          string   `json:"sender"`
+	Merchant        string   `json:"merchant"`
+	TransactionType string   `json:"transaction_type"`
+	Amount          *float64 `json:"amount"`
+	Date            string   `json:"date"`
+	CategoryID      string   `json:"category_id,omitempty" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	Note            string   `json:"note"`
+	// pending, approved atau rejected
+	Status        string         `json:"status"`
+	TransactionID *uuid.UUID     `json:"transaction_id,omitempty"`
+	Files         []*ReceiptFile `json:"files"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ReceiptFile adalah email asli (kind email) atau lampirannya (kind attachment) milik draft
+// yang belum disetujui.
+type ReceiptFile struct {
+	ID          uuid.UUID `json:"id"`
+	DraftID     uuid.UUID `json:"-"`
+	Kind        string    `json:"kind"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reconciliation adalah satu rekening koran yang dicocokkan dengan transaksi untuk periode
+// StartDate sampai EndDate. Selama LockedAt terisi, transaksi di periode tersebut tidak bisa
+// diubah nominal, tipe dan tanggalnya, dibuat maupun dihapus.
+type Reconciliation struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// Label rekening dari user, misalnya "BCA 1234"
+	Account   string     `json:"account"`
+	Format    string     `json:"format"`
+	StartDate string     `json:"start_date"`
+	EndDate   string     `json:"end_date"`
+	LockedAt  *time.Time `json:"locked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// StatementLine adalah satu transaksi di rekening koran.
+type StatementLine struct {
+	ID               uuid.UUID `json:"id"`
+	ReconciliationID uuid.UUID `json:"-"`
+	// Nomor baris atau urutan transaksi di file
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id"`
+	Date       string `json:"date"`
+	// Positif untuk pemasukan, negatif untuk pengeluaran
+	Amount   float64 `json:"amount"`
+	Note     string  `json:"note"`
+	Category string  `json:"category,omitempty"`
+	// Transaksi yang sudah dikonfirmasi sebagai pasangan baris ini
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot adalah isi transaksi yang bisa diubah user, disimpan sebagai JSONB di setiap revisi.
+type Snapshot struct {
+	TransactionType string   `json:"transaction_type"`
+	Amount          float64  `json:"amount"`
+	CategoryID      string   `json:"category_id"`
+	Period          string   `json:"period"`
+	Note            string   `json:"note"`
+	Date            string   `json:"date"`
+	Tags            []string `json:"tags"`
+}
+
+// Revision menyimpan kondisi transaksi sebelum perubahan ke-Version dilakukan oleh ChangedBy.
+type Revision struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Version       int        `json:"version"`
+	ChangedBy     *uuid.UUID `json:"changed_by,omitempty"`
+	Snapshot      Snapshot   `json:"snapshot"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (t *Transaction) Snapshot() Snapshot {
+	date := t.Date
+	if len(date) > len("2006-01-02") {
+		date = date[:len("2006-01-02")]
+	}
+
+	return Snapshot{
+		TransactionType: t.TransactionType,
+		Amount:          t.Amount,
+		CategoryID:      t.CategoryID,
+		Period:          t.Period,
+		Note:            t.Note,
+		Date:            date,
+		Tags:            append([]string{}, t.Tags...),
+	}
+}
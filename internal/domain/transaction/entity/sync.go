@@ -0,0 +1,37 @@
+package entity
+
+import (
+	categoryEntity "github.com/kenziehh/cashflow-be/internal/domain/category/entity"
+	maximumSpendEntity "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/entity"
+)
+
+const (
+	SyncTransaction = "transaction"
+	SyncCategory    = "category"
+	SyncBudget      = "budget"
+)
+
+// SyncCursor adalah posisi sync: xid transaksi database yang menulis perubahan, lalu nomor
+// urutnya. Perubahan dari sebelum migrasi 026 memiliki Xid 0.
+type SyncCursor struct {
+	Xid int64
+	Seq int64
+}
+
+// After melaporkan apakah c berada setelah other.
+func (c SyncCursor) After(other SyncCursor) bool {
+	return c.Xid > other.Xid || (c.Xid == other.Xid && c.Seq > other.Seq)
+}
+
+// Change adalah satu baris yang berubah setelah posisi sync tertentu. Untuk baris
+// yang dihapus hanya EntityID yang terisi.
+type Change struct {
+	Cursor      SyncCursor
+	Entity      string
+	EntityID    string
+	Created     bool
+	Deleted     bool
+	Transaction *Transaction
+	Category    *categoryEntity.Category
+	Budget      *maximumSpendEntity.MaximumSpend
+}
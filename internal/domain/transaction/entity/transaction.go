@@ -6,15 +6,23 @@ import (
 )
 
 type Transaction struct {
-	ID              uuid.UUID `json:"id"`
-	UserID          uuid.UUID `json:"user_id"`
-	CategoryID      string    `json:"category_id" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
-	TransactionType string    `json:"transaction_type"` // e.g., "income" or "expense"
-	Amount          float64   `json:"amount"`
-	Period          string    `json:"period"`
-	Note            string    `json:"note"`
-	Date            string    `json:"date"`
-	ProofFile       string   `json:"proof_file,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	CategoryID      string     `json:"category_id" swaggertype:"string" example:"01ARZ3NDEKTSV4RRFFQ69G5FAV"`
+	TransactionType string     `json:"transaction_type"` // e.g., "income" or "expense"
+	Amount          float64    `json:"amount"`
+	Period          string     `json:"period"`
+	Note            string     `json:"note"`
+	Date            string     `json:"date"`
+	Tags            []string   `json:"tags"`
+	DebtID          *uuid.UUID `json:"debt_id,omitempty"`
+	// ID transaksi di file sumber untuk transaksi hasil import
+	ExternalID      string     `json:"external_id,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	// Naik setiap kali transaksi diubah, dipakai sebagai ETag
+	Version         int        `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	// Hanya diisi pada response create, gunakan endpoint attachments untuk daftar lengkap
+	Attachments     []*Attachment `json:"attachments,omitempty"`
 }
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// versionETag membentuk ETag dari kolom version transaksi.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch membaca versi dari header If-Match. Header wajib ada untuk PUT/DELETE;
+// nilai yang tidak dikenali diperlakukan sebagai versi yang tidak cocok.
+func parseIfMatch(c *fiber.Ctx) (int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, errx.ErrIfMatchRequired
+	}
+
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errx.ErrVersionMismatch
+	}
+
+	return version, nil
+}
+
+// notModified mengecek header If-None-Match terhadap ETag saat ini.
+func notModified(c *fiber.Ctx, etag string) bool {
+	for _, candidate := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
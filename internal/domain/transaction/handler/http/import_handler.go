@@ -0,0 +1,165 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+// ImportTransactions godoc
+// @Summary Import transactions from another app or bank
+// @Description Import an OFX/QFX or QIF statement, or a CSV export from Money Lover, Wallet or Spendee. Source categories are matched through the user's import category mappings, then by name, then by rules, then default_category_id; rows whose category cannot be determined are skipped and listed in unmapped_categories. Every row gets an external ID (the OFX FITID, or a hash of the row) so importing the same file again never duplicates transactions. Amounts are rounded to cents; rows that round to zero or reach 10,000,000,000 are reported in errors.
+// @Tags transactions
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to import"
+// @Param format query string true "ofx, qfx, qif, moneylover, wallet or spendee"
+// @Param default_category_id query string false "Category for rows without a matching category"
+// @Param dry_run query bool false "Only report what would be imported"
+// @Success 200 {object} response.Response{data=dto.ImportResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/import [post]
+func (h *TransactionHandler) ImportTransactions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.ImportParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return errx.NewBadRequestError("file is required")
+	}
+
+	upload, err := readUpload(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.ImportTransactions(c.Context(), userID, upload.Data, params)
+	if err != nil {
+		return err
+	}
+
+	message := "Transactions imported successfully"
+	if result.DryRun {
+		message = "Import checked successfully, nothing was saved"
+	}
+	return c.JSON(response.SuccessResponse(message, result))
+}
+
+// GetCategoryMappings godoc
+// @Summary List import category mappings
+// @Description List how categories from other apps are mapped onto categories when importing
+// @Tags transactions
+// @Produce json
+// @Param source query string false "Only mappings for this import format"
+// @Success 200 {object} response.Response{data=[]entity.CategoryMapping}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/import/mappings [get]
+func (h *TransactionHandler) GetCategoryMappings(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.CategoryMappingParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	result, err := h.service.GetCategoryMappings(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Category mappings retrieved successfully", result))
+}
+
+// SaveCategoryMapping godoc
+// @Summary Map an imported category
+// @Description Create or replace the mapping of a category name from an import format onto a category. Source category names are matched case-insensitively.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body dto.CategoryMappingRequest true "Category mapping"
+// @Success 200 {object} response.Response{data=entity.CategoryMapping}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/import/mappings [put]
+func (h *TransactionHandler) SaveCategoryMapping(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.CategoryMappingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.SaveCategoryMapping(c.Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Category mapping saved successfully", result))
+}
+
+// DeleteCategoryMapping godoc
+// @Summary Remove an import category mapping
+// @Tags transactions
+// @Produce json
+// @Param source query string true "Import format"
+// @Param source_category query string true "Category name in the source app"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/import/mappings [delete]
+func (h *TransactionHandler) DeleteCategoryMapping(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.DeleteCategoryMappingParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	if err := h.service.DeleteCategoryMapping(c.Context(), userID, params); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Category mapping deleted successfully", nil))
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+// QuickAdd godoc
+// @Summary Quick-add a transaction from a sentence
+// @Description Parse a short sentence such as "makan siang 35rb kemarin" or "gaji 8jt 25 okt" into a draft transaction. Amount shorthands (rb, ribu, k, jt, juta), relative dates (hari ini, kemarin, 3 hari lalu, senin, yesterday, last friday), dates such as "25 okt" or "25/10" and income keywords (gaji, bonus, refund, ...) are understood; a leading + or - sets the type. The category comes from category_id, rules, a category name in the sentence or the category suggestion model. With create=false the draft is returned for confirmation and can be sent as-is to POST /transactions; with create=true it is saved right away.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body dto.QuickAddRequest true "Quick-add request"
+// @Success 200 {object} response.Response{data=dto.QuickAddResponse}
+// @Success 201 {object} response.Response{data=dto.QuickAddResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response{data=[]dto.DuplicateCandidate}
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/quick [post]
+func (h *TransactionHandler) QuickAdd(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.QuickAddRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.QuickAdd(c.Context(), userID, req)
+	if err != nil {
+		var dupErr *service.DuplicateWarningError
+		if errors.As(err, &dupErr) {
+			return c.Status(fiber.StatusConflict).JSON(response.Response{
+				Success: false,
+				Message: dupErr.Error(),
+				Data:    dupErr.Candidates,
+			})
+		}
+		return err
+	}
+
+	if result.Transaction != nil {
+		return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Transaction created successfully", result))
+	}
+	return c.JSON(response.SuccessResponse("Transaction draft parsed successfully", result))
+}
@@ -0,0 +1,276 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/service"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+// UploadReceiptEmail godoc
+// @Summary Upload a receipt email
+// @Description Upload a raw .eml (MIME) receipt, either as the multipart field "file" or as a message/rfc822 request body. Merchant, total, date and attachments are read with the sender's template (Tokopedia, Shopee, Gojek, Grab, Traveloka, Bukalapak, Lazada, Blibli) or a generic one, and a draft is added to the review queue. Emails forwarded inline or as an attachment are read from the original sender. The same email is only accepted once.
+// @Tags receipts
+// @Accept multipart/form-data
+// @Accept message/rfc822
+// @Produce json
+// @Param file formData file false "Email file (.eml)"
+// @Success 201 {object} response.Response{data=entity.ReceiptDraft}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/email [post]
+func (h *TransactionHandler) UploadReceiptEmail(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var data []byte
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return errx.NewBadRequestError("file is required")
+		}
+
+		upload, err := readUpload(file)
+		if err != nil {
+			return err
+		}
+		data = upload.Data
+	} else {
+		data = c.Body()
+	}
+
+	result, err := h.service.IngestEmail(c.Context(), userID, "upload", data)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Receipt email received successfully", result))
+}
+
+// GetReceiptDrafts godoc
+// @Summary List receipt drafts
+// @Description List drafts read from receipt emails, newest first. Only pending drafts still have their email files; approved drafts link to the created transaction.
+// @Tags receipts
+// @Produce json
+// @Param status query string false "pending (default), approved or rejected"
+// @Success 200 {object} response.Response{data=[]entity.ReceiptDraft}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts [get]
+func (h *TransactionHandler) GetReceiptDrafts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.ReceiptDraftParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.GetReceiptDrafts(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Receipt drafts retrieved successfully", result))
+}
+
+// GetReceiptDraft godoc
+// @Summary Get a receipt draft
+// @Tags receipts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} response.Response{data=entity.ReceiptDraft}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/{id} [get]
+func (h *TransactionHandler) GetReceiptDraft(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid draft ID format")
+	}
+
+	result, err := h.service.GetReceiptDraft(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Receipt draft retrieved successfully", result))
+}
+
+// DownloadReceiptFile godoc
+// @Summary Download a receipt draft file
+// @Description Download the original email or one of its attachments while the draft is pending. After approval the files are attachments of the transaction.
+// @Tags receipts
+// @Produce octet-stream
+// @Param id path string true "Draft ID"
+// @Param fileId path string true "File ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/{id}/files/{fileId} [get]
+func (h *TransactionHandler) DownloadReceiptFile(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid draft ID format")
+	}
+
+	fileID, err := uuid.Parse(c.Params("fileId"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid file ID format")
+	}
+
+	file, content, err := h.service.OpenReceiptFile(c.Context(), userID, id, fileID)
+	if err != nil {
+		return err
+	}
+
+	// Email asli selalu diunduh, bukan ditampilkan, karena bisa berisi HTML dari pengirim
+	disposition := "inline"
+	if file.Kind == "email" {
+		disposition = "attachment"
+	}
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("%s; filename=%q", disposition, file.Filename))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(content)
+}
+
+// ApproveReceiptDraft godoc
+// @Summary Approve a receipt draft
+// @Description Save the draft as a transaction, optionally correcting its fields first. The original email and its attachments become attachments of the transaction. amount is required when no total was found, and category_id when neither the draft nor a rule has one.
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param request body dto.ApproveReceiptRequest false "Corrections to the draft"
+// @Success 201 {object} response.Response{data=entity.Transaction}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response{data=[]dto.DuplicateCandidate}
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/{id}/approve [post]
+func (h *TransactionHandler) ApproveReceiptDraft(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid draft ID format")
+	}
+
+	var req dto.ApproveReceiptRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errx.NewBadRequestError("Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.ApproveReceiptDraft(c.Context(), userID, id, req)
+	if err != nil {
+		var dupErr *service.DuplicateWarningError
+		if errors.As(err, &dupErr) {
+			return c.Status(fiber.StatusConflict).JSON(response.Response{
+				Success: false,
+				Message: dupErr.Error(),
+				Data:    dupErr.Candidates,
+			})
+		}
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Transaction created successfully", result))
+}
+
+// RejectReceiptDraft godoc
+// @Summary Reject a receipt draft
+// @Description Remove the draft from the review queue and delete its email files. The email is still remembered, so sending it again is rejected.
+// @Tags receipts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/{id} [delete]
+func (h *TransactionHandler) RejectReceiptDraft(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid draft ID format")
+	}
+
+	if err := h.service.RejectReceiptDraft(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Receipt draft rejected successfully", nil))
+}
+
+// GetReceiptInbox godoc
+// @Summary Get the receipt inbox address
+// @Description Return the personal address that receipts can be forwarded to when the SMTP listener is enabled, along with the senders that have a parsing template.
+// @Tags receipts
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.ReceiptInboxResponse}
+// @Failure 401 {object} response.Response
+// @Security BearerAuth
+// @Router /receipts/inbox [get]
+func (h *TransactionHandler) GetReceiptInbox(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	return c.JSON(response.SuccessResponse("Receipt inbox retrieved successfully", h.service.GetReceiptInbox(c.Context(), userID)))
+}
@@ -0,0 +1,338 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/response"
+)
+
+// CreateReconciliation godoc
+// @Summary Upload a bank statement for reconciliation
+// @Description Upload a statement for one period in any import format. Statement lines outside the period are ignored. The response lists confirmed matches, suggested matches (same amount and type, dated within 3 days), statement lines without a transaction and transactions in the period without a statement line.
+// @Tags reconciliations
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Statement file"
+// @Param format query string true "ofx, qfx, qif, moneylover, wallet or spendee"
+// @Param account query string true "Label of the statement's account"
+// @Param start_date query string true "First day of the statement period (YYYY-MM-DD)"
+// @Param end_date query string true "Last day of the statement period (YYYY-MM-DD)"
+// @Success 201 {object} response.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations [post]
+func (h *TransactionHandler) CreateReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.CreateReconciliationParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return errx.NewBadRequestError("file is required")
+	}
+
+	upload, err := readUpload(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.CreateReconciliation(c.Context(), userID, upload.Data, params)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Statement uploaded successfully", result))
+}
+
+// GetReconciliations godoc
+// @Summary List reconciliations
+// @Tags reconciliations
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entity.Reconciliation}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations [get]
+func (h *TransactionHandler) GetReconciliations(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetReconciliations(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reconciliations retrieved successfully", result))
+}
+
+// GetReconciliation godoc
+// @Summary Get a reconciliation report
+// @Description Suggested matches are recomputed from the current transactions on every request
+// @Tags reconciliations
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Success 200 {object} response.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id} [get]
+func (h *TransactionHandler) GetReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	result, err := h.service.GetReconciliation(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reconciliation retrieved successfully", result))
+}
+
+// DeleteReconciliation godoc
+// @Summary Delete a reconciliation
+// @Description Removes the statement and its matches; transactions are kept. A locked reconciliation must be unlocked first.
+// @Tags reconciliations
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id} [delete]
+func (h *TransactionHandler) DeleteReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	if err := h.service.DeleteReconciliation(c.Context(), userID, id); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reconciliation deleted successfully", nil))
+}
+
+// ConfirmStatementMatches godoc
+// @Summary Confirm statement matches
+// @Description Pair statement lines with transactions, either accepting suggested matches or choosing other transactions. The amount and type of both sides must be equal; an earlier match of the same line is replaced.
+// @Tags reconciliations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Param request body dto.ConfirmMatchesRequest true "Matches to confirm"
+// @Success 200 {object} response.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id}/matches [post]
+func (h *TransactionHandler) ConfirmStatementMatches(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	var req dto.ConfirmMatchesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.ConfirmStatementMatches(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Matches confirmed successfully", result))
+}
+
+// UnmatchStatementLine godoc
+// @Summary Remove a confirmed match
+// @Tags reconciliations
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Param lineId path string true "Statement line ID"
+// @Success 200 {object} response.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id}/matches/{lineId} [delete]
+func (h *TransactionHandler) UnmatchStatementLine(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	lineID, err := uuid.Parse(c.Params("lineId"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid statement line ID format")
+	}
+
+	result, err := h.service.UnmatchStatementLine(c.Context(), userID, id, lineID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Match removed successfully", result))
+}
+
+// CreateFromStatement godoc
+// @Summary Create transactions for unmatched statement lines
+// @Description Create a transaction for each chosen statement line and match them right away. Without line_ids every line without a match or suggested match is used. Categories are determined like an import, with category_id for lines no mapping or rule matches.
+// @Tags reconciliations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Param request body dto.CreateFromStatementRequest false "Lines to create transactions for"
+// @Success 200 {object} response.Response{data=dto.ReconciliationReport}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id}/transactions [post]
+func (h *TransactionHandler) CreateFromStatement(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	var req dto.CreateFromStatementRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errx.NewBadRequestError("Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateFromStatement(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Transactions created successfully", result))
+}
+
+// LockReconciliation godoc
+// @Summary Lock a reconciled period
+// @Description Only possible once every statement line has a confirmed match. While locked, transactions dated in the period cannot be created, deleted, restored or have their amount, type or date changed.
+// @Tags reconciliations
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Success 200 {object} response.Response{data=entity.Reconciliation}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id}/lock [post]
+func (h *TransactionHandler) LockReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	result, err := h.service.LockReconciliation(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reconciliation locked successfully", result))
+}
+
+// UnlockReconciliation godoc
+// @Summary Unlock a reconciled period
+// @Tags reconciliations
+// @Produce json
+// @Param id path string true "Reconciliation ID"
+// @Success 200 {object} response.Response{data=entity.Reconciliation}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /reconciliations/{id}/lock [delete]
+func (h *TransactionHandler) UnlockReconciliation(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid reconciliation ID format")
+	}
+
+	result, err := h.service.UnlockReconciliation(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Reconciliation unlocked successfully", result))
+}
@@ -1,16 +1,18 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"mime/multipart"
 	"strings"
-	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/service"
 	"github.com/kenziehh/cashflow-be/pkg/errx"
 	"github.com/kenziehh/cashflow-be/pkg/response"
@@ -38,6 +40,9 @@ func NewTransactionHandler(service service.TransactionService) *TransactionHandl
 // @Success 201 {object} response.Response{data=entity.Transaction}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response{data=[]dto.DuplicateCandidate}
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
 // @Router /transactions [post]
@@ -74,27 +79,26 @@ func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 	}
 
 	// === File Upload (optional) ===
-	var proofPath string
+	var upload *dto.AttachmentUpload
 	file, err := c.FormFile("proofFile")
 	if err == nil && file != nil {
-		uploadDir := "./uploads/proofs"
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			return errx.NewInternalServerError("Failed to create upload directory")
+		upload, err = readUpload(file)
+		if err != nil {
+			return err
 		}
-
-		filename := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-		filePath := filepath.Join(uploadDir, filename)
-
-		if err := c.SaveFile(file, filePath); err != nil {
-			return errx.NewInternalServerError("Failed to save file")
-		}
-
-		proofPath = filePath
 	}
 
 	// Panggil service
-	result, err := h.service.CreateTransaction(c.Context(), req, userID, proofPath)
+	result, err := h.service.CreateTransaction(c.Context(), req, userID, upload)
 	if err != nil {
+		var dupErr *service.DuplicateWarningError
+		if errors.As(err, &dupErr) {
+			return c.Status(fiber.StatusConflict).JSON(response.Response{
+				Success: false,
+				Message: dupErr.Error(),
+				Data:    dupErr.Candidates,
+			})
+		}
 		return err
 	}
 
@@ -108,7 +112,9 @@ func (h *TransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Transaction ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response{data=entity.Transaction}
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
@@ -141,22 +147,32 @@ func (h *TransactionHandler) GetTransactionByID(c *fiber.Ctx) error {
 		return errx.NewUnauthorizedError("You do not have access to this transaction")
 	}
 
+	etag := versionETag(result.Version)
+	c.Set(fiber.HeaderETag, etag)
+	if notModified(c, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 
 	return c.JSON(response.SuccessResponse("Transaction retrieved successfully", result))
 }
 
 // UpdateTransaction godoc
 // @Summary Update a transaction
-// @Description Update a transaction by its ID for the authenticated user
+// @Description Update a transaction by its ID for the authenticated user. Amount, type and date of a debt repayment cannot be changed here
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param id path string true "Transaction ID"
+// @Param If-Match header string true "ETag of the transaction being edited"
 // @Param request body dto.UpdateTransactionRequest true "Update transaction request"
 // @Success 200 {object} response.Response{data=entity.Transaction}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
 // @Router /transactions/{id} [put]
@@ -176,6 +192,11 @@ func (h *TransactionHandler) UpdateTransaction(c *fiber.Ctx) error {
 		return errx.NewBadRequestError("Invalid transaction ID format")
 	}
 
+	version, err := parseIfMatch(c)
+	if err != nil {
+		return err
+	}
+
 	// Parse form-data (karena kita pakai file upload)
 	var req dto.UpdateTransactionRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -195,58 +216,101 @@ func (h *TransactionHandler) UpdateTransaction(c *fiber.Ctx) error {
 		return errx.NewUnauthorizedError("You do not have access to this transaction")
 	}
 
+	// Cek versi sebelum menyimpan lampiran supaya upload tidak sia-sia
+	if existingTx.Version != version {
+		return errx.ErrVersionMismatch
+	}
+
 	// === File Upload Handling ===
+	// File baru ditambahkan sebagai lampiran, lampiran lama tetap ada
 	file, err := c.FormFile("proofFile")
-	var proofPath string
-
 	if err == nil && file != nil {
-		uploadDir := "./uploads/proofs"
-		if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-			return errx.NewInternalServerError("Failed to create upload directory")
+		upload, err := readUpload(file)
+		if err != nil {
+			return err
+		}
+		if _, err := h.service.AddAttachment(c.Context(), userID, id, *upload); err != nil {
+			return err
 		}
+	}
 
-		filename := fmt.Sprintf("%d_%s", time.Now().Unix(), file.Filename)
-		filePath := filepath.Join(uploadDir, filename)
+	// Update transaction di service
+	result, err := h.service.UpdateTransaction(c.Context(), id, req, userID, version)
+	if err != nil {
+		return err
+	}
 
-		// Simpan file baru
-		if err := c.SaveFile(file, filePath); err != nil {
-			return errx.NewInternalServerError("Failed to save proof file")
-		}
+	c.Set(fiber.HeaderETag, versionETag(result.Version))
+	return c.JSON(response.SuccessResponse("Transaction updated successfully", result))
+}
 
-		proofPath = filePath
+// PatchTransaction godoc
+// @Summary Partially update a transaction
+// @Description Apply a JSON Merge Patch (RFC 7396). Omitted fields are unchanged and null clears category_id, note or tags. Amount, type and date of a debt repayment cannot be changed.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param If-Match header string true "ETag of the transaction being edited"
+// @Param request body dto.PatchTransactionRequest true "Merge patch document"
+// @Success 200 {object} response.Response{data=entity.Transaction}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id} [patch]
+func (h *TransactionHandler) PatchTransaction(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
 
-		// Hapus file lama (jika ada)
-		if existingTx.ProofFile != "" {
-			oldFile := filepath.Join("uploads", "proofs", filepath.Base(existingTx.ProofFile))
-			if _, err := os.Stat(oldFile); err == nil {
-				os.Remove(oldFile)
-			}
-		}
-	} else {
-		// Jika tidak ada file baru, tetap pakai yang lama
-		proofPath = existingTx.ProofFile
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
 	}
 
-	// Update transaction di service
-	result, err := h.service.UpdateTransaction(c.Context(), id, req, proofPath)
+	version, err := parseIfMatch(c)
 	if err != nil {
 		return err
 	}
 
+	// Body dibaca langsung karena BodyParser tidak mengenal application/merge-patch+json
+	var req dto.PatchTransactionRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return errx.NewBadRequestError("Invalid request body: " + err.Error())
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.PatchTransaction(c.Context(), id, req, userID, version)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, versionETag(result.Version))
 	return c.JSON(response.SuccessResponse("Transaction updated successfully", result))
 }
 
 // DeleteTransaction godoc
 // @Summary Delete a transaction
-// @Description Delete a transaction by its ID for the authenticated user
+// @Description Move a transaction to the trash. It can be restored until the retention period ends.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param id path string true "Transaction ID"
+// @Param If-Match header string true "ETag of the transaction being deleted"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Security BearerAuth
 // @Router /transactions/{id} [delete]
@@ -266,6 +330,11 @@ func (h *TransactionHandler) DeleteTransaction(c *fiber.Ctx) error {
 		return errx.NewBadRequestError("Invalid transaction ID format")
 	}
 
+	version, err := parseIfMatch(c)
+	if err != nil {
+		return err
+	}
+
 	existingTx, err := h.service.GetTransactionByID(c.Context(), id)
 	if err != nil {
 		return err
@@ -275,7 +344,7 @@ func (h *TransactionHandler) DeleteTransaction(c *fiber.Ctx) error {
 		return errx.NewUnauthorizedError("You do not have access to this transaction")
 	}
 
-	if err := h.service.DeleteTransaction(c.Context(), id); err != nil {
+	if err := h.service.DeleteTransaction(c.Context(), id, version); err != nil {
 		return err
 	}
 
@@ -292,7 +361,9 @@ func (h *TransactionHandler) DeleteTransaction(c *fiber.Ctx) error {
 // @Param page_size query int false "Number of items per page" default(10)
 // @Param sort_by query string false "Field to sort by" Enums(date, amount, created_at) default(date)
 // @Param order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response{data=dto.PaginatedTransactionsResponse}
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -334,41 +405,287 @@ func (h *TransactionHandler) GetTransactionsWithPagination(c *fiber.Ctx) error {
 	return c.JSON(response.SuccessResponse("Transactions retrieved successfully", result))
 }
 
-func (h *TransactionHandler) GetProofFile(c *fiber.Ctx) error {
+// GetAttachments godoc
+// @Summary List transaction attachments
+// @Description List the files attached to a transaction
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} response.Response{data=[]entity.Attachment}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments [get]
+func (h *TransactionHandler) GetAttachments(c *fiber.Ctx) error {
 	userID, ok := c.Locals("userID").(uuid.UUID)
 	if !ok {
 		return errx.NewUnauthorizedError("Invalid user ID")
 	}
 
-	idParam := c.Params("id")
-	id, err := uuid.Parse(idParam)
+	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return errx.NewBadRequestError("Invalid transaction ID")
+		return errx.NewBadRequestError("Invalid transaction ID format")
 	}
 
-	tx, err := h.service.GetTransactionByID(c.Context(), id)
+	result, err := h.service.ListAttachments(c.Context(), userID, id)
 	if err != nil {
 		return err
 	}
-	if tx == nil {
-		return errx.NewNotFoundError("Transaction not found")
+
+	return c.JSON(response.SuccessResponse("Attachments retrieved successfully", result))
+}
+
+// AddAttachment godoc
+// @Summary Attach a file to a transaction
+// @Description Upload a JPEG, PNG, PDF or HEIC file. The type is detected from the file content. Location and other EXIF/XMP metadata are removed from photos before they are stored, and images whose metadata cannot be read are rejected. Uploads count towards the user's storage quota.
+// @Tags transactions
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} response.Response{data=entity.Attachment}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments [post]
+func (h *TransactionHandler) AddAttachment(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
 	}
 
-	if tx.UserID != userID {
-		return errx.NewUnauthorizedError("You do not have access to this transaction")
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
 	}
 
-	if tx.ProofFile == "" {
-		return errx.NewNotFoundError("No proof file")
+	file, err := c.FormFile("file")
+	if err != nil {
+		return errx.NewBadRequestError("file is required")
 	}
 
-	safePath := filepath.Join("uploads", "proofs", filepath.Base(tx.ProofFile))
+	upload, err := readUpload(file)
+	if err != nil {
+		return err
+	}
 
-	if _, err := os.Stat(safePath); os.IsNotExist(err) {
-		return errx.NewNotFoundError("File not found")
+	result, err := h.service.AddAttachment(c.Context(), userID, id, *upload)
+	if err != nil {
+		return err
 	}
 
-	return c.SendFile(safePath, true)
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Attachment added successfully", result))
+}
+
+// DownloadAttachment godoc
+// @Summary Download a transaction attachment
+// @Description Stream the content of an attachment. Photos also have JPEG thumb and medium sizes once processed; until then the original is returned.
+// @Tags transactions
+// @Produce octet-stream
+// @Param id path string true "Transaction ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param size query string false "thumb, medium or original (default)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments/{attachmentId} [get]
+func (h *TransactionHandler) DownloadAttachment(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, attachmentID, err := parseAttachmentParams(c)
+	if err != nil {
+		return err
+	}
+
+	var params dto.AttachmentDownloadParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	attachment, file, err := h.service.OpenAttachment(c.Context(), userID, id, attachmentID, params.Size)
+	if err != nil {
+		return err
+	}
+
+	return sendAttachment(c, attachment, file)
+}
+
+// RemoveAttachment godoc
+// @Summary Remove a transaction attachment
+// @Description Delete an attachment. The stored file is removed once no other attachment uses it.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments/{attachmentId} [delete]
+func (h *TransactionHandler) RemoveAttachment(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, attachmentID, err := parseAttachmentParams(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.RemoveAttachment(c.Context(), userID, id, attachmentID); err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Attachment removed successfully", nil))
+}
+
+// CreateAttachmentLink godoc
+// @Summary Create a signed download URL for an attachment
+// @Description Mint a time-limited URL that downloads the attachment without a bearer token. One-time links stop working after the first download.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param request body dto.CreateAttachmentLinkRequest false "Link options"
+// @Success 201 {object} response.Response{data=dto.AttachmentLinkResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments/{attachmentId}/link [post]
+func (h *TransactionHandler) CreateAttachmentLink(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, attachmentID, err := parseAttachmentParams(c)
+	if err != nil {
+		return err
+	}
+
+	// Body boleh kosong, semua opsi punya nilai default
+	var req dto.CreateAttachmentLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errx.NewBadRequestError("Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.CreateAttachmentLink(c.Context(), userID, id, attachmentID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.SuccessResponse("Attachment link created successfully", result))
+}
+
+// DownloadSignedAttachment godoc
+// @Summary Download an attachment through a signed URL
+// @Description Public download authenticated by the signature in the URL created by the attachment link endpoint
+// @Tags transactions
+// @Produce octet-stream
+// @Param attachmentId path string true "Attachment ID"
+// @Param expires query int true "Expiry as a Unix timestamp"
+// @Param nonce query string false "One-time link nonce"
+// @Param sig query string true "Signature"
+// @Param size query string false "thumb, medium or original (default)"
+// @Success 200 {file} file
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /attachments/{attachmentId} [get]
+func (h *TransactionHandler) DownloadSignedAttachment(c *fiber.Ctx) error {
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return errx.NewNotFoundError("Attachment not found")
+	}
+
+	var params dto.SignedAttachmentParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewForbiddenError("Invalid or expired link")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	attachment, file, err := h.service.OpenSignedAttachment(c.Context(), attachmentID, params)
+	if err != nil {
+		return err
+	}
+
+	// Link sekali pakai tidak boleh disimpan di cache browser maupun proxy
+	if params.Nonce != "" {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "private")
+	}
+	return sendAttachment(c, attachment, file)
+}
+
+func sendAttachment(c *fiber.Ctx, attachment *entity.Attachment, file io.ReadCloser) error {
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", attachment.Filename))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(file)
+}
+
+func parseAttachmentParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError("Invalid attachment ID format")
+	}
+
+	return id, attachmentID, nil
+}
+
+// readUpload membaca isi file multipart untuk diteruskan ke service.
+func readUpload(file *multipart.FileHeader) (*dto.AttachmentUpload, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, errx.NewBadRequestError("Failed to read uploaded file")
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errx.NewBadRequestError("Failed to read uploaded file")
+	}
+
+	return &dto.AttachmentUpload{Filename: file.Filename, Data: data}, nil
 }
 
 // GetSummaryTransaction godoc
@@ -377,7 +694,9 @@ func (h *TransactionHandler) GetProofFile(c *fiber.Ctx) error {
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} response.Response{data=dto.SummaryTransactionResponse}
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -396,3 +715,325 @@ func (h *TransactionHandler) GetSummaryTransaction(c *fiber.Ctx) error {
 
 	return c.JSON(response.SuccessResponse("Transaction summary retrieved successfully", result))
 }
+
+// FindDuplicates godoc
+// @Summary Find likely duplicate transactions
+// @Description List pairs of transactions with the same type and amount, close dates and similar notes
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param days query int false "Maximum days apart" default(3)
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} response.Response{data=[]dto.DuplicatePair}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/duplicates [get]
+func (h *TransactionHandler) FindDuplicates(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.DuplicateScanParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.FindDuplicates(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Duplicate transactions retrieved successfully", result))
+}
+
+// MergeTransactions godoc
+// @Summary Merge a duplicate transaction
+// @Description Keep this transaction, combine the duplicate's note, tags and proof file into it, take over its goal, bill and statement line links, then move the duplicate to trash. Both transactions must still have the versions the client saw, and debt repayments cannot be merged.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID to keep"
+// @Param If-Match header string true "ETag of the transaction being kept"
+// @Param request body dto.MergeTransactionsRequest true "Merge request"
+// @Success 200 {object} response.Response{data=entity.Transaction}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 412 {object} response.Response
+// @Failure 428 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/merge [post]
+func (h *TransactionHandler) MergeTransactions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	version, err := parseIfMatch(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.MergeTransactionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.MergeTransactions(c.Context(), userID, id, version, req)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, versionETag(result.Version))
+	return c.JSON(response.SuccessResponse("Transactions merged successfully", result))
+}
+
+// GetTrash godoc
+// @Summary Get trashed transactions
+// @Description Get deleted transactions that can still be restored, with the time each will be purged
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.TrashedTransaction}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/trash [get]
+func (h *TransactionHandler) GetTrash(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	result, err := h.service.GetTrash(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Trashed transactions retrieved successfully", result))
+}
+
+// RestoreTransaction godoc
+// @Summary Restore a trashed transaction
+// @Description Move a deleted transaction out of the trash. Debt repayments cannot be restored, record a new repayment on the debt instead
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} response.Response{data=entity.Transaction}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/trash/{id}/restore [post]
+func (h *TransactionHandler) RestoreTransaction(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	result, err := h.service.RestoreTransaction(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Transaction restored successfully", result))
+}
+
+// GetHistory godoc
+// @Summary Get transaction revision history
+// @Description List previous versions of a transaction with who changed it and which fields changed
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} response.Response{data=[]dto.RevisionDiff}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/history [get]
+func (h *TransactionHandler) GetHistory(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	result, err := h.service.GetHistory(c.Context(), userID, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Transaction history retrieved successfully", result))
+}
+
+// RevertTransaction godoc
+// @Summary Revert a transaction to a previous version
+// @Description Restore the state a transaction had before the given version. The revert is recorded as a new version. Reverts that would change the amount, type or date of a debt repayment are rejected.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body dto.RevertTransactionRequest true "Revert request"
+// @Success 200 {object} response.Response{data=entity.Transaction}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/{id}/revert [post]
+func (h *TransactionHandler) RevertTransaction(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	var req dto.RevertTransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.RevertTransaction(c.Context(), userID, id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Transaction reverted successfully", result))
+}
+
+// BatchTransactions godoc
+// @Summary Create, update and delete transactions in one request
+// @Description Run up to 100 operations atomically. Each operation is validated like its single endpoint; if any fails, nothing is saved and the per-operation results explain why.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body dto.BatchTransactionRequest true "Batch operations"
+// @Success 200 {object} response.Response{data=[]dto.BatchOperationResult}
+// @Failure 400 {object} response.Response{data=[]dto.BatchOperationResult}
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response{data=[]dto.BatchOperationResult}
+// @Failure 412 {object} response.Response{data=[]dto.BatchOperationResult}
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /transactions/batch [post]
+func (h *TransactionHandler) BatchTransactions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var req dto.BatchTransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errx.NewBadRequestError("Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	// Semua operasi divalidasi dulu supaya client mendapat seluruh error sekaligus
+	failures := map[int]error{}
+	for i, op := range req.Operations {
+		if err := h.validate.Struct(op); err != nil {
+			failures[i] = errx.NewBadRequestError(err.Error())
+		}
+	}
+
+	var result []dto.BatchOperationResult
+	var err error
+	if len(failures) > 0 {
+		err = service.NewBatchRejectedError(req.Operations, failures)
+	} else {
+		result, err = h.service.BatchTransactions(c.Context(), userID, req)
+	}
+
+	if err != nil {
+		var batchErr *service.BatchRejectedError
+		if errors.As(err, &batchErr) {
+			return c.Status(batchErr.Status).JSON(response.Response{
+				Success: false,
+				Message: batchErr.Error(),
+				Data:    batchErr.Results,
+			})
+		}
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Batch processed successfully", result))
+}
+
+// Sync godoc
+// @Summary Get changes since the last sync
+// @Description Return transactions, categories and budgets created, updated or deleted since the given token, plus a token for the next sync. Omit since for a full sync. When has_more is true, call again with the new token. Changes are only returned once every write that started before them has committed, so a token never skips a change that commits late. Tokens from older versions are still accepted.
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Param since query string false "Token from the previous sync response"
+// @Param limit query int false "Maximum number of changes to return" default(500)
+// @Success 200 {object} response.Response{data=dto.SyncResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security BearerAuth
+// @Router /sync [get]
+func (h *TransactionHandler) Sync(c *fiber.Ctx) error {
+	userID, ok := c.Locals("userID").(uuid.UUID)
+	if !ok {
+		return errx.NewUnauthorizedError("Invalid user ID")
+	}
+
+	var params dto.SyncParams
+	if err := c.QueryParser(&params); err != nil {
+		return errx.NewBadRequestError("Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return errx.NewBadRequestError(err.Error())
+	}
+
+	result, err := h.service.Sync(c.Context(), userID, params)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(response.SuccessResponse("Changes retrieved successfully", result))
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvImporter membaca export CSV aplikasi keuangan. Kolom dicari berdasarkan nama header
+// (tanpa membedakan huruf besar), sehingga urutan kolom dan kolom tambahan tidak berpengaruh.
+type csvImporter struct {
+	name string
+	// Nama header yang mungkin untuk setiap field; date dan amount wajib ada
+	columns     map[string][]string
+	dateLayouts []string
+}
+
+const (
+	csvID       = "id"
+	csvDate     = "date"
+	csvAmount   = "amount"
+	csvType     = "type"
+	csvCategory = "category"
+	csvNote     = "note"
+	csvPayee    = "payee"
+)
+
+// Money Lover menulis pengeluaran sebagai nominal negatif dengan tanggal dd/mm/yyyy.
+var moneyLoverImporter = &csvImporter{
+	name: "moneylover",
+	columns: map[string][]string{
+		csvID:       {"id"},
+		csvDate:     {"date"},
+		csvAmount:   {"amount"},
+		csvCategory: {"category"},
+		csvNote:     {"note"},
+	},
+	dateLayouts: []string{"02/01/2006", "2/1/2006", "02-01-2006", "2006-01-02"},
+}
+
+// Wallet (BudgetBakers) menyertakan kolom type berisi Income atau Expenses.
+var walletImporter = &csvImporter{
+	name: "wallet",
+	columns: map[string][]string{
+		csvDate:     {"date"},
+		csvAmount:   {"amount"},
+		csvType:     {"type"},
+		csvCategory: {"category"},
+		csvNote:     {"note"},
+		csvPayee:    {"payee"},
+	},
+	dateLayouts: []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02", "02/01/2006"},
+}
+
+// Spendee memakai header "Category name" dan tanggal ISO 8601 dengan zona waktu.
+var spendeeImporter = &csvImporter{
+	name: "spendee",
+	columns: map[string][]string{
+		csvDate:     {"date"},
+		csvAmount:   {"amount"},
+		csvType:     {"type"},
+		csvCategory: {"category name", "category"},
+		csvNote:     {"note"},
+	},
+	dateLayouts: []string{"2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02"},
+}
+
+func (imp *csvImporter) Name() string { return imp.name }
+
+func (imp *csvImporter) Parse(data []byte) (*Result, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("file has no CSV header")
+	}
+
+	index := map[string]int{}
+	for field, names := range imp.columns {
+		for _, name := range names {
+			if i := headerIndex(header, name); i >= 0 {
+				index[field] = i
+				break
+			}
+		}
+	}
+	for _, required := range []string{csvDate, csvAmount} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("missing %q column, is this a %s export?", required, imp.name)
+		}
+	}
+
+	ids := newFingerprinter(imp.name)
+	result := &Result{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		if isBlankRow(row) {
+			continue
+		}
+
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		date, err := parseDate(value(csvDate), imp.dateLayouts)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		amount, err := ParseAmount(value(csvAmount))
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Message: fmt.Sprintf("invalid amount %q", value(csvAmount))})
+			continue
+		}
+		// Kolom type lebih dipercaya daripada tanda nominal
+		switch kind := strings.ToLower(value(csvType)); {
+		case strings.HasPrefix(kind, "expense"):
+			amount = -abs(amount)
+		case strings.HasPrefix(kind, "income"):
+			amount = abs(amount)
+		}
+
+		note := value(csvNote)
+		if payee := value(csvPayee); payee != "" && payee != note {
+			if note != "" {
+				note = payee + " - " + note
+			} else {
+				note = payee
+			}
+		}
+
+		externalID := ""
+		if id := value(csvID); id != "" {
+			externalID = sourceID(imp.name, id)
+		} else {
+			externalID = ids.id(row...)
+		}
+
+		result.Records = append(result.Records, Record{
+			Line:       line,
+			ExternalID: externalID,
+			Date:       date,
+			Amount:     amount,
+			Note:       note,
+			Category:   value(csvCategory),
+		})
+	}
+
+	return result, nil
+}
+
+// sniffDelimiter memilih pemisah yang paling banyak muncul di baris header; export dengan
+// locale Eropa atau Indonesia sering memakai titik koma.
+func sniffDelimiter(data []byte) rune {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+
+	best, count := ',', bytes.Count(header, []byte{','})
+	for _, d := range []rune{';', '\t'} {
+		if n := bytes.Count(header, []byte(string(d))); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+func headerIndex(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
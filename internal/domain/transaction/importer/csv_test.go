@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		importer *csvImporter
+		data     string
+		records  []Record
+		errors   []int
+	}{
+		{
+			name:     "Money Lover",
+			importer: moneyLoverImporter,
+			data: "\xef\xbb\xbfId,Date,Category,Amount,Currency,Note\n" +
+				"17,15/01/2025,Food & Beverage,\"-35,000\",IDR,Makan siang\n" +
+				"18,25/01/2025,Salary,8000000,IDR,\n",
+			records: []Record{
+				{Line: 2, ExternalID: "moneylover:17", Date: "2025-01-15", Amount: -35000, Note: "Makan siang", Category: "Food & Beverage"},
+				{Line: 3, ExternalID: "moneylover:18", Date: "2025-01-25", Amount: 8000000, Category: "Salary"},
+			},
+		},
+		{
+			name:     "Wallet with semicolons and a type column",
+			importer: walletImporter,
+			data: "account;category;currency;amount;type;payee;note;date\n" +
+				"Cash;Groceries;IDR;120.000,50;Expenses;Superindo;Mingguan;2025-02-01 10:15:00\n" +
+				"Cash;Refund;IDR;-50.000;Income;;;2025-02-02 08:00:00\n" +
+				"Cash;Food;IDR;25.000;Expenses;Warung;Warung;2025-02-03 12:00:00\n",
+			records: []Record{
+				{Line: 2, Date: "2025-02-01", Amount: -120000.5, Note: "Superindo - Mingguan", Category: "Groceries"},
+				{Line: 3, Date: "2025-02-02", Amount: 50000, Category: "Refund"},
+				{Line: 4, Date: "2025-02-03", Amount: -25000, Note: "Warung", Category: "Food"},
+			},
+		},
+		{
+			name:     "Spendee",
+			importer: spendeeImporter,
+			data: "Date,Wallet,Type,Category name,Amount,Currency,Note,Labels,Author\n" +
+				"2025-03-01T09:30:00+07:00,Main,Expense,Transport,-15000,IDR,Ojek,,me\n" +
+				"\n" +
+				",Main,Expense,Transport,-15000,IDR,,,me\n" +
+				"2025-03-02T09:30:00+07:00,Main,Expense,Transport,lima ribu,IDR,,,me\n",
+			records: []Record{
+				{Line: 2, Date: "2025-03-01", Amount: -15000, Note: "Ojek", Category: "Transport"},
+			},
+			errors: []int{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.importer.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			for i, rec := range result.Records {
+				// Tanpa kolom id, external ID dibuat dari isi baris
+				if !strings.HasPrefix(rec.ExternalID, tt.importer.name+":") {
+					t.Errorf("record %d external ID = %q", i, rec.ExternalID)
+				}
+				if tt.records[i].ExternalID == "" {
+					result.Records[i].ExternalID = ""
+				}
+			}
+			assertRecords(t, result.Records, tt.records)
+			assertErrorLines(t, result.Errors, tt.errors)
+		})
+	}
+}
+
+func TestCSVParseMissingColumns(t *testing.T) {
+	if _, err := walletImporter.Parse([]byte("date,category\n2025-01-01,Food\n")); err == nil {
+		t.Error("Parse accepted a file without an amount column")
+	}
+	if _, err := moneyLoverImporter.Parse(nil); err == nil {
+		t.Error("Parse accepted an empty file")
+	}
+}
+
+func TestCSVParseDuplicateRows(t *testing.T) {
+	data := []byte("date,amount,note\n2025-01-01,-10,Coffee\n2025-01-01,-10,Coffee\n")
+	result, err := walletImporter.Parse(data)
+	if err != nil || len(result.Records) != 2 {
+		t.Fatalf("Parse = %+v, %v", result, err)
+	}
+	if result.Records[0].ExternalID == result.Records[1].ExternalID {
+		t.Errorf("identical rows share external ID %q", result.Records[0].ExternalID)
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   rune
+	}{
+		{"date,amount,note\n1;2", ','},
+		{"date;amount;note\n1,2,3,4", ';'},
+		{"date\tamount\tnote", '\t'},
+		{"date", ','},
+	}
+	for _, tt := range tests {
+		if got := sniffDelimiter([]byte(tt.header)); got != tt.want {
+			t.Errorf("sniffDelimiter(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
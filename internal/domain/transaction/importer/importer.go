@@ -0,0 +1,191 @@
+// Package importer mengubah file export dari bank atau aplikasi keuangan lain menjadi
+// baris transaksi yang seragam. Setiap format mendaftarkan satu Importer di registry.
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record adalah satu transaksi dari file sumber.
+type Record struct {
+	// Nomor baris (CSV, QIF) atau urutan transaksi (OFX) di file, untuk laporan error
+	Line int
+	// ID stabil dari sumber (FITID untuk OFX) atau hash isi baris jika sumber tidak
+	// punya ID, sehingga import ulang file yang sama bisa dikenali
+	ExternalID string
+	// Format 2006-01-02
+	Date string
+	// Positif untuk pemasukan, negatif untuk pengeluaran
+	Amount float64
+	Note   string
+	// Nama kategori di aplikasi sumber, kosong jika format tidak punya kategori
+	Category string
+}
+
+// RowError menjelaskan baris yang tidak bisa dibaca; baris lain tetap diimport.
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type Result struct {
+	Records []Record
+	Errors  []RowError
+}
+
+type Importer interface {
+	// Name dipakai sebagai source pada mapping kategori dan awalan external ID
+	Name() string
+	// Parse mengembalikan error jika file sama sekali bukan format ini; baris yang rusak
+	// dilaporkan di Result.Errors.
+	Parse(data []byte) (*Result, error)
+}
+
+var registry = map[string]Importer{}
+
+func init() {
+	register(ofxImporter{}, "qfx")
+	register(qifImporter{})
+	register(moneyLoverImporter)
+	register(walletImporter)
+	register(spendeeImporter)
+}
+
+// register mendaftarkan importer dengan namanya sendiri dan alias tambahan.
+func register(imp Importer, aliases ...string) {
+	registry[imp.Name()] = imp
+	for _, alias := range aliases {
+		registry[alias] = imp
+	}
+}
+
+// Lookup mencari importer berdasarkan nama format, tanpa membedakan huruf besar.
+func Lookup(format string) (Importer, bool) {
+	imp, ok := registry[strings.ToLower(strings.TrimSpace(format))]
+	return imp, ok
+}
+
+// Formats mengembalikan semua nama format yang didukung, termasuk alias, terurut.
+func Formats() []string {
+	formats := make([]string, 0, len(registry))
+	for name := range registry {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NormalizeCategory menyamakan nama kategori sumber supaya mapping tidak peka huruf besar
+// dan spasi berlebih.
+func NormalizeCategory(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// fingerprinter membuat external ID untuk sumber tanpa ID transaksi. Baris yang isinya
+// persis sama diberi nomor urut, sehingga dua transaksi kembar di satu file tetap menjadi
+// dua transaksi, dan import ulang file yang sama menghasilkan ID yang sama.
+type fingerprinter struct {
+	source string
+	seen   map[string]int
+}
+
+func newFingerprinter(source string) *fingerprinter {
+	return &fingerprinter{source: source, seen: map[string]int{}}
+}
+
+func (f *fingerprinter) id(fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	key := hex.EncodeToString(sum[:16])
+	f.seen[key]++
+	return fmt.Sprintf("%s:%s:%d", f.source, key, f.seen[key])
+}
+
+// Panjang maksimal kolom external_id
+const maxExternalIDLength = 255
+
+// sourceID membentuk external ID dari ID transaksi yang diberikan file, misalnya
+// "ofx:<rekening>:<FITID>". ID yang melebihi kolom external_id diganti hash-nya; ID pendek
+// tidak berubah supaya import ulang tetap dikenali.
+func sourceID(source string, parts ...string) string {
+	id := source + ":" + strings.Join(parts, ":")
+	if len(id) <= maxExternalIDLength {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return source + ":sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ParseAmount membaca nominal dengan pemisah ribuan titik atau koma, simbol mata uang dan
+// tanda minus atau kurung untuk angka negatif. Jika titik dan koma muncul bersamaan, yang
+// terakhir adalah pemisah desimal; jika hanya satu jenis dan diikuti tepat tiga digit, itu
+// pemisah ribuan.
+func ParseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			b.WriteRune(r)
+		case r == '-' || r == '−':
+			negative = true
+		}
+	}
+	s = b.String()
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount")
+	}
+
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(s, ",") == 1 && len(s)-lastComma-1 != 3 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastDot >= 0:
+		if strings.Count(s, ".") > 1 || len(s)-lastDot-1 == 3 {
+			s = strings.ReplaceAll(s, ".", "")
+		}
+	}
+
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// parseDate mencoba setiap layout secara berurutan dan mengembalikan tanggal 2006-01-02.
+func parseDate(s string, layouts []string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "35000", want: 35000},
+		{in: "-35000", want: -35000},
+		{in: "35.000", want: 35000},
+		{in: "35,000", want: 35000},
+		{in: "1.234.567", want: 1234567},
+		{in: "1,234,567", want: 1234567},
+		{in: "1.234,56", want: 1234.56},
+		{in: "1,234.56", want: 1234.56},
+		{in: "12,5", want: 12.5},
+		{in: "12.50", want: 12.5},
+		{in: "Rp 35.000", want: 35000},
+		{in: "IDR -1.500,00", want: -1500},
+		{in: "(250.00)", want: -250},
+		{in: "−75,25", want: -75.25},
+		{in: " 0,99 ", want: 0.99},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3,4,5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestSourceID(t *testing.T) {
+	if got := sourceID("ofx", "123", "T1"); got != "ofx:123:T1" {
+		t.Errorf("sourceID = %q", got)
+	}
+
+	long := strings.Repeat("x", 300)
+	got := sourceID("ofx", "123", long)
+	if len(got) > maxExternalIDLength || !strings.HasPrefix(got, "ofx:sha256:") {
+		t.Errorf("sourceID with a long ID = %q (%d characters)", got, len(got))
+	}
+	if again := sourceID("ofx", "123", long); again != got {
+		t.Errorf("sourceID is not stable: %q != %q", again, got)
+	}
+	if other := sourceID("ofx", "456", long); other == got {
+		t.Error("sourceID gives the same hash for different accounts")
+	}
+}
+
+func TestFingerprinter(t *testing.T) {
+	ids := newFingerprinter("qif")
+	first := ids.id("2025-01-01", "-10.00", "Coffee")
+	second := ids.id("2025-01-01", "-10.00", "Coffee")
+	other := ids.id("2025-01-01", "-10.00", "Tea")
+	if first == second || first == other {
+		t.Errorf("ids are not unique: %q %q %q", first, second, other)
+	}
+
+	// File yang sama diimport ulang mendapat ID yang sama
+	again := newFingerprinter("qif")
+	if got := again.id("2025-01-01", "-10.00", "Coffee"); got != first {
+		t.Errorf("re-import id = %q, want %q", got, first)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, format := range []string{"ofx", "QFX", " qif ", "moneylover", "wallet", "spendee"} {
+		if _, ok := Lookup(format); !ok {
+			t.Errorf("Lookup(%q) not found", format)
+		}
+	}
+	if _, ok := Lookup("xls"); ok {
+		t.Error("Lookup(xls) found an importer")
+	}
+}
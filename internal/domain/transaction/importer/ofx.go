@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ofxImporter membaca OFX 1.x (SGML, elemen tanpa tag penutup) maupun OFX 2.x (XML).
+// File QFX dari Quicken memakai struktur yang sama.
+type ofxImporter struct{}
+
+func (ofxImporter) Name() string { return "ofx" }
+
+func (ofxImporter) Parse(data []byte) (*Result, error) {
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, fmt.Errorf("file is not an OFX statement")
+	}
+
+	result := &Result{}
+	var (
+		account string
+		current map[string]string
+		index   int
+	)
+
+	for _, el := range ofxElements(content) {
+		switch el.tag {
+		case "ACCTID":
+			account = el.value
+		case "STMTTRN":
+			current = map[string]string{}
+		case "/STMTTRN":
+			if current == nil {
+				continue
+			}
+			index++
+			record, err := ofxRecord(current, account)
+			if err != nil {
+				result.Errors = append(result.Errors, RowError{Line: index, Message: err.Error()})
+			} else {
+				record.Line = index
+				result.Records = append(result.Records, *record)
+			}
+			current = nil
+		default:
+			if current != nil && el.value != "" {
+				current[el.tag] = el.value
+			}
+		}
+	}
+
+	return result, nil
+}
+
+type ofxElement struct {
+	tag   string
+	value string
+}
+
+// ofxElements memecah isi file menjadi urutan tag beserta teks setelahnya. Tag penutup
+// elemen (OFX 2.x) diabaikan, tag penutup aggregate seperti </STMTTRN> tetap dikembalikan.
+func ofxElements(content string) []ofxElement {
+	var elements []ofxElement
+	for {
+		start := strings.IndexByte(content, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(content[start:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(content[start+1 : start+end]))
+		content = content[start+end+1:]
+
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		value := content
+		if next := strings.IndexByte(content, '<'); next >= 0 {
+			value = content[:next]
+		}
+
+		if strings.HasPrefix(tag, "/") && tag != "/STMTTRN" {
+			continue
+		}
+		elements = append(elements, ofxElement{tag: tag, value: html.UnescapeString(strings.TrimSpace(value))})
+	}
+	return elements
+}
+
+func ofxRecord(fields map[string]string, account string) (*Record, error) {
+	fitID := fields["FITID"]
+	if fitID == "" {
+		return nil, fmt.Errorf("transaction has no FITID")
+	}
+
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return nil, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+	date, err := parseDate(posted[:8], []string{"20060102"})
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseOFXAmount(fields["TRNAMT"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRNAMT %q", fields["TRNAMT"])
+	}
+
+	note := fields["NAME"]
+	if memo := fields["MEMO"]; memo != "" && memo != note {
+		if note != "" {
+			note += " - "
+		}
+		note += memo
+	}
+
+	// FITID hanya unik per rekening, jadi ID rekening ikut menjadi bagian external ID
+	return &Record{
+		ExternalID: sourceID("ofx", account, fitID),
+		Date:       date,
+		Amount:     amount,
+		Note:       note,
+	}, nil
+}
+
+// parseOFXAmount membaca TRNAMT. OFX tidak memakai pemisah ribuan, jadi titik atau koma
+// selalu pemisah desimal ("12.500" adalah 12,5), berbeda dengan ParseAmount untuk CSV.
+func parseOFXAmount(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	return amount, nil
+}
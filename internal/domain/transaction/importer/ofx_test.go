@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM>
+<BANKID>014
+<ACCTID>1234567890
+</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250115120000[+7:WIB]
+<TRNAMT>-35000.00
+<FITID>T001
+<NAME>Indomaret
+<MEMO>Belanja &amp; parkir
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250125
+<TRNAMT>8000000,50
+<FITID>T002
+<NAME>Gaji
+<MEMO>Gaji
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250126
+<TRNAMT>-10000
+</STMTTRN>
+<STMTTRN>
+<DTPOSTED>2025
+<TRNAMT>-1
+<FITID>T004
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>DEBIT</TRNTYPE>
+        <DTPOSTED>20250301</DTPOSTED>
+        <TRNAMT>-12.500</TRNAMT>
+        <FITID>X1</FITID>
+        <MEMO>Coffee</MEMO>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestOFXParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		records []Record
+		errors  []int
+	}{
+		{
+			name: "SGML",
+			data: ofxSGML,
+			records: []Record{
+				{Line: 1, ExternalID: "ofx:1234567890:T001", Date: "2025-01-15", Amount: -35000, Note: "Indomaret - Belanja & parkir"},
+				{Line: 2, ExternalID: "ofx:1234567890:T002", Date: "2025-01-25", Amount: 8000000.5, Note: "Gaji"},
+			},
+			// Transaksi ketiga tanpa FITID, keempat dengan DTPOSTED rusak
+			errors: []int{3, 4},
+		},
+		{
+			name: "XML",
+			data: ofxXML,
+			records: []Record{
+				{Line: 1, ExternalID: "ofx:4111:X1", Date: "2025-03-01", Amount: -12.5, Note: "Coffee"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ofxImporter{}.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			assertRecords(t, result.Records, tt.records)
+			assertErrorLines(t, result.Errors, tt.errors)
+		})
+	}
+}
+
+func TestOFXParseLongFITID(t *testing.T) {
+	fitID := strings.Repeat("9", 300)
+	data := strings.Replace(ofxXML, "<FITID>X1</FITID>", "<FITID>"+fitID+"</FITID>", 1)
+
+	result, err := ofxImporter{}.Parse([]byte(data))
+	if err != nil || len(result.Records) != 1 {
+		t.Fatalf("Parse = %+v, %v", result, err)
+	}
+	if got := result.Records[0].ExternalID; got != sourceID("ofx", "4111", fitID) || len(got) > maxExternalIDLength {
+		t.Errorf("ExternalID = %q", got)
+	}
+}
+
+func TestOFXParseRejectsOtherFiles(t *testing.T) {
+	if _, err := (ofxImporter{}).Parse([]byte("date,amount\n2025-01-01,10\n")); err == nil {
+		t.Error("Parse accepted a CSV file")
+	}
+}
+
+func TestParseOFXAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "-35000.00", want: -35000},
+		{in: "12.500", want: 12.5},
+		{in: "12,5", want: 12.5},
+		{in: " 100 ", want: 100},
+		{in: "", wantErr: true},
+		{in: "1.234,56", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOFXAmount(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseOFXAmount(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func assertRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d\n got: %+v\nwant: %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func assertErrorLines(t *testing.T, got []RowError, want []int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got errors %+v, want lines %v", got, want)
+	}
+	for i := range want {
+		if got[i].Line != want[i] {
+			t.Errorf("error %d on line %d, want line %d (%s)", i, got[i].Line, want[i], got[i].Message)
+		}
+	}
+}
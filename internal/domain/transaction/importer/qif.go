@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// qifImporter membaca file QIF (Quicken Interchange Format) untuk rekening bank, kas dan
+// kartu kredit. QIF tidak punya ID transaksi, jadi external ID dibuat dari isi record.
+type qifImporter struct{}
+
+func (qifImporter) Name() string { return "qif" }
+
+type qifRecord struct {
+	line   int
+	fields map[byte]string
+}
+
+func (qifImporter) Parse(data []byte) (*Result, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("!")) {
+		return nil, fmt.Errorf("file is not a QIF file")
+	}
+
+	var (
+		records []qifRecord
+		current *qifRecord
+		inTxns  bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if text[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(text))
+			// !Option dan !Clear hanya mengatur perilaku Quicken, bukan awal bagian baru
+			if strings.HasPrefix(header, "!option") || strings.HasPrefix(header, "!clear") {
+				continue
+			}
+			inTxns = isQIFTransactionSection(header)
+			current = nil
+			continue
+		}
+		if !inTxns {
+			continue
+		}
+
+		if text[0] == '^' {
+			if current != nil {
+				records = append(records, *current)
+			}
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			current = &qifRecord{line: line, fields: map[byte]string{}}
+		}
+		code := text[0]
+		// Field pertama yang muncul dipakai; split (S, E, $) bisa berulang dan diabaikan
+		if _, exists := current.fields[code]; !exists {
+			current.fields[code] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		records = append(records, *current)
+	}
+
+	layouts := qifDateLayouts(records)
+	ids := newFingerprinter("qif")
+	result := &Result{}
+	for _, rec := range records {
+		f := rec.fields
+
+		date, err := parseDate(normalizeQIFDate(f['D']), layouts)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: rec.line, Message: err.Error()})
+			continue
+		}
+
+		rawAmount := f['T']
+		if rawAmount == "" {
+			rawAmount = f['U']
+		}
+		amount, err := ParseAmount(rawAmount)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: rec.line, Message: fmt.Sprintf("invalid amount %q", rawAmount)})
+			continue
+		}
+
+		note := f['P']
+		if memo := f['M']; memo != "" && memo != note {
+			if note != "" {
+				note += " - "
+			}
+			note += memo
+		}
+
+		// Kategori berbentuk [Nama Rekening] adalah transfer antar rekening, bukan kategori
+		category := f['L']
+		if strings.HasPrefix(category, "[") {
+			category = ""
+		}
+
+		result.Records = append(result.Records, Record{
+			Line:       rec.line,
+			ExternalID: ids.id(date, strconv.FormatFloat(amount, 'f', 2, 64), f['P'], f['M'], f['L'], f['N']),
+			Date:       date,
+			Amount:     amount,
+			Note:       note,
+			Category:   category,
+		})
+	}
+
+	return result, nil
+}
+
+func isQIFTransactionSection(header string) bool {
+	switch strings.TrimSpace(strings.TrimPrefix(header, "!type:")) {
+	case "bank", "cash", "ccard", "oth a", "oth l":
+		return true
+	}
+	return false
+}
+
+// normalizeQIFDate menyeragamkan pemisah tanggal; Quicken menulis tahun 2000-an seperti
+// 1/15'24 atau 1/15' 4.
+func normalizeQIFDate(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "0")
+	if len(s) == 10 && s[4] == '-' {
+		return s
+	}
+	return strings.NewReplacer("'", "/", "-", "/", ".", "/").Replace(s)
+}
+
+// qifDateLayouts menentukan urutan hari dan bulan untuk seluruh file. Default-nya
+// bulan/hari seperti Quicken, kecuali ada tanggal yang angka pertamanya lebih dari 12.
+func qifDateLayouts(records []qifRecord) []string {
+	dayFirst := false
+	for _, rec := range records {
+		parts := strings.Split(normalizeQIFDate(rec.fields['D']), "/")
+		if len(parts) != 3 || len(parts[0]) > 2 {
+			continue
+		}
+		if n, err := strconv.Atoi(parts[0]); err == nil && n > 12 {
+			dayFirst = true
+			break
+		}
+	}
+
+	if dayFirst {
+		return []string{"2/1/2006", "2/1/06", "2006-01-02", "2006/1/2"}
+	}
+	return []string{"1/2/2006", "1/2/06", "2006-01-02", "2006/1/2"}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import "testing"
+
+func TestQIFParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		records []Record
+		errors  []int
+	}{
+		{
+			name: "month first with Quicken years",
+			data: "!Type:Bank\n" +
+				"D1/15'24\nT-35,000.00\nPIndomaret\nMBelanja\nLGroceries\n^\n" +
+				"D2/ 1' 4\nU1,500.50\nPRefund\n^\n",
+			records: []Record{
+				{Line: 2, Date: "2024-01-15", Amount: -35000, Note: "Indomaret - Belanja", Category: "Groceries"},
+				{Line: 8, Date: "2004-02-01", Amount: 1500.5, Note: "Refund"},
+			},
+		},
+		{
+			name: "day first detected from the file",
+			data: "!Type:CCard\n" +
+				"D03/02/2025\nT-10.00\nPCoffee\n^\n" +
+				"D25/02/2025\nT-20.00\nPLunch\n^\n",
+			records: []Record{
+				{Line: 2, Date: "2025-02-03", Amount: -10, Note: "Coffee"},
+				{Line: 6, Date: "2025-02-25", Amount: -20, Note: "Lunch"},
+			},
+		},
+		{
+			name: "transfers, splits and other sections",
+			data: "!Option:AutoSwitch\n!Account\nNChecking\nTBank\n^\n" +
+				"!Type:Cash\n" +
+				"D2025-03-01\nT-50000\nPTopup\nL[Savings]\n^\n" +
+				"D2025-03-02\nT-90000\nLFood\nSFood\n$-60000\nSTransport\n$-30000\n^\n" +
+				"!Type:Invst\nD2025-03-03\nT-1\n^\n",
+			records: []Record{
+				{Line: 7, Date: "2025-03-01", Amount: -50000, Note: "Topup"},
+				{Line: 12, Date: "2025-03-02", Amount: -90000, Category: "Food"},
+			},
+		},
+		{
+			name: "broken rows are reported",
+			data: "!Type:Bank\n" +
+				"Dnot a date\nT-1\n^\n" +
+				"D2025-01-01\nTabc\n^\n" +
+				"D2025-01-02\nT5\n",
+			records: []Record{
+				{Line: 8, Date: "2025-01-02", Amount: 5},
+			},
+			errors: []int{2, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := qifImporter{}.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			for i := range result.Records {
+				if result.Records[i].ExternalID == "" {
+					t.Errorf("record %d has no external ID", i)
+				}
+				result.Records[i].ExternalID = ""
+			}
+			assertRecords(t, result.Records, tt.records)
+			assertErrorLines(t, result.Errors, tt.errors)
+		})
+	}
+}
+
+func TestQIFParseDuplicates(t *testing.T) {
+	data := []byte("!Type:Bank\nD1/1/2025\nT-10\nPCoffee\n^\nD1/1/2025\nT-10\nPCoffee\n^\n")
+
+	first, err := qifImporter{}.Parse(data)
+	if err != nil || len(first.Records) != 2 {
+		t.Fatalf("Parse = %+v, %v", first, err)
+	}
+	a, b := first.Records[0].ExternalID, first.Records[1].ExternalID
+	if a == b {
+		t.Errorf("identical records share external ID %q", a)
+	}
+
+	// Import ulang file yang sama menghasilkan ID yang sama
+	again, _ := qifImporter{}.Parse(data)
+	if again.Records[0].ExternalID != a || again.Records[1].ExternalID != b {
+		t.Errorf("re-import ids = %q, %q; want %q, %q", again.Records[0].ExternalID, again.Records[1].ExternalID, a, b)
+	}
+}
+
+func TestQIFParseRejectsOtherFiles(t *testing.T) {
+	if _, err := (qifImporter{}).Parse([]byte("<OFX></OFX>")); err == nil {
+		t.Error("Parse accepted an OFX file")
+	}
+}
@@ -0,0 +1,174 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "januari": time.January, "january": time.January,
+	"feb": time.February, "februari": time.February, "february": time.February, "peb": time.February,
+	"mar": time.March, "maret": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"mei": time.May, "may": time.May,
+	"jun": time.June, "juni": time.June, "june": time.June,
+	"jul": time.July, "juli": time.July, "july": time.July,
+	"agu": time.August, "agt": time.August, "agus": time.August, "agustus": time.August, "aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"okt": time.October, "oktober": time.October, "oct": time.October, "october": time.October,
+	"nov": time.November, "nop": time.November, "november": time.November, "nopember": time.November,
+	"des": time.December, "desember": time.December, "dec": time.December, "december": time.December,
+}
+
+var weekdays = map[string]time.Weekday{
+	"minggu": time.Sunday, "ahad": time.Sunday, "sunday": time.Sunday,
+	"senin": time.Monday, "monday": time.Monday,
+	"selasa": time.Tuesday, "tuesday": time.Tuesday,
+	"rabu": time.Wednesday, "wednesday": time.Wednesday,
+	"kamis": time.Thursday, "thursday": time.Thursday,
+	"jumat": time.Friday, "jum'at": time.Friday, "friday": time.Friday,
+	"sabtu": time.Saturday, "saturday": time.Saturday,
+}
+
+// Selisih hari dari hari ini untuk kata tanggal relatif
+var relativeDays = map[string]int{
+	"hari ini": 0, "today": 0, "tadi": 0,
+	"kemarin lusa": -2, "kemarin": -1, "kemaren": -1, "kmrn": -1, "kmarin": -1, "yesterday": -1,
+	"besok": 1, "tomorrow": 1, "lusa": 2,
+	"minggu lalu": -7, "last week": -7,
+}
+
+var (
+	numericDate = regexp.MustCompile(`^([0-9]{1,2})[/-]([0-9]{1,2})(?:[/-]([0-9]{2}|[0-9]{4}))?$`)
+	isoDate     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	dayNumber   = regexp.MustCompile(`^[0-9]{1,2}$`)
+	yearNumber  = regexp.MustCompile(`^[0-9]{4}$`)
+)
+
+// Tanggal tanpa tahun yang jatuh lebih dari sekian hari ke depan dianggap tahun lalu,
+// misalnya "28 des" yang diketik awal Januari
+const futureDateTolerance = 31
+
+// matchDate mencoba membaca tanggal yang dimulai di tokens[i] dan mengembalikan jumlah token
+// yang dipakai. Kata depan seperti "tgl" atau "on" ikut dipakai.
+func matchDate(tokens []string, i int, today time.Time) (time.Time, int, bool) {
+	switch tokens[i] {
+	case "tgl", "tanggal", "pada", "on":
+		if i+1 < len(tokens) {
+			if date, n, ok := matchDate(tokens, i+1, today); ok {
+				return date, n + 1, true
+			}
+		}
+		return time.Time{}, 0, false
+	}
+
+	// Frasa dua kata didahulukan, supaya "kemarin lusa" tidak terbaca sebagai "kemarin"
+	if i+1 < len(tokens) {
+		if days, ok := relativeDays[tokens[i]+" "+tokens[i+1]]; ok {
+			return today.AddDate(0, 0, days), 2, true
+		}
+	}
+	if days, ok := relativeDays[tokens[i]]; ok {
+		return today.AddDate(0, 0, days), 1, true
+	}
+
+	// "3 hari lalu", "3 hari yang lalu", "3 days ago"
+	if n, err := strconv.Atoi(tokens[i]); err == nil && n >= 0 && n <= 366 && i+2 < len(tokens) {
+		switch {
+		case (tokens[i+1] == "hari" || tokens[i+1] == "hr") && tokens[i+2] == "lalu":
+			return today.AddDate(0, 0, -n), 3, true
+		case tokens[i+1] == "hari" && (tokens[i+2] == "yang" || tokens[i+2] == "yg") && i+3 < len(tokens) && tokens[i+3] == "lalu":
+			return today.AddDate(0, 0, -n), 4, true
+		case (tokens[i+1] == "days" || tokens[i+1] == "day") && tokens[i+2] == "ago":
+			return today.AddDate(0, 0, -n), 3, true
+		}
+	}
+
+	// Nama hari berarti hari tersebut yang terakhir lewat; "senin lalu" atau "last monday"
+	// tidak termasuk hari ini
+	if tokens[i] == "last" && i+1 < len(tokens) {
+		if weekday, ok := weekdays[tokens[i+1]]; ok {
+			return previousWeekday(today, weekday, false), 2, true
+		}
+	}
+	if weekday, ok := weekdays[tokens[i]]; ok {
+		if i+1 < len(tokens) && tokens[i+1] == "lalu" {
+			return previousWeekday(today, weekday, false), 2, true
+		}
+		return previousWeekday(today, weekday, true), 1, true
+	}
+
+	if isoDate.MatchString(tokens[i]) {
+		if date, err := time.Parse("2006-01-02", tokens[i]); err == nil {
+			return date, 1, true
+		}
+	}
+	if m := numericDate.FindStringSubmatch(tokens[i]); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if date, ok := buildDate(day, time.Month(month), m[3], today); ok {
+			return date, 1, true
+		}
+	}
+
+	// "25 okt", "25 oktober 2024"
+	if dayNumber.MatchString(tokens[i]) && i+1 < len(tokens) {
+		if month, ok := months[tokens[i+1]]; ok {
+			day, _ := strconv.Atoi(tokens[i])
+			year, n := "", 2
+			if i+2 < len(tokens) && yearNumber.MatchString(tokens[i+2]) {
+				year, n = tokens[i+2], 3
+			}
+			if date, ok := buildDate(day, month, year, today); ok {
+				return date, n, true
+			}
+		}
+	}
+
+	// "oct 25", "october 25 2024"
+	if month, ok := months[tokens[i]]; ok && i+1 < len(tokens) && dayNumber.MatchString(tokens[i+1]) {
+		day, _ := strconv.Atoi(tokens[i+1])
+		year, n := "", 2
+		if i+2 < len(tokens) && yearNumber.MatchString(tokens[i+2]) {
+			year, n = tokens[i+2], 3
+		}
+		if date, ok := buildDate(day, month, year, today); ok {
+			return date, n, true
+		}
+	}
+
+	return time.Time{}, 0, false
+}
+
+// buildDate membentuk tanggal yang valid; tahun kosong berarti tahun ini atau tahun lalu.
+func buildDate(day int, month time.Month, year string, today time.Time) (time.Time, bool) {
+	if month < time.January || month > time.December || day < 1 {
+		return time.Time{}, false
+	}
+
+	y := today.Year()
+	if year != "" {
+		y, _ = strconv.Atoi(year)
+		if len(year) == 2 {
+			y += 2000
+		}
+	}
+
+	date := time.Date(y, month, day, 0, 0, 0, 0, time.UTC)
+	if year == "" && date.After(today.AddDate(0, 0, futureDateTolerance)) {
+		date = time.Date(y-1, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func previousWeekday(today time.Time, weekday time.Weekday, includeToday bool) time.Time {
+	days := (int(today.Weekday()) - int(weekday) + 7) % 7
+	if days == 0 && !includeToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, -days)
+}
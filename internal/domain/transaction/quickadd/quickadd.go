@@ -0,0 +1,236 @@
+// Package quickadd membaca kalimat singkat seperti "makan siang 35rb kemarin" atau
+// "gaji 8jt 25 okt" menjadi draft transaksi. Bahasa Indonesia dan Inggris sama-sama dikenali.
+package quickadd
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry adalah hasil pembacaan satu kalimat.
+type Entry struct {
+	// 0 jika kalimat tidak menyebut nominal
+	Amount float64
+	// income atau expense; expense jika tidak ada petunjuk
+	TransactionType string
+	// true jika tipe berasal dari tanda +/- atau kata kunci, bukan default
+	TypeFound bool
+	// Format 2006-01-02, hari ini jika kalimat tidak menyebut tanggal
+	Date      string
+	DateFound bool
+	// Sisa kalimat setelah nominal dan tanggal dibuang
+	Note string
+}
+
+var multipliers = map[string]float64{
+	"k": 1e3, "rb": 1e3, "ribu": 1e3, "rebu": 1e3, "thousand": 1e3,
+	"jt": 1e6, "juta": 1e6, "m": 1e6, "mio": 1e6, "million": 1e6,
+}
+
+var incomeKeywords = map[string]bool{
+	"gaji": true, "gajian": true, "salary": true, "bonus": true, "thr": true, "pemasukan": true,
+	"income": true, "terima": true, "diterima": true, "dapat": true, "dapet": true, "refund": true,
+	"cashback": true, "dividen": true, "dividend": true, "jual": true, "jualan": true,
+	"penjualan": true, "sold": true, "received": true, "receive": true, "earned": true,
+}
+
+var expenseKeywords = map[string]bool{
+	"beli": true, "bayar": true, "byr": true, "pengeluaran": true, "expense": true, "spent": true,
+	"spend": true, "bought": true, "buy": true, "pay": true, "paid": true, "belanja": true,
+}
+
+// Kata yang hanya menandai tipe atau nominal, tidak perlu masuk note
+var fillerWords = map[string]bool{
+	"pemasukan": true, "pengeluaran": true, "income": true, "expense": true,
+	"sebesar": true, "seharga": true,
+}
+
+var numberPattern = regexp.MustCompile(`^([0-9]+(?:[.,][0-9]+)*)([a-z]*)$`)
+
+// Parse membaca text dengan today sebagai acuan tanggal relatif.
+func Parse(text string, today time.Time) Entry {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	words := strings.Fields(text)
+	tokens := make([]string, len(words))
+	for i, w := range words {
+		tokens[i] = normalizeToken(w)
+	}
+	used := make([]bool, len(words))
+
+	entry := Entry{TransactionType: "expense", Date: today.Format("2006-01-02")}
+
+	// Tanggal dicari lebih dulu supaya "25 okt" tidak terbaca sebagai nominal 25
+	for i := 0; i < len(tokens) && !entry.DateFound; i++ {
+		date, n, ok := matchDate(tokens, i, today)
+		if !ok {
+			continue
+		}
+		entry.Date, entry.DateFound = date.Format("2006-01-02"), true
+		for j := i; j < i+n; j++ {
+			used[j] = true
+		}
+	}
+
+	if start, n, amount, sign, ok := findAmount(tokens, used); ok {
+		entry.Amount = amount
+		if sign != "" {
+			entry.TransactionType, entry.TypeFound = sign, true
+		}
+		for j := start; j < start+n; j++ {
+			used[j] = true
+		}
+	}
+
+	var note []string
+	for i, w := range words {
+		if used[i] {
+			continue
+		}
+		t := tokens[i]
+		if !entry.TypeFound {
+			if incomeKeywords[t] {
+				entry.TransactionType, entry.TypeFound = "income", true
+			} else if expenseKeywords[t] {
+				entry.TypeFound = true
+			}
+		}
+		if fillerWords[t] {
+			continue
+		}
+		note = append(note, w)
+	}
+	entry.Note = strings.Trim(strings.Join(note, " "), " ,;-")
+
+	return entry
+}
+
+// findAmount memilih nominal dari token yang belum dipakai. Angka dengan satuan (rb, jt, k),
+// awalan Rp atau tanda +/- didahulukan daripada angka polos seperti "2" pada "2 porsi".
+func findAmount(tokens []string, used []bool) (start, n int, amount float64, sign string, ok bool) {
+	bestPriority := 0
+	for i := 0; i < len(tokens); i++ {
+		if used[i] {
+			continue
+		}
+
+		j, priority, tokenSign := i, 0, ""
+		t := tokens[i]
+		if strings.HasPrefix(t, "+") || strings.HasPrefix(t, "-") {
+			tokenSign = "income"
+			if t[0] == '-' {
+				tokenSign = "expense"
+			}
+			t, priority = t[1:], 2
+		}
+		if strings.HasPrefix(t, "rp") || strings.HasPrefix(t, "idr") {
+			t = strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(t, "rp"), "idr"), ".")
+			priority = 2
+			// "Rp 35.000" memisahkan mata uang dan angka
+			if t == "" && j+1 < len(tokens) && !used[j+1] {
+				j++
+				t = tokens[j]
+			}
+		}
+
+		value, consumed, explicit, valid := parseNumber(t, tokens, used, j)
+		if !valid || value <= 0 {
+			continue
+		}
+		if explicit {
+			priority = 2
+		}
+		if priority == 0 {
+			priority = 1
+		}
+
+		if priority > bestPriority {
+			bestPriority = priority
+			start, n, amount, sign, ok = i, j-i+consumed, value, tokenSign, true
+		}
+	}
+	return start, n, amount, sign, ok
+}
+
+// parseNumber membaca angka di token (yang sudah dibersihkan dari tanda dan Rp) beserta satuan
+// yang menempel atau di token berikutnya. consumed adalah jumlah token mulai dari index.
+func parseNumber(t string, tokens []string, used []bool, index int) (value float64, consumed int, explicit, ok bool) {
+	m := numberPattern.FindStringSubmatch(t)
+	if m == nil {
+		return 0, 0, false, false
+	}
+	digits, suffix := m[1], m[2]
+	consumed = 1
+
+	if suffix == "" && index+1 < len(tokens) && !used[index+1] {
+		if _, isUnit := multipliers[tokens[index+1]]; isUnit {
+			suffix = tokens[index+1]
+			consumed = 2
+		}
+	}
+
+	multiplier := 1.0
+	if suffix != "" {
+		unit, isUnit := multipliers[suffix]
+		if !isUnit {
+			return 0, 0, false, false
+		}
+		multiplier, explicit = unit, true
+	}
+
+	value, ok = parseDigits(digits, explicit)
+	if !ok {
+		return 0, 0, false, false
+	}
+	// Dibulatkan ke sen supaya "1,1jt" tidak menjadi 1100000.0000000002
+	return math.Round(value*multiplier*100) / 100, consumed, explicit, true
+}
+
+// parseDigits membaca angka dengan pemisah titik atau koma. Dengan satuan, satu pemisah berarti
+// desimal ("1,5jt"); tanpa satuan, kelompok tiga digit berarti pemisah ribuan ("35.000").
+func parseDigits(s string, withUnit bool) (float64, bool) {
+	groups := strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == ',' })
+
+	var normalized string
+	switch {
+	case len(groups) == 1:
+		normalized = s
+	case withUnit && len(groups) == 2:
+		normalized = groups[0] + "." + groups[1]
+	default:
+		last := groups[len(groups)-1]
+		middleThousands := true
+		for _, g := range groups[1 : len(groups)-1] {
+			if len(g) != 3 {
+				middleThousands = false
+			}
+		}
+		// Pemisah desimal harus berbeda dari pemisah ribuan, jadi "1.500,50" dan "1,500.50"
+		// terbaca tetapi "1.500.50" tidak
+		decimalSep := s[len(s)-len(last)-1]
+		thousandsSep := s[len(groups[0])]
+		switch {
+		case !middleThousands:
+			return 0, false
+		case len(last) == 3:
+			normalized = strings.Join(groups, "")
+		case len(last) <= 2 && (len(groups) == 2 || decimalSep != thousandsSep):
+			normalized = strings.Join(groups[:len(groups)-1], "") + "." + last
+		default:
+			return 0, false
+		}
+	}
+
+	value, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func normalizeToken(w string) string {
+	return strings.TrimRight(strings.Trim(strings.ToLower(w), ",;:!?()\""), ".")
+}
@@ -0,0 +1,128 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	// Rabu, 29 Oktober 2025
+	today := time.Date(2025, 10, 29, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		text      string
+		today     time.Time
+		amount    float64
+		txType    string
+		typeFound bool
+		date      string
+		dateFound bool
+		note      string
+	}{
+		// Contoh dari request
+		{text: "makan siang 35rb kemarin", amount: 35000, txType: "expense", date: "2025-10-28", dateFound: true, note: "makan siang"},
+		{text: "gaji 8jt 25 okt", amount: 8000000, txType: "income", typeFound: true, date: "2025-10-25", dateFound: true, note: "gaji"},
+
+		// Satuan nominal
+		{text: "kopi 25k", amount: 25000, txType: "expense", date: "2025-10-29", note: "kopi"},
+		{text: "kopi 25.5k", amount: 25500, txType: "expense", date: "2025-10-29", note: "kopi"},
+		{text: "motor 1,5jt", amount: 1500000, txType: "expense", date: "2025-10-29", note: "motor"},
+		{text: "motor 1.5 jt", amount: 1500000, txType: "expense", date: "2025-10-29", note: "motor"},
+		{text: "laptop 12 juta", amount: 12000000, txType: "expense", date: "2025-10-29", note: "laptop"},
+		{text: "makan 20 rb", amount: 20000, txType: "expense", date: "2025-10-29", note: "makan"},
+
+		// Pemisah ribuan dan desimal tanpa satuan
+		{text: "1.500", amount: 1500, txType: "expense", date: "2025-10-29"},
+		{text: "bayar listrik 1.234.567", amount: 1234567, txType: "expense", typeFound: true, date: "2025-10-29", note: "bayar listrik"},
+		{text: "beli buku 12,50", amount: 12.5, txType: "expense", typeFound: true, date: "2025-10-29", note: "beli buku"},
+		{text: "Rp 1.500,50 parkir", amount: 1500.5, txType: "expense", date: "2025-10-29", note: "parkir"},
+		// Kelompok terakhir empat digit bukan pemisah ribuan maupun sen
+		{text: "1.2345", txType: "expense", date: "2025-10-29", note: "1.2345"},
+
+		// Rp, satuan dan tanda lebih diutamakan daripada angka polos
+		{text: "2 porsi 30rb", amount: 30000, txType: "expense", date: "2025-10-29", note: "2 porsi"},
+		{text: "rp 35.000", amount: 35000, txType: "expense", date: "2025-10-29"},
+		{text: "Rp35.000 parkir", amount: 35000, txType: "expense", date: "2025-10-29", note: "parkir"},
+		{text: "3 tiket IDR 150.000", amount: 150000, txType: "expense", date: "2025-10-29", note: "3 tiket"},
+		{text: "2 porsi", amount: 2, txType: "expense", date: "2025-10-29", note: "porsi"},
+
+		// Tanda +/- menentukan tipe
+		{text: "+500k freelance", amount: 500000, txType: "income", typeFound: true, date: "2025-10-29", note: "freelance"},
+		{text: "-20000 bensin", amount: 20000, txType: "expense", typeFound: true, date: "2025-10-29", note: "bensin"},
+		{text: "-50rb gaji dipotong", amount: 50000, txType: "expense", typeFound: true, date: "2025-10-29", note: "gaji dipotong"},
+
+		// Kata kunci tipe; kata pengisi tidak masuk note
+		{text: "pemasukan 2 juta dari jualan", amount: 2000000, txType: "income", typeFound: true, date: "2025-10-29", note: "dari jualan"},
+		{text: "lunch 15k yesterday", amount: 15000, txType: "expense", date: "2025-10-28", dateFound: true, note: "lunch"},
+		{text: "received 1m refund", amount: 1000000, txType: "income", typeFound: true, date: "2025-10-29", note: "received refund"},
+
+		// Tanggal relatif dan absolut
+		{text: "parkir 5rb kemarin lusa", amount: 5000, txType: "expense", date: "2025-10-27", dateFound: true, note: "parkir"},
+		{text: "makan 20rb 3 hari lalu", amount: 20000, txType: "expense", date: "2025-10-26", dateFound: true, note: "makan"},
+		{text: "taxi 80k 2 days ago", amount: 80000, txType: "expense", date: "2025-10-27", dateFound: true, note: "taxi"},
+		{text: "kopi 18rb senin", amount: 18000, txType: "expense", date: "2025-10-27", dateFound: true, note: "kopi"},
+		{text: "kopi 18rb rabu", amount: 18000, txType: "expense", date: "2025-10-29", dateFound: true, note: "kopi"},
+		{text: "kopi 18rb rabu lalu", amount: 18000, txType: "expense", date: "2025-10-22", dateFound: true, note: "kopi"},
+		{text: "gaji tgl 25/10", txType: "income", typeFound: true, date: "2025-10-25", dateFound: true, note: "gaji"},
+		{text: "sewa 2jt 2025-09-01", amount: 2000000, txType: "expense", date: "2025-09-01", dateFound: true, note: "sewa"},
+		{text: "hotel 1.2jt oct 3 2024", amount: 1200000, txType: "expense", date: "2024-10-03", dateFound: true, note: "hotel"},
+		// Tanggal tanpa tahun yang terlalu jauh ke depan dianggap tahun lalu
+		{text: "kado 100rb 28 des", today: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), amount: 100000, txType: "expense", date: "2025-12-28", dateFound: true, note: "kado"},
+		// 30 Februari bukan tanggal, jadi angkanya dibaca sebagai nominal
+		{text: "30 feb", amount: 30, txType: "expense", date: "2025-10-29", note: "feb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			now := today
+			if !tt.today.IsZero() {
+				now = tt.today
+			}
+
+			got := Parse(tt.text, now)
+			want := Entry{
+				Amount:          tt.amount,
+				TransactionType: tt.txType,
+				TypeFound:       tt.typeFound,
+				Date:            tt.date,
+				DateFound:       tt.dateFound,
+				Note:            tt.note,
+			}
+			if got != want {
+				t.Errorf("Parse(%q)\n got: %+v\nwant: %+v", tt.text, got, want)
+			}
+		})
+	}
+}
+
+func TestParseDigits(t *testing.T) {
+	tests := []struct {
+		in       string
+		withUnit bool
+		want     float64
+		ok       bool
+	}{
+		{"35000", false, 35000, true},
+		{"35.000", false, 35000, true},
+		{"35,000", false, 35000, true},
+		{"1.500", false, 1500, true},
+		{"1.500", true, 1.5, true},
+		{"1,5", true, 1.5, true},
+		{"1,5", false, 1.5, true},
+		{"12,50", false, 12.5, true},
+		{"1.234.567", false, 1234567, true},
+		{"1.234.567,89", false, 1234567.89, true},
+		{"1.2345", false, 0, false},
+		{"1,500.50", false, 1500.5, true},
+		{"1.500.50", false, 0, false},
+		{"1.23.4", false, 0, false},
+		{"1.23.456", false, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseDigits(tt.in, tt.withUnit)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseDigits(%q, %v) = %v, %v; want %v, %v", tt.in, tt.withUnit, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Package receipt membaca email struk (.eml/MIME) dari e-commerce dan transportasi online lalu
+// mengambil merchant, total, tanggal dan lampirannya memakai template per pengirim.
+package receipt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Batas kedalaman multipart bersarang dan jumlah lampiran yang diambil dari satu email
+	maxPartDepth   = 10
+	maxAttachments = 10
+)
+
+// Email adalah isi email yang sudah didekode.
+type Email struct {
+	MessageID string
+	// Alamat pengirim huruf kecil. Untuk email yang diteruskan, ini pengirim aslinya.
+	From     string
+	FromName string
+	// Alamat yang meneruskan email, kosong jika email tidak diteruskan
+	ForwardedBy string
+	Subject     string
+	// Zero jika header Date tidak ada atau tidak terbaca
+	Date time.Time
+	// true jika Date berasal dari teks forward tanpa zona waktu, jadi tidak perlu dikonversi
+	floatingDate bool
+	// Isi text/plain diikuti isi text/html yang sudah diubah menjadi teks
+	Text        string
+	Attachments []File
+}
+
+// File adalah lampiran email.
+type File struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type parsedParts struct {
+	plain       []string
+	html        []string
+	attachments []File
+	// Email lain yang dilampirkan utuh, biasanya hasil "forward as attachment"
+	forwarded *Email
+}
+
+// Parse membaca email mentah berformat RFC 5322.
+func Parse(data []byte) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	decoder := &mime.WordDecoder{CharsetReader: charsetReader}
+	email := &Email{
+		MessageID: strings.Trim(msg.Header.Get("Message-Id"), "<> \t"),
+		Subject:   decodeHeader(decoder, msg.Header.Get("Subject")),
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		email.Date = date
+	}
+
+	parser := mail.AddressParser{WordDecoder: decoder}
+	if from, err := parser.Parse(msg.Header.Get("From")); err == nil {
+		email.From, email.FromName = strings.ToLower(from.Address), from.Name
+	}
+
+	parts := &parsedParts{}
+	header := textproto.MIMEHeader(msg.Header)
+	if err := walkPart(header, msg.Body, parts, 0); err != nil {
+		return nil, err
+	}
+
+	email.Text = strings.TrimSpace(strings.Join(append(parts.plain, parts.html...), "\n"))
+	email.Attachments = parts.attachments
+
+	if inner := parts.forwarded; inner != nil && inner.From != "" {
+		inner.ForwardedBy = email.From
+		inner.Attachments = append(inner.Attachments, email.Attachments...)
+		if len(inner.Attachments) > maxAttachments {
+			inner.Attachments = inner.Attachments[:maxAttachments]
+		}
+		return inner, nil
+	}
+
+	applyInlineForward(email)
+	return email, nil
+}
+
+func walkPart(header textproto.MIMEHeader, body io.Reader, parts *parsedParts, depth int) error {
+	if depth > maxPartDepth {
+		return errors.New("invalid email: too many nested parts")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				// Bagian rusak di akhir email tidak membatalkan bagian yang sudah terbaca
+				return nil
+			}
+			if err := walkPart(part.Header, part, parts, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+	if err != nil {
+		return nil
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if filename != "" {
+		filename = decodeHeader(&mime.WordDecoder{CharsetReader: charsetReader}, filename)
+	}
+
+	switch {
+	case mediaType == "message/rfc822":
+		if parts.forwarded == nil {
+			if inner, err := Parse(content); err == nil {
+				parts.forwarded = inner
+			}
+		}
+	case disposition != "attachment" && filename == "" && mediaType == "text/plain":
+		parts.plain = append(parts.plain, toUTF8(content, params["charset"]))
+	case disposition != "attachment" && filename == "" && mediaType == "text/html":
+		parts.html = append(parts.html, htmlToText(toUTF8(content, params["charset"])))
+	case len(content) > 0 && len(parts.attachments) < maxAttachments && (filename != "" || disposition == "attachment"):
+		parts.attachments = append(parts.attachments, File{Filename: filename, ContentType: mediaType, Data: content})
+	}
+
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		// Spasi dan baris baru di tengah base64 dibuang dulu
+		cleaned := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		}, string(raw))
+		if data, err := base64.StdEncoding.DecodeString(cleaned); err == nil {
+			return data, nil
+		}
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(cleaned, "="))
+	case "quoted-printable":
+		return io.ReadAll(quotedprintable.NewReader(body))
+	default:
+		return io.ReadAll(body)
+	}
+}
+
+func decodeHeader(decoder *mime.WordDecoder, value string) string {
+	if decoded, err := decoder.DecodeHeader(value); err == nil {
+		return strings.TrimSpace(decoded)
+	}
+	return strings.TrimSpace(value)
+}
+
+// charsetReader mendukung UTF-8 dan Latin-1/Windows-1252, yang dipakai hampir semua email struk.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(toUTF8(data, charset)), nil
+}
+
+func toUTF8(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	if !utf8.Valid(data) {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	return string(data)
+}
+
+var (
+	scriptBlock = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	styleBlock  = regexp.MustCompile(`(?is)<style\b.*?</style\s*>`)
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	lineBreak   = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|tr|li|h[1-6]|table|ul|ol)\s*>`)
+	cellBreak   = regexp.MustCompile(`(?i)</t[dh]\s*>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaces      = regexp.MustCompile(`[ \t\x{00a0}]+`)
+)
+
+// htmlToText mengubah HTML struk menjadi teks per baris. Sel tabel dalam satu baris tetap
+// satu baris supaya label dan nominalnya berdampingan.
+func htmlToText(s string) string {
+	s = scriptBlock.ReplaceAllString(s, "")
+	s = styleBlock.ReplaceAllString(s, "")
+	s = htmlComment.ReplaceAllString(s, "")
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	s = lineBreak.ReplaceAllString(s, "\n")
+	s = cellBreak.ReplaceAllString(s, " ")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(spaces.ReplaceAllString(line, " ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	forwardMarker = regexp.MustCompile(`(?i)-+\s*(?:forwarded message|original message|pesan yang diteruskan|pesan asli)\s*-+`)
+	forwardField  = regexp.MustCompile(`(?im)^\s*\*?(from|dari|date|tanggal|sent|dikirim|subject|subjek)\*?\s*:\s*(.+?)\s*$`)
+)
+
+// applyInlineForward mengambil pengirim, tanggal dan subject asli dari email yang diteruskan
+// langsung di badan email (bukan sebagai lampiran), supaya template pengirim asli yang dipakai.
+func applyInlineForward(email *Email) {
+	loc := forwardMarker.FindStringIndex(email.Text)
+	if loc == nil {
+		return
+	}
+
+	block := email.Text[loc[1]:]
+	if len(block) > 1000 {
+		block = block[:1000]
+	}
+
+	var from *mail.Address
+	var date time.Time
+	var floating bool
+	var subject string
+	for _, m := range forwardField.FindAllStringSubmatch(block, 6) {
+		value := m[2]
+		switch strings.ToLower(m[1]) {
+		case "from", "dari":
+			if from == nil {
+				from, _ = mail.ParseAddress(value)
+			}
+		case "date", "tanggal", "sent", "dikirim":
+			if date.IsZero() {
+				date, floating, _ = parseForwardDate(value)
+			}
+		case "subject", "subjek":
+			if subject == "" {
+				subject = value
+			}
+		}
+	}
+	if from == nil {
+		return
+	}
+
+	email.ForwardedBy = email.From
+	email.From, email.FromName = strings.ToLower(from.Address), from.Name
+	if !date.IsZero() {
+		email.Date, email.floatingDate = date, floating
+	}
+	if subject != "" {
+		email.Subject = subject
+	}
+}
+
+var forwardDateLayouts = []string{
+	"Mon, Jan 2, 2006 at 3:04 PM",
+	"Mon, 2 Jan 2006 at 15:04",
+	"Monday, January 2, 2006 3:04 PM",
+	"Monday, 2 January 2006 15:04",
+	"2 Jan 2006 15:04",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+// parseForwardDate mengembalikan floating true untuk format tanpa zona waktu.
+func parseForwardDate(value string) (date time.Time, floating bool, err error) {
+	if date, err := mail.ParseDate(value); err == nil {
+		return date, false, nil
+	}
+	value = strings.ReplaceAll(value, "\u00a0", " ")
+	for _, layout := range forwardDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true, nil
+		}
+	}
+	return time.Time{}, false, errors.New("unknown date format")
+}
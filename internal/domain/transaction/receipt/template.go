@@ -0,0 +1,251 @@
+package receipt
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/importer"
+)
+
+// Template menjelaskan cara membaca struk dari satu pengirim.
+type Template struct {
+	// Disimpan di draft untuk menandai template yang dipakai
+	Name     string
+	Merchant string
+	// Domain alamat pengirim; subdomain ikut cocok
+	Domains []string
+	// Label nominal total, yang lebih dulu lebih diutamakan. Label harus berdiri sebagai
+	// kata utuh, sehingga "Total" tidak cocok dengan "Subtotal".
+	TotalLabels []string
+}
+
+var templates = []Template{
+	{Name: "tokopedia", Merchant: "Tokopedia", Domains: []string{"tokopedia.com"},
+		TotalLabels: []string{"Total Tagihan", "Total Pembayaran", "Total Bayar", "Total Belanja"}},
+	{Name: "shopee", Merchant: "Shopee", Domains: []string{"shopee.co.id", "shopee.com"},
+		TotalLabels: []string{"Total Pembayaran", "Total Pesanan", "Total"}},
+	{Name: "gojek", Merchant: "Gojek", Domains: []string{"gojek.com", "gojek.co.id"},
+		TotalLabels: []string{"Total Bayar", "Total Pembayaran", "Total Price", "Total"}},
+	{Name: "grab", Merchant: "Grab", Domains: []string{"grab.com"},
+		TotalLabels: []string{"Total Bayar", "Total Paid", "Jumlah yang Dibayar", "Total"}},
+	{Name: "traveloka", Merchant: "Traveloka", Domains: []string{"traveloka.com"},
+		TotalLabels: []string{"Total Pembayaran", "Total Harga", "Total Price", "Total"}},
+	{Name: "bukalapak", Merchant: "Bukalapak", Domains: []string{"bukalapak.com"},
+		TotalLabels: []string{"Total Pembayaran", "Total Tagihan", "Total"}},
+	{Name: "lazada", Merchant: "Lazada", Domains: []string{"lazada.co.id", "lazada.com"},
+		TotalLabels: []string{"Total Pembayaran", "Grand Total", "Total"}},
+	{Name: "blibli", Merchant: "Blibli", Domains: []string{"blibli.com"},
+		TotalLabels: []string{"Total Pembayaran", "Total Belanja", "Total"}},
+}
+
+// Dipakai untuk pengirim yang tidak punya template; merchant diambil dari nama pengirim
+var genericTemplate = Template{
+	Name: "generic",
+	TotalLabels: []string{
+		"Grand Total", "Total Pembayaran", "Total Bayar", "Total Tagihan", "Jumlah yang Dibayar",
+		"Amount Paid", "Total Paid", "Order Total", "Total Amount", "Total", "Jumlah", "Amount",
+	},
+}
+
+// Receipt adalah hasil pembacaan satu email struk.
+type Receipt struct {
+	Template string
+	Merchant string
+	// 0 jika total tidak ditemukan
+	Amount float64
+	// income untuk email pengembalian dana, selain itu expense
+	TransactionType string
+	// Format 2006-01-02
+	Date string
+	Note string
+}
+
+var refundWords = []string{"refund", "pengembalian dana", "dana dikembalikan", "dana telah dikembalikan"}
+
+// Extract membaca struk dari email memakai template pengirimnya. loc dipakai untuk tanggal
+// email; today dipakai jika email tidak punya tanggal.
+func Extract(email *Email, loc *time.Location, today time.Time) Receipt {
+	tmpl := templateFor(email.From)
+
+	receipt := Receipt{
+		Template:        tmpl.Name,
+		Merchant:        tmpl.Merchant,
+		TransactionType: "expense",
+		Date:            today.In(loc).Format("2006-01-02"),
+		Note:            truncate(cleanSubject(email.Subject), 200),
+	}
+	if receipt.Merchant == "" {
+		receipt.Merchant = senderName(email)
+	}
+	switch {
+	case email.floatingDate:
+		receipt.Date = email.Date.Format("2006-01-02")
+	case !email.Date.IsZero():
+		receipt.Date = email.Date.In(loc).Format("2006-01-02")
+	}
+
+	for _, label := range tmpl.TotalLabels {
+		if amount, ok := findTotal(email.Text, label); ok {
+			receipt.Amount = amount
+			break
+		}
+	}
+
+	subject := strings.ToLower(email.Subject)
+	for _, word := range refundWords {
+		if strings.Contains(subject, word) {
+			receipt.TransactionType = "income"
+			break
+		}
+	}
+
+	return receipt
+}
+
+// Templates mengembalikan daftar template pengirim yang dikenali.
+func Templates() []Template {
+	return append([]Template{}, templates...)
+}
+
+func templateFor(from string) Template {
+	at := strings.LastIndex(from, "@")
+	if at < 0 {
+		return genericTemplate
+	}
+	domain := from[at+1:]
+
+	for _, tmpl := range templates {
+		for _, d := range tmpl.Domains {
+			if domain == d || strings.HasSuffix(domain, "."+d) {
+				return tmpl
+			}
+		}
+	}
+	return genericTemplate
+}
+
+var (
+	currencyAmount = regexp.MustCompile(`(?i)(?:rp\.?|idr|usd|\$)\s*([0-9][0-9.,]*[0-9]|[0-9])`)
+	// Angka dengan pemisah ribuan atau desimal lebih meyakinkan daripada angka polos
+	formattedAmount = regexp.MustCompile(`\b[0-9]{1,3}(?:[.,][0-9]{3})+(?:[.,][0-9]{1,2})?\b|\b[0-9]+[.,][0-9]{1,2}\b`)
+	plainAmount     = regexp.MustCompile(`\b[0-9]+\b`)
+)
+
+// findTotal mencari nominal setelah label, paling jauh sampai baris berikutnya karena di
+// sebagian email teks nominal ditulis di bawah labelnya.
+func findTotal(text, label string) (float64, bool) {
+	lowerText, lowerLabel := strings.ToLower(text), strings.ToLower(label)
+
+	for offset := 0; offset < len(lowerText); {
+		i := strings.Index(lowerText[offset:], lowerLabel)
+		if i < 0 {
+			return 0, false
+		}
+		start, end := offset+i, offset+i+len(lowerLabel)
+		offset = end
+
+		if !wordBoundary(lowerText, start, end) {
+			continue
+		}
+
+		window := lowerText[end:]
+		if n := nthIndex(window, "\n", 2); n >= 0 {
+			window = window[:n]
+		}
+		if len(window) > 120 {
+			window = window[:120]
+		}
+
+		var raw string
+		if m := currencyAmount.FindStringSubmatch(window); m != nil {
+			raw = m[1]
+		} else if m := formattedAmount.FindString(window); m != "" {
+			raw = m
+		} else if m := plainAmount.FindString(window); m != "" {
+			raw = m
+		}
+		if raw == "" {
+			continue
+		}
+
+		amount, err := importer.ParseAmount(raw)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		return math.Round(amount*100) / 100, true
+	}
+
+	return 0, false
+}
+
+func wordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); unicode.IsLetter(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func nthIndex(s, sep string, n int) int {
+	offset := 0
+	for k := 0; k < n; k++ {
+		i := strings.Index(s[offset:], sep)
+		if i < 0 {
+			return -1
+		}
+		if k == n-1 {
+			return offset + i
+		}
+		offset += i + len(sep)
+	}
+	return -1
+}
+
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*(?:(?:fwd?|fw|re|tr|wg|balas|terusan)\s*:\s*)+`)
+
+func cleanSubject(subject string) string {
+	return strings.TrimSpace(subjectPrefix.ReplaceAllString(subject, ""))
+}
+
+// senderName memakai nama tampilan pengirim, atau nama domain jika kosong ("halo@kopikenangan.id"
+// menjadi "Kopikenangan").
+func senderName(email *Email) string {
+	if name := strings.TrimSpace(email.FromName); name != "" {
+		return truncate(name, 100)
+	}
+
+	at := strings.LastIndex(email.From, "@")
+	if at < 0 {
+		return ""
+	}
+	labels := strings.Split(email.From[at+1:], ".")
+	name := labels[0]
+	// "mail.tokoku.co.id" memakai label sebelum domain tingkat atas
+	for i := len(labels) - 1; i >= 0; i-- {
+		if l := labels[i]; len(l) > 3 && l != "mail" && l != "email" && l != "noreply" {
+			name = l
+			break
+		}
+	}
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func truncate(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
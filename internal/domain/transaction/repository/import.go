@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	categoryEntity "github.com/kenziehh/cashflow-be/internal/domain/category/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+// errAlreadyImported dikembalikan insertTransaction jika transaksi dengan external ID yang
+// sama sudah ada, misalnya karena file yang sama diimport bersamaan.
+var errAlreadyImported = errors.New("transaction already imported")
+
+func (r *transactionRepository) GetCategories(ctx context.Context) ([]*categoryEntity.Category, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM categories`)
+	if err != nil {
+		log.Printf("[DB ERROR] GetCategories failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var categories []*categoryEntity.Category
+	for rows.Next() {
+		c := &categoryEntity.Category{}
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return categories, nil
+}
+
+func (r *transactionRepository) GetCategoryMappings(ctx context.Context, userID uuid.UUID, source string) ([]*entity.CategoryMapping, error) {
+	query := `
+		SELECT m.source, m.source_category, m.category_id, c.name, m.created_at, m.updated_at
+		FROM import_category_mappings m
+		JOIN categories c ON c.id = m.category_id
+		WHERE m.user_id = $1 AND ($2::TEXT = '' OR m.source = $2)
+		ORDER BY m.source, m.source_category
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, source)
+	if err != nil {
+		log.Printf("[DB ERROR] GetCategoryMappings failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	mappings := []*entity.CategoryMapping{}
+	for rows.Next() {
+		m := &entity.CategoryMapping{}
+		if err := rows.Scan(&m.Source, &m.SourceCategory, &m.CategoryID, &m.CategoryName, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		mappings = append(mappings, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return mappings, nil
+}
+
+func (r *transactionRepository) SaveCategoryMapping(ctx context.Context, userID uuid.UUID, mapping *entity.CategoryMapping) error {
+	err := r.db.QueryRowContext(ctx, `SELECT name FROM categories WHERE id = $1`, mapping.CategoryID).Scan(&mapping.CategoryName)
+	if err == sql.ErrNoRows {
+		return errx.ErrCategoryNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] SaveCategoryMapping failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	query := `
+		INSERT INTO import_category_mappings (user_id, source, source_category, category_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, source, source_category) DO UPDATE
+		SET category_id = EXCLUDED.category_id, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query, userID, mapping.Source, mapping.SourceCategory, mapping.CategoryID).
+		Scan(&mapping.CreatedAt, &mapping.UpdatedAt)
+	if err != nil {
+		log.Printf("[DB ERROR] SaveCategoryMapping failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) DeleteCategoryMapping(ctx context.Context, userID uuid.UUID, source, sourceCategory string) error {
+	query := `DELETE FROM import_category_mappings WHERE user_id = $1 AND source = $2 AND source_category = $3`
+
+	res, err := r.db.ExecContext(ctx, query, userID, source, sourceCategory)
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteCategoryMapping failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrCategoryMappingNotFound
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) GetImportedExternalIDs(ctx context.Context, userID uuid.UUID, externalIDs []string) (map[string]bool, error) {
+	imported := map[string]bool{}
+	if len(externalIDs) == 0 {
+		return imported, nil
+	}
+
+	query := `SELECT external_id FROM transactions WHERE user_id = $1 AND external_id = ANY($2)`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(externalIDs))
+	if err != nil {
+		log.Printf("[DB ERROR] GetImportedExternalIDs failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		imported[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return imported, nil
+}
+
+// ImportTransactions menyimpan semua transaksi dalam satu transaksi database dan
+// mengembalikan transaksi yang benar-benar tersimpan. Transaksi yang external ID-nya sudah
+// ada dilewati tanpa membatalkan yang lain.
+func (r *transactionRepository) ImportTransactions(ctx context.Context, txs []*entity.Transaction) ([]*entity.Transaction, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	created := make([]*entity.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		err := r.insertTransaction(ctx, dbTx, tx)
+		if err == errAlreadyImported {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, tx)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return created, nil
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+type ReceiptRepository interface {
+	CreateReceiptDraft(ctx context.Context, draft *entity.ReceiptDraft) error
+	GetReceiptDraft(ctx context.Context, id uuid.UUID) (*entity.ReceiptDraft, error)
+	GetReceiptDrafts(ctx context.Context, userID uuid.UUID, status string) ([]*entity.ReceiptDraft, error)
+	ApproveReceiptDraft(ctx context.Context, draftID uuid.UUID, tx *entity.Transaction) error
+	// RejectReceiptDraft mengembalikan storage key file draft yang perlu dibersihkan
+	RejectReceiptDraft(ctx context.Context, draftID uuid.UUID) ([]string, error)
+}
+
+type receiptRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+	// Draft yang disetujui disimpan sebagai transaksi biasa beserta lampirannya
+	transactions *transactionRepository
+}
+
+func NewReceiptRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) ReceiptRepository {
+	return &receiptRepository{
+		db:           db,
+		redis:        redis,
+		keyring:      keyring,
+		transactions: &transactionRepository{db: db, redis: redis, keyring: keyring},
+	}
+}
+
+const receiptDraftColumns = `id, user_id, source, template, message_key, sender, merchant, type, amount,
+	TO_CHAR(date, 'YYYY-MM-DD'), COALESCE(category_id, ''), note, status, transaction_id, created_at, updated_at`
+
+// CreateReceiptDraft menyimpan draft beserta file email dan lampirannya dalam satu transaksi
+// database. Email dengan message key yang sama dengan draft lain ditolak.
+func (r *receiptRepository) CreateReceiptDraft(ctx context.Context, draft *entity.ReceiptDraft) error {
+	note, err := r.transactions.sealNote(ctx, draft.UserID, draft.Note)
+	if err != nil {
+		return err
+	}
+
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO receipt_drafts (id, user_id, source, template, message_key, sender, merchant, type, amount,
+			date, category_id, note, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13, $14, $15)
+	`,
+		draft.ID, draft.UserID, draft.Source, draft.Template, draft.MessageKey, draft.Sender, draft.Merchant,
+		draft.TransactionType, draft.Amount, draft.Date, draft.CategoryID, note, draft.Status,
+		draft.CreatedAt, draft.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errx.ErrEmailAlreadyReceived
+		}
+		log.Printf("[DB ERROR] CreateReceiptDraft failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	for _, file := range draft.Files {
+		_, err := dbTx.ExecContext(ctx, `
+			INSERT INTO receipt_files (id, draft_id, kind, filename, content_type, size, storage_key, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, file.ID, draft.ID, file.Kind, file.Filename, file.ContentType, file.Size, file.StorageKey, file.CreatedAt)
+		if err != nil {
+			log.Printf("[DB ERROR] CreateReceiptDraft failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *receiptRepository) GetReceiptDraft(ctx context.Context, id uuid.UUID) (*entity.ReceiptDraft, error) {
+	query := `SELECT ` + receiptDraftColumns + ` FROM receipt_drafts WHERE id = $1`
+
+	draft, err := r.readReceiptDraft(ctx, r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errx.ErrReceiptDraftNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetReceiptDraft failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	if err := r.attachReceiptFiles(ctx, []*entity.ReceiptDraft{draft}); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// GetReceiptDrafts mengambil draft user dengan status tertentu, yang terbaru lebih dulu.
+func (r *receiptRepository) GetReceiptDrafts(ctx context.Context, userID uuid.UUID, status string) ([]*entity.ReceiptDraft, error) {
+	query := `
+		SELECT ` + receiptDraftColumns + `
+		FROM receipt_drafts
+		WHERE user_id = $1 AND status = $2
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, status)
+	if err != nil {
+		log.Printf("[DB ERROR] GetReceiptDrafts failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	drafts := []*entity.ReceiptDraft{}
+	for rows.Next() {
+		draft, err := r.readReceiptDraft(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		drafts = append(drafts, draft)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	if err := r.attachReceiptFiles(ctx, drafts); err != nil {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+// ApproveReceiptDraft menyimpan transaksi hasil draft beserta lampirannya, lalu menandai draft
+// disetujui. File draft berpindah menjadi lampiran transaksi dengan storage key yang sama.
+func (r *receiptRepository) ApproveReceiptDraft(ctx context.Context, draftID uuid.UUID, tx *entity.Transaction) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Status dikunci lebih dulu supaya dua persetujuan bersamaan tidak membuat dua transaksi
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE receipt_drafts SET status = 'approved', updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, draftID)
+	if err != nil {
+		log.Printf("[DB ERROR] ApproveReceiptDraft failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrReceiptAlreadyReviewed
+	}
+
+	if err := r.transactions.insertTransaction(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	for _, attachment := range tx.Attachments {
+		if err := insertAttachment(ctx, dbTx, attachment); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dbTx.ExecContext(ctx, `UPDATE receipt_drafts SET transaction_id = $1 WHERE id = $2`, tx.ID, draftID); err != nil {
+		log.Printf("[DB ERROR] ApproveReceiptDraft failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	if _, err := dbTx.ExecContext(ctx, `DELETE FROM receipt_files WHERE draft_id = $1`, draftID); err != nil {
+		log.Printf("[DB ERROR] ApproveReceiptDraft failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// RejectReceiptDraft menandai draft ditolak dan melepas filenya. Barisnya tetap disimpan supaya
+// email yang sama tidak masuk lagi. Storage key file dikembalikan untuk dibersihkan.
+func (r *receiptRepository) RejectReceiptDraft(ctx context.Context, draftID uuid.UUID) ([]string, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	res, err := dbTx.ExecContext(ctx, `
+		UPDATE receipt_drafts SET status = 'rejected', updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, draftID)
+	if err != nil {
+		log.Printf("[DB ERROR] RejectReceiptDraft failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, errx.ErrReceiptAlreadyReviewed
+	}
+
+	rows, err := dbTx.QueryContext(ctx, `DELETE FROM receipt_files WHERE draft_id = $1 RETURNING storage_key`, draftID)
+	if err != nil {
+		log.Printf("[DB ERROR] RejectReceiptDraft failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, errx.ErrDatabaseError
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return keys, nil
+}
+
+func (r *receiptRepository) attachReceiptFiles(ctx context.Context, drafts []*entity.ReceiptDraft) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+
+	byID := map[uuid.UUID]*entity.ReceiptDraft{}
+	ids := make([]uuid.UUID, 0, len(drafts))
+	for _, draft := range drafts {
+		draft.Files = []*entity.ReceiptFile{}
+		byID[draft.ID] = draft
+		ids = append(ids, draft.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, draft_id, kind, filename, content_type, size, storage_key, created_at
+		FROM receipt_files
+		WHERE draft_id = ANY($1::UUID[])
+		ORDER BY kind DESC, created_at
+	`, pq.Array(ids))
+	if err != nil {
+		log.Printf("[DB ERROR] GetReceiptFiles failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f := &entity.ReceiptFile{}
+		if err := rows.Scan(&f.ID, &f.DraftID, &f.Kind, &f.Filename, &f.ContentType, &f.Size, &f.StorageKey, &f.CreatedAt); err != nil {
+			return errx.ErrDatabaseError
+		}
+		byID[f.DraftID].Files = append(byID[f.DraftID].Files, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *receiptRepository) readReceiptDraft(ctx context.Context, row rowScanner) (*entity.ReceiptDraft, error) {
+	draft := &entity.ReceiptDraft{}
+	var amount sql.NullFloat64
+	err := row.Scan(
+		&draft.ID, &draft.UserID, &draft.Source, &draft.Template, &draft.MessageKey, &draft.Sender, &draft.Merchant,
+		&draft.TransactionType, &amount, &draft.Date, &draft.CategoryID, &draft.Note, &draft.Status,
+		&draft.TransactionID, &draft.CreatedAt, &draft.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Valid {
+		draft.Amount = &amount.Float64
+	}
+
+	if draft.Note, err = r.transactions.openNote(ctx, draft.UserID, draft.Note); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
+)
+
+type ReconciliationRepository interface {
+	CreateReconciliation(ctx context.Context, rec *entity.Reconciliation, lines []*entity.StatementLine) error
+	GetReconciliation(ctx context.Context, id uuid.UUID) (*entity.Reconciliation, error)
+	GetReconciliations(ctx context.Context, userID uuid.UUID) ([]*entity.Reconciliation, error)
+	GetStatementLines(ctx context.Context, rec *entity.Reconciliation) ([]*entity.StatementLine, error)
+	GetLedger(ctx context.Context, userID, reconciliationID uuid.UUID, startDate, endDate string) ([]*entity.Transaction, error)
+	ConfirmStatementMatches(ctx context.Context, reconciliationID uuid.UUID, matches map[uuid.UUID]uuid.UUID) error
+	UnmatchStatementLine(ctx context.Context, reconciliationID, lineID uuid.UUID) error
+	CreateFromStatement(ctx context.Context, reconciliationID uuid.UUID, txs map[uuid.UUID]*entity.Transaction) error
+	// SetReconciliationLock mengunci periode rekonsiliasi, lockedAt nil membuka kuncinya
+	SetReconciliationLock(ctx context.Context, id uuid.UUID, lockedAt *time.Time) error
+	DeleteReconciliation(ctx context.Context, id uuid.UUID) error
+}
+
+type reconciliationRepository struct {
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
+	// Baris transaksi dari dan ke rekening koran dibaca dan disimpan seperti transaksi biasa
+	transactions *transactionRepository
+}
+
+func NewReconciliationRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) ReconciliationRepository {
+	return &reconciliationRepository{
+		db:           db,
+		redis:        redis,
+		keyring:      keyring,
+		transactions: &transactionRepository{db: db, redis: redis, keyring: keyring},
+	}
+}
+
+const reconciliationColumns = `id, user_id, account, format, TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD'), locked_at, created_at, updated_at`
+
+// CreateReconciliation menyimpan rekonsiliasi beserta semua baris rekening korannya dalam satu
+// transaksi database.
+func (r *reconciliationRepository) CreateReconciliation(ctx context.Context, rec *entity.Reconciliation, lines []*entity.StatementLine) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO reconciliations (id, user_id, account, format, start_date, end_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, rec.ID, rec.UserID, rec.Account, rec.Format, rec.StartDate, rec.EndDate, rec.CreatedAt, rec.UpdatedAt)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateReconciliation failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, `
+		INSERT INTO reconciliation_lines (id, reconciliation_id, line, external_id, date, amount, note, category)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateReconciliation failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+	defer stmt.Close()
+
+	for _, line := range lines {
+		// Deskripsi rekening koran sama sensitifnya dengan note transaksi
+		note, err := r.transactions.sealNote(ctx, rec.UserID, line.Note)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			line.ID, rec.ID, line.Line, line.ExternalID, line.Date, line.Amount, note, line.Category,
+		); err != nil {
+			log.Printf("[DB ERROR] CreateReconciliation failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) GetReconciliation(ctx context.Context, id uuid.UUID) (*entity.Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + ` FROM reconciliations WHERE id = $1`
+
+	rec, err := scanReconciliation(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errx.ErrReconciliationNotFound
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] GetReconciliation failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return rec, nil
+}
+
+func (r *reconciliationRepository) GetReconciliations(ctx context.Context, userID uuid.UUID) ([]*entity.Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + ` FROM reconciliations WHERE user_id = $1 ORDER BY start_date DESC, created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetReconciliations failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	reconciliations := []*entity.Reconciliation{}
+	for rows.Next() {
+		rec, err := scanReconciliation(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		reconciliations = append(reconciliations, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return reconciliations, nil
+}
+
+func (r *reconciliationRepository) GetStatementLines(ctx context.Context, rec *entity.Reconciliation) ([]*entity.StatementLine, error) {
+	query := `
+		SELECT id, reconciliation_id, line, external_id, TO_CHAR(date, 'YYYY-MM-DD'), amount, note, category,
+			transaction_id, confirmed_at
+		FROM reconciliation_lines
+		WHERE reconciliation_id = $1
+		ORDER BY line
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, rec.ID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetStatementLines failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	lines := []*entity.StatementLine{}
+	for rows.Next() {
+		line := &entity.StatementLine{}
+		if err := rows.Scan(
+			&line.ID, &line.ReconciliationID, &line.Line, &line.ExternalID, &line.Date, &line.Amount, &line.Note,
+			&line.Category, &line.TransactionID, &line.ConfirmedAt,
+		); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		if line.Note, err = r.transactions.openNote(ctx, rec.UserID, line.Note); err != nil {
+			return nil, errx.ErrInternalServer
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return lines, nil
+}
+
+// GetLedger mengambil transaksi aktif user di antara startDate dan endDate yang belum menjadi
+// pasangan baris rekening koran di rekonsiliasi lain.
+func (r *reconciliationRepository) GetLedger(ctx context.Context, userID, reconciliationID uuid.UUID, startDate, endDate string) ([]*entity.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+			AND date BETWEEN $2 AND $3
+			AND NOT EXISTS (
+				SELECT 1 FROM reconciliation_lines l
+				WHERE l.transaction_id = t.id AND l.reconciliation_id <> $4
+			)
+		ORDER BY date, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, reconciliationID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetLedger failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	transactions := []*entity.Transaction{}
+	for rows.Next() {
+		tx, err := r.transactions.readTransaction(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return transactions, nil
+}
+
+// ConfirmStatementMatches menyimpan pasangan baris rekening koran (key) dan transaksi (value).
+func (r *reconciliationRepository) ConfirmStatementMatches(ctx context.Context, reconciliationID uuid.UUID, matches map[uuid.UUID]uuid.UUID) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	lineIDs := make([]uuid.UUID, 0, len(matches))
+	for lineID := range matches {
+		lineIDs = append(lineIDs, lineID)
+	}
+
+	// Pasangan lama dilepas dulu supaya dua baris bisa bertukar transaksi tanpa melanggar unique index
+	if _, err := dbTx.ExecContext(ctx, `
+		UPDATE reconciliation_lines SET transaction_id = NULL, confirmed_at = NULL
+		WHERE reconciliation_id = $1 AND id = ANY($2::UUID[])
+	`, reconciliationID, pq.Array(lineIDs)); err != nil {
+		log.Printf("[DB ERROR] ConfirmStatementMatches failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	for lineID, transactionID := range matches {
+		if err := linkStatementLine(ctx, dbTx, reconciliationID, lineID, transactionID); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) UnmatchStatementLine(ctx context.Context, reconciliationID, lineID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reconciliation_lines SET transaction_id = NULL, confirmed_at = NULL
+		WHERE reconciliation_id = $1 AND id = $2
+	`, reconciliationID, lineID)
+	if err != nil {
+		log.Printf("[DB ERROR] UnmatchStatementLine failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrStatementLineNotFound
+	}
+
+	return nil
+}
+
+// CreateFromStatement menyimpan transaksi baru untuk baris rekening koran (key) lalu langsung
+// memasangkannya, dalam satu transaksi database.
+func (r *reconciliationRepository) CreateFromStatement(ctx context.Context, reconciliationID uuid.UUID, txs map[uuid.UUID]*entity.Transaction) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	for lineID, tx := range txs {
+		if err := r.transactions.insertTransaction(ctx, dbTx, tx); err != nil {
+			if err == errAlreadyImported {
+				return errx.NewConflictError("A transaction with the same external ID was already imported")
+			}
+			return err
+		}
+		if err := linkStatementLine(ctx, dbTx, reconciliationID, lineID, tx.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) SetReconciliationLock(ctx context.Context, id uuid.UUID, lockedAt *time.Time) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE reconciliations SET locked_at = $1, updated_at = NOW() WHERE id = $2`, lockedAt, id,
+	); err != nil {
+		log.Printf("[DB ERROR] SetReconciliationLock failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *reconciliationRepository) DeleteReconciliation(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM reconciliations WHERE id = $1`, id); err != nil {
+		log.Printf("[DB ERROR] DeleteReconciliation failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func linkStatementLine(ctx context.Context, q dbExecutor, reconciliationID, lineID, transactionID uuid.UUID) error {
+	res, err := q.ExecContext(ctx, `
+		UPDATE reconciliation_lines SET transaction_id = $1, confirmed_at = NOW()
+		WHERE reconciliation_id = $2 AND id = $3
+	`, transactionID, reconciliationID, lineID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errx.NewConflictError("Transaction is already matched to another statement line")
+		}
+		log.Printf("[DB ERROR] linkStatementLine failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrStatementLineNotFound
+	}
+
+	return nil
+}
+
+func scanReconciliation(row rowScanner) (*entity.Reconciliation, error) {
+	rec := &entity.Reconciliation{}
+	err := row.Scan(
+		&rec.ID, &rec.UserID, &rec.Account, &rec.Format, &rec.StartDate, &rec.EndDate,
+		&rec.LockedAt, &rec.CreatedAt, &rec.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -3,59 +3,105 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	categoryEntity "github.com/kenziehh/cashflow-be/internal/domain/category/entity"
+	maximumSpendEntity "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/entity"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
 	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/lib/pq"
 )
 
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, tx *entity.Transaction) error
 	GetTransactionByID(ctx context.Context, id string) (*entity.Transaction, error)
-	UpdateTransaction(ctx context.Context, tx *entity.Transaction) error
-	DeleteTransaction(ctx context.Context, id string) error
+	UpdateTransaction(ctx context.Context, tx *entity.Transaction, revision *entity.Revision) error
+	DeleteTransaction(ctx context.Context, id string, version int) error
 	GetTransactionsWithPagination(ctx context.Context, userID uuid.UUID, filter dto.TransactionListParams) (dto.PaginatedTransactionsResponse, error)
 	GetSummaryTransaction(ctx context.Context, userID uuid.UUID) (dto.SummaryTransactionResponse, error)
+	FindDuplicateCandidates(ctx context.Context, tx *entity.Transaction, days int) ([]*entity.Transaction, error)
+	FindDuplicatePairs(ctx context.Context, userID uuid.UUID, params dto.DuplicateScanParams) ([][2]uuid.UUID, error)
+	GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Transaction, error)
+	MergeTransactions(ctx context.Context, keep *entity.Transaction, revision *entity.Revision, duplicate *entity.Transaction) error
+	GetTrashedTransactions(ctx context.Context, userID uuid.UUID) ([]*entity.Transaction, error)
+	GetTrashedTransactionByID(ctx context.Context, id uuid.UUID) (*entity.Transaction, error)
+	RestoreTransaction(ctx context.Context, id uuid.UUID) error
+	PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int, []string, error)
+	GetRevisions(ctx context.Context, transactionID uuid.UUID) ([]entity.Revision, error)
+	GetRevision(ctx context.Context, transactionID uuid.UUID, version int) (*entity.Revision, error)
+	ApplyBatch(ctx context.Context, writes []entity.BatchWrite) error
+	GetChangesSince(ctx context.Context, userID uuid.UUID, since entity.SyncCursor, limit int) ([]entity.Change, error)
+	GetAttachments(ctx context.Context, transactionID uuid.UUID) ([]*entity.Attachment, error)
+	GetAttachment(ctx context.Context, id uuid.UUID) (*entity.Attachment, error)
+	CreateAttachment(ctx context.Context, attachment *entity.Attachment) error
+	DeleteAttachment(ctx context.Context, id uuid.UUID) error
+	GetAttachmentUsage(ctx context.Context, userID uuid.UUID) (int64, error)
+	// PinAttachmentFile menahan file key dari penghapusan selama ttl; dipanggil sebelum file ditulis
+	PinAttachmentFile(ctx context.Context, key string, ttl time.Duration) error
+	// ReleaseAttachmentFile memanggil remove jika key tidak lagi dipakai maupun ditahan
+	ReleaseAttachmentFile(ctx context.Context, key string, remove func(ctx context.Context, key string) error) error
+	// GetReleasableAttachmentFiles mengembalikan key yang penghapusannya tertunda dan tahanannya sudah habis
+	GetReleasableAttachmentFiles(ctx context.Context, limit int) ([]string, error)
+	ClaimPendingAttachments(ctx context.Context, limit int) ([]*entity.Attachment, error)
+	SaveAttachmentPreviews(ctx context.Context, attachment *entity.Attachment) error
+	StoreAttachmentLink(ctx context.Context, nonce string, attachmentID uuid.UUID, ttl time.Duration) error
+	// ConsumeAttachmentLink menghapus link sekali pakai dan mengembalikan false jika sudah dipakai atau kedaluwarsa
+	ConsumeAttachmentLink(ctx context.Context, nonce string) (bool, error)
+	GetCategories(ctx context.Context) ([]*categoryEntity.Category, error)
+	// GetCategoryMappings mengembalikan mapping kategori import milik user, source kosong untuk semua sumber
+	GetCategoryMappings(ctx context.Context, userID uuid.UUID, source string) ([]*entity.CategoryMapping, error)
+	SaveCategoryMapping(ctx context.Context, userID uuid.UUID, mapping *entity.CategoryMapping) error
+	DeleteCategoryMapping(ctx context.Context, userID uuid.UUID, source, sourceCategory string) error
+	// GetImportedExternalIDs mengembalikan external ID yang sudah dipakai transaksi user, termasuk yang ada di trash
+	GetImportedExternalIDs(ctx context.Context, userID uuid.UUID, externalIDs []string) (map[string]bool, error)
+	ImportTransactions(ctx context.Context, txs []*entity.Transaction) ([]*entity.Transaction, error)
 }
 
 type transactionRepository struct {
-	db    *sql.DB
-	redis *redis.Client
+	db      *sql.DB
+	redis   *redis.Client
+	keyring *encryption.Keyring
 }
 
-func NewTransactionRepository(db *sql.DB, redis *redis.Client) TransactionRepository {
+func NewTransactionRepository(db *sql.DB, redis *redis.Client, keyring *encryption.Keyring) TransactionRepository {
 	return &transactionRepository{
-		db:    db,
-		redis: redis,
+		db:      db,
+		redis:   redis,
+		keyring: keyring,
 	}
 }
 
+// CreateTransaction menyimpan transaksi beserta lampiran di tx.Attachments dalam satu transaksi database.
 func (r *transactionRepository) CreateTransaction(ctx context.Context, tx *entity.Transaction) error {
-	query := `
-		INSERT INTO transactions (id, user_id, amount, type, category_id, note, period, date, proof_file, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	`
+	if len(tx.Attachments) == 0 {
+		return r.insertTransaction(ctx, r.db, tx)
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
-		tx.ID,
-		tx.UserID,
-		tx.Amount,
-		tx.TransactionType,
-		tx.CategoryID,
-		tx.Note,
-		tx.Period,
-		tx.Date,
-		tx.ProofFile,
-		tx.CreatedAt,
-		tx.UpdatedAt,
-	)
+	dbTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("[DB ERROR]:", err)
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	if err := r.insertTransaction(ctx, dbTx, tx); err != nil {
+		return err
+	}
+	for _, attachment := range tx.Attachments {
+		if err := insertAttachment(ctx, dbTx, attachment); err != nil {
+			return err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
 		return errx.ErrDatabaseError
 	}
 
@@ -63,28 +109,9 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, tx *entit
 }
 
 func (r *transactionRepository) GetTransactionByID(ctx context.Context, id string) (*entity.Transaction, error) {
-	query := `
-		SELECT id, user_id, amount, type, category_id, note, date, proof_file, created_at, updated_at, period
-		FROM transactions
-		WHERE id = $1
-	`
-
-	row := r.db.QueryRowContext(ctx, query, id)
-	tx := &entity.Transaction{}
-	err := row.Scan(
-		&tx.ID,
-		&tx.UserID,
-		&tx.Amount,
-		&tx.TransactionType,
-		&tx.CategoryID,
-		&tx.Note,
-		&tx.Date,
-		&tx.ProofFile,
-		&tx.CreatedAt,
-		&tx.UpdatedAt,
-		&tx.Period,
-	)
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1 AND deleted_at IS NULL`
 
+	tx, err := r.readTransaction(ctx, r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errx.ErrTransactionNotFound
@@ -97,43 +124,82 @@ func (r *transactionRepository) GetTransactionByID(ctx context.Context, id strin
 	return tx, nil
 }
 
-func (r *transactionRepository) UpdateTransaction(ctx context.Context, tx *entity.Transaction) error {
-	query := `
-		UPDATE transactions
-		SET amount = $1, type = $2, category_id = $3, note = $4, date = $5, updated_at = $6, proof_file = $8
-		WHERE id = $7
-	`
+// UpdateTransaction menyimpan perubahan transaksi. Jika revision diisi, kondisi sebelum
+// perubahan dicatat dengan nomor versi berikutnya dalam transaksi database yang sama.
+func (r *transactionRepository) UpdateTransaction(ctx context.Context, tx *entity.Transaction, revision *entity.Revision) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
 
-	_, err := r.db.ExecContext(ctx, query,
-		tx.Amount,
-		tx.TransactionType,
-		tx.CategoryID,
-		tx.Note,
-		tx.Date,
-		tx.UpdatedAt,
-		tx.ID,
-		tx.ProofFile,
-	)
+	if err := r.updateTransaction(ctx, dbTx, tx, revision); err != nil {
+		return err
+	}
 
-	if err != nil {
+	if err := dbTx.Commit(); err != nil {
 		return errx.ErrDatabaseError
 	}
 
+	tx.Version++
 	return nil
 }
 
-func (r *transactionRepository) DeleteTransaction(ctx context.Context, id string) error {
+func (r *transactionRepository) GetRevisions(ctx context.Context, transactionID uuid.UUID) ([]entity.Revision, error) {
 	query := `
-		DELETE FROM transactions
-		WHERE id = $1
+		SELECT t.user_id, r.id, r.transaction_id, r.version, r.changed_by, r.snapshot, r.created_at
+		FROM transaction_revisions r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.transaction_id = $1
+		ORDER BY r.version ASC
 	`
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	rows, err := r.db.QueryContext(ctx, query, transactionID)
 	if err != nil {
-		return errx.ErrDatabaseError
+		log.Printf("[DB ERROR] GetRevisions failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	revisions := []entity.Revision{}
+	for rows.Next() {
+		rev, err := r.readRevision(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		revisions = append(revisions, *rev)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return revisions, nil
+}
+
+func (r *transactionRepository) GetRevision(ctx context.Context, transactionID uuid.UUID, version int) (*entity.Revision, error) {
+	query := `
+		SELECT t.user_id, r.id, r.transaction_id, r.version, r.changed_by, r.snapshot, r.created_at
+		FROM transaction_revisions r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.transaction_id = $1 AND r.version = $2
+	`
+
+	rev, err := r.readRevision(ctx, r.db.QueryRowContext(ctx, query, transactionID, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.NewNotFoundError("Revision not found")
+		}
+		log.Printf("[DB ERROR] GetRevision failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return rev, nil
+}
+
+// DeleteTransaction memindahkan transaksi ke trash, penghapusan permanen dilakukan oleh PurgeTrashedBefore.
+func (r *transactionRepository) DeleteTransaction(ctx context.Context, id string, version int) error {
+	return softDeleteTransaction(ctx, r.db, id, version)
 }
 
 func (r *transactionRepository) GetTransactionsWithPagination(
@@ -145,9 +211,9 @@ func (r *transactionRepository) GetTransactionsWithPagination(
 	// fmt.Println("Filter received in repository:", filter)
 
 	query := `
-		SELECT id, user_id, amount, type, category_id, note, date, created_at, updated_at, proof_file, period
+		SELECT ` + transactionColumns + `
 		FROM transactions
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
 	args := []interface{}{userID}
@@ -203,20 +269,7 @@ func (r *transactionRepository) GetTransactionsWithPagination(
 
 	var transactions []*entity.Transaction
 	for rows.Next() {
-		tx := &entity.Transaction{}
-		err := rows.Scan(
-			&tx.ID,
-			&tx.UserID,
-			&tx.Amount,
-			&tx.TransactionType,
-			&tx.CategoryID,
-			&tx.Note,
-			&tx.Date,
-			&tx.CreatedAt,
-			&tx.UpdatedAt,
-			&tx.ProofFile,
-			&tx.Period,
-		)
+		tx, err := r.readTransaction(ctx, rows)
 		if err != nil {
 			return dto.PaginatedTransactionsResponse{}, errx.ErrDatabaseError
 		}
@@ -228,7 +281,7 @@ func (r *transactionRepository) GetTransactionsWithPagination(
 	}
 
 	var total int
-	countQuery := `SELECT COUNT(*) FROM transactions WHERE user_id = $1`
+	countQuery := `SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND deleted_at IS NULL`
 	err = r.db.QueryRowContext(ctx, countQuery, userID).Scan(&total)
 	if err != nil {
 		return dto.PaginatedTransactionsResponse{}, errx.ErrDatabaseError
@@ -252,7 +305,7 @@ func (r *transactionRepository) GetSummaryTransaction(ctx context.Context, userI
 		COALESCE(SUM(CASE WHEN type = 'income' AND date = CURRENT_DATE THEN amount END), 0) AS total_income_daily,
 		COALESCE(SUM(CASE WHEN type = 'expense' AND date = CURRENT_DATE THEN amount END), 0) AS total_expense_daily
 	FROM transactions
-	WHERE user_id = $1
+	WHERE user_id = $1 AND debt_id IS NULL AND deleted_at IS NULL
 	`
 
 	var summary dto.SummaryTransactionResponse
@@ -270,3 +323,1074 @@ func (r *transactionRepository) GetSummaryTransaction(ctx context.Context, userI
 
 	return summary, nil
 }
+
+// FindDuplicateCandidates mencari transaksi dengan tipe dan nominal sama dalam rentang hari tertentu.
+func (r *transactionRepository) FindDuplicateCandidates(ctx context.Context, tx *entity.Transaction, days int) ([]*entity.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE user_id = $1 AND type = $2 AND amount = $3 AND id <> $4 AND debt_id IS NULL AND deleted_at IS NULL
+			AND date BETWEEN $5::DATE - $6::INT AND $5::DATE + $6::INT
+		ORDER BY date DESC
+		LIMIT 20`
+
+	rows, err := r.db.QueryContext(ctx, query, tx.UserID, tx.TransactionType, tx.Amount, tx.ID, tx.Date, days)
+	if err != nil {
+		log.Printf("[DB ERROR] FindDuplicateCandidates failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var candidates []*entity.Transaction
+	for rows.Next() {
+		t, err := r.readTransaction(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		candidates = append(candidates, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return candidates, nil
+}
+
+func (r *transactionRepository) FindDuplicatePairs(ctx context.Context, userID uuid.UUID, params dto.DuplicateScanParams) ([][2]uuid.UUID, error) {
+	query := `
+		SELECT a.id, b.id
+		FROM transactions a
+		JOIN transactions b ON b.user_id = a.user_id
+			AND a.id < b.id
+			AND b.type = a.type
+			AND b.amount = a.amount
+			AND ABS(a.date - b.date) <= $2
+		WHERE a.user_id = $1
+			AND a.debt_id IS NULL AND b.debt_id IS NULL
+			AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+			AND ($3 = '' OR a.date >= $3::DATE)
+			AND ($4 = '' OR a.date <= $4::DATE)
+		ORDER BY a.date DESC
+		LIMIT 500
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, params.Days, params.StartDate, params.EndDate)
+	if err != nil {
+		log.Printf("[DB ERROR] FindDuplicatePairs failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var pairs [][2]uuid.UUID
+	for rows.Next() {
+		var pair [2]uuid.UUID
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return pairs, nil
+}
+
+func (r *transactionRepository) GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.Transaction, error) {
+	result := map[uuid.UUID]*entity.Transaction{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	strIDs := make([]string, len(ids))
+	for i, id := range ids {
+		strIDs[i] = id.String()
+	}
+
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = ANY($1::UUID[]) AND deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(strIDs))
+	if err != nil {
+		log.Printf("[DB ERROR] GetTransactionsByIDs failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := r.readTransaction(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		result[t.ID] = t
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return result, nil
+}
+
+// MergeTransactions menyimpan hasil gabungan ke transaksi yang dipertahankan, memindahkan
+// lampiran, relasi goal dan bill, serta pasangan rekening koran milik duplikat, lalu memindahkan duplikat ke trash dalam satu transaksi database.
+func (r *transactionRepository) MergeTransactions(ctx context.Context, keep *entity.Transaction, revision *entity.Revision, duplicate *entity.Transaction) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Perubahan transaksi yang dipertahankan dicatat sebagai revisi seperti edit biasa
+	if err := r.updateTransaction(ctx, dbTx, keep, revision); err != nil {
+		return err
+	}
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{
+			`UPDATE transaction_attachments SET transaction_id = $1 WHERE transaction_id = $2`,
+			[]interface{}{keep.ID, duplicate.ID},
+		},
+		{
+			`UPDATE goal_contributions SET transaction_id = $1
+			WHERE transaction_id = $2
+				AND goal_id NOT IN (SELECT goal_id FROM goal_contributions WHERE transaction_id = $1)`,
+			[]interface{}{keep.ID, duplicate.ID},
+		},
+		{
+			`UPDATE bill_payments SET transaction_id = $1
+			WHERE transaction_id = $2
+				AND bill_id NOT IN (SELECT bill_id FROM bill_payments WHERE transaction_id = $1)`,
+			[]interface{}{keep.ID, duplicate.ID},
+		},
+		{
+			// Satu transaksi hanya boleh menjadi pasangan satu baris rekening koran
+			`UPDATE reconciliation_lines SET transaction_id = $1
+			WHERE transaction_id = $2
+				AND NOT EXISTS (SELECT 1 FROM reconciliation_lines WHERE transaction_id = $1)`,
+			[]interface{}{keep.ID, duplicate.ID},
+		},
+	}
+
+	for _, stmt := range statements {
+		if _, err := dbTx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return errx.FromWrite("MergeTransactions", err)
+		}
+	}
+
+	if err := softDeleteTransaction(ctx, dbTx, duplicate.ID.String(), duplicate.Version); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	keep.Version++
+	return nil
+}
+
+func (r *transactionRepository) GetTrashedTransactions(ctx context.Context, userID uuid.UUID) ([]*entity.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetTrashedTransactions failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	transactions := []*entity.Transaction{}
+	for rows.Next() {
+		tx, err := r.readTransaction(ctx, rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return transactions, nil
+}
+
+func (r *transactionRepository) GetTrashedTransactionByID(ctx context.Context, id uuid.UUID) (*entity.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	tx, err := r.readTransaction(ctx, r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrTransactionNotFound
+		}
+		log.Printf("[DB ERROR] GetTrashedTransactionByID failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return tx, nil
+}
+
+func (r *transactionRepository) RestoreTransaction(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE transactions SET deleted_at = NULL, updated_at = NOW(), version = version + 1 WHERE id = $1`, id,
+	); err != nil {
+		return errx.FromWrite("RestoreTransaction", err)
+	}
+
+	return nil
+}
+
+// PurgeTrashedBefore menghapus permanen transaksi yang masuk trash sebelum cutoff beserta
+// lampirannya, lalu mengembalikan jumlah transaksi dan storage key lampiran yang ikut terhapus.
+func (r *transactionRepository) PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int, []string, error) {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Lampiran terhapus lewat ON DELETE CASCADE, jadi key-nya diambil lebih dulu
+	rows, err := dbTx.QueryContext(ctx, `
+		DELETE FROM transaction_attachments
+		WHERE transaction_id IN (
+			SELECT id FROM transactions WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		)
+		RETURNING storage_key, COALESCE(thumbnail_key, ''), COALESCE(medium_key, '')
+	`, cutoff)
+	if err != nil {
+		log.Printf("[DB ERROR] PurgeTrashedBefore failed: %v\n", err)
+		return 0, nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key, thumbnailKey, mediumKey string
+		if err := rows.Scan(&key, &thumbnailKey, &mediumKey); err != nil {
+			return 0, nil, errx.ErrDatabaseError
+		}
+		keys = append(keys, key)
+		if thumbnailKey != "" {
+			keys = append(keys, thumbnailKey)
+		}
+		if mediumKey != "" {
+			keys = append(keys, mediumKey)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, nil, errx.ErrDatabaseError
+	}
+
+	res, err := dbTx.ExecContext(ctx,
+		`DELETE FROM transactions WHERE deleted_at IS NOT NULL AND deleted_at < $1`, cutoff,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] PurgeTrashedBefore failed: %v\n", err)
+		return 0, nil, errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, nil, errx.ErrDatabaseError
+	}
+
+	purged, _ := res.RowsAffected()
+	return int(purged), keys, nil
+}
+
+func (r *transactionRepository) GetAttachments(ctx context.Context, transactionID uuid.UUID) ([]*entity.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + `
+		FROM transaction_attachments
+		WHERE transaction_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		log.Printf("[DB ERROR] GetAttachments failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	attachments := []*entity.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return attachments, nil
+}
+
+func (r *transactionRepository) GetAttachment(ctx context.Context, id uuid.UUID) (*entity.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM transaction_attachments WHERE id = $1`
+
+	attachment, err := scanAttachment(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errx.ErrAttachmentNotFound
+		}
+		log.Printf("[DB ERROR] GetAttachment failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	return attachment, nil
+}
+
+func (r *transactionRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	return insertAttachment(ctx, r.db, attachment)
+}
+
+func (r *transactionRepository) DeleteAttachment(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM transaction_attachments WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("[DB ERROR] DeleteAttachment failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// GetAttachmentUsage menjumlahkan ukuran semua lampiran milik user, termasuk lampiran
+// transaksi yang ada di trash dan file draft struk karena file-nya masih tersimpan.
+func (r *transactionRepository) GetAttachmentUsage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var usage int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(size), 0) FROM transaction_attachments WHERE user_id = $1)
+			+ (SELECT COALESCE(SUM(f.size), 0) FROM receipt_files f
+				JOIN receipt_drafts d ON d.id = f.draft_id WHERE d.user_id = $1)
+	`, userID).Scan(&usage)
+	if err != nil {
+		log.Printf("[DB ERROR] GetAttachmentUsage failed: %v\n", err)
+		return 0, errx.ErrDatabaseError
+	}
+
+	return usage, nil
+}
+
+// PinAttachmentFile menahan key sampai ttl habis. Kunci advisory yang sama dengan
+// ReleaseAttachmentFile memastikan tahanan tercatat sebelum atau sesudah penghapusan, tidak
+// di tengahnya, sehingga file yang ditulis setelah ini tidak ikut terhapus.
+func (r *transactionRepository) PinAttachmentFile(ctx context.Context, key string, ttl time.Duration) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	if _, err := dbTx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+		log.Printf("[DB ERROR] PinAttachmentFile failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	_, err = dbTx.ExecContext(ctx, `
+		INSERT INTO attachment_file_holds (storage_key, pinned_until)
+		VALUES ($1, NOW() + $2 * INTERVAL '1 second')
+		ON CONFLICT (storage_key) DO UPDATE
+		SET pinned_until = GREATEST(attachment_file_holds.pinned_until, EXCLUDED.pinned_until)
+	`, key, ttl.Seconds())
+	if err != nil {
+		log.Printf("[DB ERROR] PinAttachmentFile failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// ReleaseAttachmentFile menghapus file lewat remove jika tidak ada lampiran (termasuk milik
+// transaksi di trash) atau file draft struk yang memakai key, baik sebagai file asli maupun
+// thumbnail/preview. Jika key masih ditahan upload yang sedang berjalan, penghapusan ditunda
+// dan diulang worker setelah tahanannya habis.
+func (r *transactionRepository) ReleaseAttachmentFile(ctx context.Context, key string, remove func(ctx context.Context, key string) error) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Kunci dipegang sampai commit, termasuk selama file dihapus dari storage
+	if _, err := dbTx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+		log.Printf("[DB ERROR] ReleaseAttachmentFile failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	var refs int
+	var pinned bool
+	err = dbTx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM transaction_attachments
+			WHERE storage_key = $1 OR thumbnail_key = $1 OR medium_key = $1)
+			+ (SELECT COUNT(*) FROM receipt_files WHERE storage_key = $1),
+			EXISTS (SELECT 1 FROM attachment_file_holds WHERE storage_key = $1 AND pinned_until > NOW())
+	`, key).Scan(&refs, &pinned)
+	if err != nil {
+		log.Printf("[DB ERROR] ReleaseAttachmentFile failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	switch {
+	case refs > 0:
+		_, err = dbTx.ExecContext(ctx, `DELETE FROM attachment_file_holds WHERE storage_key = $1 AND pinned_until <= NOW()`, key)
+	case pinned:
+		_, err = dbTx.ExecContext(ctx, `UPDATE attachment_file_holds SET remove_requested = TRUE WHERE storage_key = $1`, key)
+	default:
+		if removeErr := remove(ctx, key); removeErr != nil {
+			log.Printf("[Storage] failed to remove attachment %s: %v", key, removeErr)
+			_, err = dbTx.ExecContext(ctx, `
+				INSERT INTO attachment_file_holds (storage_key, pinned_until, remove_requested)
+				VALUES ($1, NOW(), TRUE)
+				ON CONFLICT (storage_key) DO UPDATE SET remove_requested = TRUE
+			`, key)
+		} else {
+			_, err = dbTx.ExecContext(ctx, `DELETE FROM attachment_file_holds WHERE storage_key = $1`, key)
+		}
+	}
+	if err != nil {
+		log.Printf("[DB ERROR] ReleaseAttachmentFile failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+// GetReleasableAttachmentFiles mengambil key yang penghapusannya tertunda dan membuang tahanan
+// kedaluwarsa yang tidak menunggu penghapusan.
+func (r *transactionRepository) GetReleasableAttachmentFiles(ctx context.Context, limit int) ([]string, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		DELETE FROM attachment_file_holds WHERE pinned_until <= NOW() AND NOT remove_requested
+	`); err != nil {
+		log.Printf("[DB ERROR] GetReleasableAttachmentFiles failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT storage_key FROM attachment_file_holds
+		WHERE remove_requested AND pinned_until <= NOW()
+		ORDER BY pinned_until
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] GetReleasableAttachmentFiles failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return keys, nil
+}
+
+// ClaimPendingAttachments mengambil lampiran yang belum dibuatkan preview dan menandainya
+// sedang diproses. Klaim yang lebih lama dari 10 menit dianggap gagal dan bisa diambil lagi.
+func (r *transactionRepository) ClaimPendingAttachments(ctx context.Context, limit int) ([]*entity.Attachment, error) {
+	query := `
+		UPDATE transaction_attachments
+		SET claimed_at = NOW()
+		WHERE id IN (
+			SELECT id FROM transaction_attachments
+			WHERE processed_at IS NULL
+				AND (claimed_at IS NULL OR claimed_at < NOW() - INTERVAL '10 minutes')
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + attachmentColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] ClaimPendingAttachments failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer rows.Close()
+
+	attachments := []*entity.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	return attachments, nil
+}
+
+// SaveAttachmentPreviews menyimpan hasil pemrosesan lampiran dan menandainya selesai.
+func (r *transactionRepository) SaveAttachmentPreviews(ctx context.Context, attachment *entity.Attachment) error {
+	query := `
+		UPDATE transaction_attachments
+		SET storage_key = $1, content_type = $2, size = $3, thumbnail_key = NULLIF($4, ''),
+			medium_key = NULLIF($5, ''), processed_at = $6
+		WHERE id = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		attachment.StorageKey,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.ThumbnailKey,
+		attachment.MediumKey,
+		attachment.ProcessedAt,
+		attachment.ID,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] SaveAttachmentPreviews failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func (r *transactionRepository) StoreAttachmentLink(ctx context.Context, nonce string, attachmentID uuid.UUID, ttl time.Duration) error {
+	key := "attachment_link:" + nonce
+	if err := r.redis.Set(ctx, key, attachmentID.String(), ttl).Err(); err != nil {
+		return errx.ErrRedisError
+	}
+	return nil
+}
+
+func (r *transactionRepository) ConsumeAttachmentLink(ctx context.Context, nonce string) (bool, error) {
+	key := "attachment_link:" + nonce
+	// DEL bersifat atomik sehingga dua request bersamaan tidak bisa sama-sama berhasil
+	deleted, err := r.redis.Del(ctx, key).Result()
+	if err != nil {
+		return false, errx.ErrRedisError
+	}
+	return deleted > 0, nil
+}
+
+// ApplyBatch menjalankan semua operasi dalam satu transaksi database. Jika satu operasi gagal,
+// seluruh perubahan dibatalkan dan error dibungkus BatchWriteError berisi indeks operasinya.
+func (r *transactionRepository) ApplyBatch(ctx context.Context, writes []entity.BatchWrite) error {
+	dbTx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	for i, w := range writes {
+		var err error
+		switch w.Op {
+		case entity.BatchCreate:
+			err = r.insertTransaction(ctx, dbTx, w.Transaction)
+		case entity.BatchUpdate:
+			err = r.updateTransaction(ctx, dbTx, w.Transaction, w.Revision)
+		case entity.BatchDelete:
+			err = softDeleteTransaction(ctx, dbTx, w.Transaction.ID.String(), w.Transaction.Version)
+		}
+		if err != nil {
+			return &BatchWriteError{Index: i, Err: err}
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return errx.ErrDatabaseError
+	}
+
+	for _, w := range writes {
+		if w.Op != entity.BatchCreate {
+			w.Transaction.Version++
+		}
+	}
+
+	return nil
+}
+
+// GetChangesSince mengambil transaksi, kategori, dan budget (maximum spend) milik user yang
+// berubah setelah posisi since, diurutkan dari yang paling lama. Setiap tabel dibatasi
+// limit baris sehingga hasil gabungan yang dipotong ke limit tetap berurutan tanpa celah.
+// Semua query dibaca dari satu snapshot dan hanya perubahan dari transaksi database yang xid-nya
+// di bawah xmin snapshot yang dikirim, karena transaksi yang masih berjalan bisa saja memegang
+// nomor urut lebih kecil dan commit setelah cursor client melewatinya.
+// Baris yang dihapus hanya dikirim jika since tidak nol, karena sync penuh tidak perlu tombstone.
+func (r *transactionRepository) GetChangesSince(ctx context.Context, userID uuid.UUID, since entity.SyncCursor, limit int) ([]entity.Change, error) {
+	dbTx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+	defer dbTx.Rollback()
+
+	// Query pertama menentukan snapshot transaksi ini, jadi xmin-nya cocok dengan data yang terbaca
+	var horizon int64
+	if err := dbTx.QueryRowContext(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT`).Scan(&horizon); err != nil {
+		log.Printf("[DB ERROR] GetChangesSince failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+
+	var changes []entity.Change
+	includeDeleted := since != (entity.SyncCursor{})
+
+	txRows, err := dbTx.QueryContext(ctx, `
+		SELECT sync_xid::TEXT::BIGINT, sync_seq, (created_xid, COALESCE(created_seq, 0)) > ($2::TEXT::xid8, $3::BIGINT),
+			`+transactionColumns+`
+		FROM transactions
+		WHERE user_id = $1
+			AND (sync_xid, sync_seq) > ($2::TEXT::xid8, $3::BIGINT)
+			AND sync_xid < $4::TEXT::xid8
+			AND ($5 OR deleted_at IS NULL)
+		ORDER BY sync_xid ASC, sync_seq ASC
+		LIMIT $6
+	`, userID, since.Xid, since.Seq, horizon, includeDeleted, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] GetChangesSince failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer txRows.Close()
+
+	for txRows.Next() {
+		c := entity.Change{Entity: entity.SyncTransaction}
+		tx, err := r.readTransaction(ctx, prefixScanner{row: txRows, prefix: []interface{}{&c.Cursor.Xid, &c.Cursor.Seq, &c.Created}})
+		if err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		c.EntityID = tx.ID.String()
+		if tx.DeletedAt != nil {
+			c.Deleted = true
+		} else {
+			c.Transaction = tx
+		}
+		changes = append(changes, c)
+	}
+	if err := txRows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	categoryRows, err := dbTx.QueryContext(ctx, `
+		SELECT sync_xid::TEXT::BIGINT, sync_seq, (created_xid, COALESCE(created_seq, 0)) > ($1::TEXT::xid8, $2::BIGINT), id, name
+		FROM categories
+		WHERE (sync_xid, sync_seq) > ($1::TEXT::xid8, $2::BIGINT)
+			AND sync_xid < $3::TEXT::xid8
+		ORDER BY sync_xid ASC, sync_seq ASC
+		LIMIT $4
+	`, since.Xid, since.Seq, horizon, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] GetChangesSince failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer categoryRows.Close()
+
+	for categoryRows.Next() {
+		c := entity.Change{Entity: entity.SyncCategory, Category: &categoryEntity.Category{}}
+		if err := categoryRows.Scan(&c.Cursor.Xid, &c.Cursor.Seq, &c.Created, &c.Category.ID, &c.Category.Name); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		c.EntityID = c.Category.ID
+		changes = append(changes, c)
+	}
+	if err := categoryRows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	budgetRows, err := dbTx.QueryContext(ctx, `
+		SELECT sync_xid::TEXT::BIGINT, sync_seq, (created_xid, COALESCE(created_seq, 0)) > ($2::TEXT::xid8, $3::BIGINT),
+			id, user_id, COALESCE(daily_limit, 0), COALESCE(monthly_limit, 0),
+			COALESCE(yearly_limit, 0), COALESCE(created_at, NOW()), COALESCE(updated_at, NOW())
+		FROM maximum_spends
+		WHERE user_id = $1
+			AND (sync_xid, sync_seq) > ($2::TEXT::xid8, $3::BIGINT)
+			AND sync_xid < $4::TEXT::xid8
+		ORDER BY sync_xid ASC, sync_seq ASC
+		LIMIT $5
+	`, userID, since.Xid, since.Seq, horizon, limit)
+	if err != nil {
+		log.Printf("[DB ERROR] GetChangesSince failed: %v\n", err)
+		return nil, errx.ErrDatabaseError
+	}
+	defer budgetRows.Close()
+
+	for budgetRows.Next() {
+		c := entity.Change{Entity: entity.SyncBudget, Budget: &maximumSpendEntity.MaximumSpend{}}
+		b := c.Budget
+		if err := budgetRows.Scan(&c.Cursor.Xid, &c.Cursor.Seq, &c.Created, &b.ID, &b.UserID, &b.DailyLimit, &b.MonthlyLimit,
+			&b.YearlyLimit, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+		c.EntityID = b.ID
+		changes = append(changes, c)
+	}
+	if err := budgetRows.Err(); err != nil {
+		return nil, errx.ErrDatabaseError
+	}
+
+	if includeDeleted {
+		// user_id kosong berarti data global seperti kategori
+		tombstoneRows, err := dbTx.QueryContext(ctx, `
+			SELECT sync_xid::TEXT::BIGINT, seq, entity, entity_id
+			FROM sync_tombstones
+			WHERE (user_id = $1 OR user_id IS NULL)
+				AND (sync_xid, seq) > ($2::TEXT::xid8, $3::BIGINT)
+				AND sync_xid < $4::TEXT::xid8
+			ORDER BY sync_xid ASC, seq ASC
+			LIMIT $5
+		`, userID, since.Xid, since.Seq, horizon, limit)
+		if err != nil {
+			log.Printf("[DB ERROR] GetChangesSince failed: %v\n", err)
+			return nil, errx.ErrDatabaseError
+		}
+		defer tombstoneRows.Close()
+
+		for tombstoneRows.Next() {
+			c := entity.Change{Deleted: true}
+			if err := tombstoneRows.Scan(&c.Cursor.Xid, &c.Cursor.Seq, &c.Entity, &c.EntityID); err != nil {
+				return nil, errx.ErrDatabaseError
+			}
+			changes = append(changes, c)
+		}
+		if err := tombstoneRows.Err(); err != nil {
+			return nil, errx.ErrDatabaseError
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[j].Cursor.After(changes[i].Cursor)
+	})
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+
+	return changes, nil
+}
+
+const transactionColumns = `id, user_id, amount, type, COALESCE(category_id, ''), COALESCE(note, ''), date, created_at, updated_at, COALESCE(period, ''), debt_id, tags, deleted_at, version, COALESCE(external_id, '')`
+
+const attachmentColumns = `id, transaction_id, user_id, filename, content_type, size, storage_key,
+	COALESCE(thumbnail_key, ''), COALESCE(medium_key, ''), processed_at, created_at`
+
+// BatchWriteError menandai operasi batch yang gagal dieksekusi.
+type BatchWriteError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchWriteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BatchWriteError) Unwrap() error {
+	return e.Err
+}
+
+// dbExecutor dipenuhi oleh *sql.DB maupun *sql.Tx sehingga query yang sama bisa
+// dipakai di dalam maupun di luar transaksi database.
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r *transactionRepository) insertTransaction(ctx context.Context, q dbExecutor, tx *entity.Transaction) error {
+	note, err := r.sealNote(ctx, tx.UserID, tx.Note)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO transactions (id, user_id, amount, type, category_id, note, period, date, created_at, updated_at, tags, external_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+	`
+
+	res, err := q.ExecContext(ctx, query,
+		tx.ID,
+		tx.UserID,
+		tx.Amount,
+		tx.TransactionType,
+		nullIfEmpty(tx.CategoryID),
+		note,
+		tx.Period,
+		tx.Date,
+		tx.CreatedAt,
+		tx.UpdatedAt,
+		pq.Array(tagsOrEmpty(tx.Tags)),
+		nullIfEmpty(tx.ExternalID),
+	)
+	if err != nil {
+		return errx.FromWrite("CreateTransaction", err)
+	}
+
+	// Hanya mungkin terjadi untuk transaksi import yang external ID-nya sudah ada
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errAlreadyImported
+	}
+
+	return nil
+}
+
+// updateTransaction mencatat revisi (jika ada) lalu mengubah transaksi hanya jika versinya
+// masih sama dengan tx.Version.
+func (r *transactionRepository) updateTransaction(ctx context.Context, q dbExecutor, tx *entity.Transaction, revision *entity.Revision) error {
+	note, err := r.sealNote(ctx, tx.UserID, tx.Note)
+	if err != nil {
+		return err
+	}
+
+	if revision != nil {
+		// Snapshot ikut menyimpan note, jadi note di dalamnya juga dienkripsi
+		sealed := revision.Snapshot
+		if sealed.Note, err = r.sealNote(ctx, tx.UserID, sealed.Note); err != nil {
+			return err
+		}
+		snapshot, err := json.Marshal(sealed)
+		if err != nil {
+			return errx.ErrInternalServer
+		}
+
+		err = q.QueryRowContext(ctx, `
+			INSERT INTO transaction_revisions (id, transaction_id, version, changed_by, snapshot, created_at)
+			SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+			FROM transaction_revisions
+			WHERE transaction_id = $2
+			RETURNING version
+		`, revision.ID, revision.TransactionID, revision.ChangedBy, snapshot, revision.CreatedAt).Scan(&revision.Version)
+		if err != nil {
+			log.Printf("[DB ERROR] CreateRevision failed: %v\n", err)
+			return errx.ErrDatabaseError
+		}
+	}
+
+	// Update hanya berhasil jika versi belum diubah request lain
+	query := `
+		UPDATE transactions
+		SET amount = $1, type = $2, category_id = $3, note = $4, date = $5, updated_at = $6, tags = $8,
+			period = $10, version = version + 1
+		WHERE id = $7 AND version = $9
+	`
+
+	res, err := q.ExecContext(ctx, query,
+		tx.Amount,
+		tx.TransactionType,
+		nullIfEmpty(tx.CategoryID),
+		note,
+		tx.Date,
+		tx.UpdatedAt,
+		tx.ID,
+		pq.Array(tagsOrEmpty(tx.Tags)),
+		tx.Version,
+		nullIfEmpty(tx.Period),
+	)
+
+	if err != nil {
+		return errx.FromWrite("UpdateTransaction", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func insertAttachment(ctx context.Context, q dbExecutor, attachment *entity.Attachment) error {
+	query := `
+		INSERT INTO transaction_attachments (id, transaction_id, user_id, storage_key, filename, content_type, size, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := q.ExecContext(ctx, query,
+		attachment.ID,
+		attachment.TransactionID,
+		attachment.UserID,
+		attachment.StorageKey,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.CreatedAt,
+	)
+	if err != nil {
+		log.Printf("[DB ERROR] CreateAttachment failed: %v\n", err)
+		return errx.ErrDatabaseError
+	}
+
+	return nil
+}
+
+func softDeleteTransaction(ctx context.Context, q dbExecutor, id string, version int) error {
+	query := `
+		UPDATE transactions
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND version = $2
+	`
+
+	res, err := q.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return errx.FromWrite("DeleteTransaction", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errx.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// prefixScanner membaca kolom tambahan di depan kolom yang dibaca scanner lain.
+type prefixScanner struct {
+	row    rowScanner
+	prefix []interface{}
+}
+
+func (p prefixScanner) Scan(dest ...interface{}) error {
+	return p.row.Scan(append(p.prefix, dest...)...)
+}
+
+func scanTransaction(row rowScanner) (*entity.Transaction, error) {
+	tx := &entity.Transaction{}
+	err := row.Scan(
+		&tx.ID,
+		&tx.UserID,
+		&tx.Amount,
+		&tx.TransactionType,
+		&tx.CategoryID,
+		&tx.Note,
+		&tx.Date,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+		&tx.Period,
+		&tx.DebtID,
+		pq.Array(&tx.Tags),
+		&tx.DeletedAt,
+		&tx.Version,
+		&tx.ExternalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// readTransaction membaca satu baris transaksi lalu mendekripsi note-nya.
+func (r *transactionRepository) readTransaction(ctx context.Context, row rowScanner) (*entity.Transaction, error) {
+	tx, err := scanTransaction(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.Note, err = r.openNote(ctx, tx.UserID, tx.Note); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// readRevision membaca revisi beserta user pemilik transaksi (kolom terakhir) yang
+// dibutuhkan untuk mendekripsi note di snapshot.
+func (r *transactionRepository) readRevision(ctx context.Context, row rowScanner) (*entity.Revision, error) {
+	var owner uuid.UUID
+	rev, err := scanRevision(prefixScanner{row: row, prefix: []interface{}{&owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	if rev.Snapshot.Note, err = r.openNote(ctx, owner, rev.Snapshot.Note); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+func (r *transactionRepository) sealNote(ctx context.Context, userID uuid.UUID, note string) (string, error) {
+	sealed, err := r.keyring.EncryptString(ctx, userID, note)
+	if err != nil {
+		log.Printf("[Encryption] failed to encrypt note: %v\n", err)
+		return "", errx.ErrInternalServer
+	}
+	return sealed, nil
+}
+
+func (r *transactionRepository) openNote(ctx context.Context, userID uuid.UUID, note string) (string, error) {
+	opened, err := r.keyring.DecryptString(ctx, userID, note)
+	if err != nil {
+		log.Printf("[Encryption] failed to decrypt note: %v\n", err)
+		return "", err
+	}
+	return opened, nil
+}
+
+func scanAttachment(row rowScanner) (*entity.Attachment, error) {
+	attachment := &entity.Attachment{}
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TransactionID,
+		&attachment.UserID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&attachment.ThumbnailKey,
+		&attachment.MediumKey,
+		&attachment.ProcessedAt,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func scanRevision(row rowScanner) (*entity.Revision, error) {
+	rev := &entity.Revision{}
+	var snapshot []byte
+	if err := row.Scan(&rev.ID, &rev.TransactionID, &rev.Version, &rev.ChangedBy, &snapshot, &rev.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+	"github.com/kenziehh/cashflow-be/pkg/imageutil"
+)
+
+// AttachmentLimits membatasi ukuran satu lampiran dan total ukuran lampiran per user (byte).
+type AttachmentLimits struct {
+	MaxSize int64
+	Quota   int64
+}
+
+// Tipe file yang boleh diunggah beserta ekstensi yang dipakai saat menyimpan.
+// Tipe ditentukan dari isi file, bukan dari nama atau header Content-Type.
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+	"image/heic":      ".heic",
+	"image/heif":      ".heif",
+}
+
+const maxFilenameLength = 100
+
+// Lama file yang baru ditulis ditahan dari penghapusan, cukup untuk menyimpan baris lampirannya
+const attachmentPinDuration = time.Hour
+
+func (s *transactionService) ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]*entity.Attachment, error) {
+	if _, err := s.getOwnedTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAttachments(ctx, transactionID)
+}
+
+func (s *transactionService) AddAttachment(ctx context.Context, userID, transactionID uuid.UUID, upload dto.AttachmentUpload) (*entity.Attachment, error) {
+	tx, err := s.getOwnedTransaction(ctx, userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.storeAttachment(ctx, tx, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateAttachment(ctx, attachment); err != nil {
+		s.removeOrphanedAttachment(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	s.queueAttachmentWork()
+
+	return attachment, nil
+}
+
+// OpenAttachment mengembalikan metadata lampiran beserta isinya; pemanggil wajib menutup reader.
+func (s *transactionService) OpenAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, size string) (*entity.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getOwnedAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.openAttachmentFile(ctx, attachment.Variant(size))
+}
+
+func (s *transactionService) openAttachmentFile(ctx context.Context, attachment *entity.Attachment) (*entity.Attachment, io.ReadCloser, error) {
+	data, err := s.readAttachmentFile(ctx, attachment.UserID, attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, nil, errx.NewNotFoundError("File not found")
+	}
+	if err != nil {
+		log.Printf("[Storage] failed to read attachment %s: %v", attachment.StorageKey, err)
+		return nil, nil, errx.ErrInternalServer
+	}
+
+	return attachment, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *transactionService) RemoveAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) error {
+	attachment, err := s.getOwnedAttachment(ctx, userID, transactionID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAttachment(ctx, attachment.ID); err != nil {
+		return err
+	}
+
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey, attachment.MediumKey} {
+		s.removeOrphanedAttachment(ctx, key)
+	}
+
+	return nil
+}
+
+func (s *transactionService) getOwnedAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) (*entity.Attachment, error) {
+	if _, err := s.getOwnedTransaction(ctx, userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TransactionID != transactionID {
+		return nil, errx.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// storeAttachment memvalidasi upload (ukuran, tipe file, kuota) lalu menyimpan isinya ke
+// storage. Lampiran yang dikembalikan belum disimpan ke database.
+func (s *transactionService) storeAttachment(ctx context.Context, tx *entity.Transaction, upload dto.AttachmentUpload) (*entity.Attachment, error) {
+	size := int64(len(upload.Data))
+	if size == 0 {
+		return nil, errx.NewBadRequestError("Attachment is empty")
+	}
+	if s.limits.MaxSize > 0 && size > s.limits.MaxSize {
+		return nil, errx.NewPayloadTooLargeError(fmt.Sprintf("Attachment exceeds the maximum size of %d bytes", s.limits.MaxSize))
+	}
+
+	contentType := mimetype.Detect(upload.Data).String()
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, errx.NewUnsupportedMediaTypeError("Only JPEG, PNG, PDF and HEIC files are allowed")
+	}
+
+	// Metadata dibuang sebelum disimpan supaya file asli dengan lokasi GPS tidak pernah bisa diunduh
+	data, err := stripImageMetadata(upload.Data, contentType)
+	if err != nil {
+		return nil, errx.NewUnsupportedMediaTypeError("Image could not be read")
+	}
+	size = int64(len(data))
+
+	if s.limits.Quota > 0 {
+		used, err := s.repo.GetAttachmentUsage(ctx, tx.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if used+size > s.limits.Quota {
+			return nil, errx.NewPayloadTooLargeError("Attachment storage quota exceeded")
+		}
+	}
+
+	filename := sanitizeFilename(upload.Filename, ext)
+	key, err := s.putAttachmentFile(ctx, tx.UserID, "attachments", data, ext, contentType)
+	if err != nil {
+		log.Printf("[Storage] failed to store attachment %s: %v", key, err)
+		return nil, errx.NewInternalServerError("Failed to save attachment")
+	}
+
+	return &entity.Attachment{
+		ID:            uuid.New(),
+		TransactionID: tx.ID,
+		UserID:        tx.UserID,
+		Filename:      filename,
+		ContentType:   contentType,
+		Size:          size,
+		StorageKey:    key,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// stripImageMetadata membuang EXIF (termasuk lokasi GPS) dan XMP dari foto. File selain gambar
+// dikembalikan apa adanya; gambar yang strukturnya tidak terbaca menghasilkan error.
+func stripImageMetadata(data []byte, contentType string) ([]byte, error) {
+	if !strings.HasPrefix(contentType, "image/") {
+		return data, nil
+	}
+	return imageutil.StripMetadata(data)
+}
+
+// sanitizeFilename membuang path dan menyisakan karakter ASCII yang aman dipakai di header
+// Content-Disposition, lalu menyesuaikan ekstensi dengan tipe file sebenarnya.
+func sanitizeFilename(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('_')
+		}
+	}
+
+	base := strings.Trim(b.String(), "._")
+	if base == "" {
+		base = "attachment"
+	}
+	if runes := []rune(base); len(runes) > maxFilenameLength {
+		base = string(runes[:maxFilenameLength])
+	}
+
+	return base + ext
+}
+
+// putAttachmentFile mengenkripsi isi file dengan data key milik user lalu menyimpannya.
+// Key tetap berbasis isi file asli tetapi dipisah per user, karena ciphertext yang sama
+// hanya bisa dibuka oleh pemiliknya.
+func (s *transactionService) putAttachmentFile(ctx context.Context, userID uuid.UUID, prefix string, data []byte, ext, contentType string) (string, error) {
+	encrypted, err := s.keyring.Encrypt(ctx, userID, data)
+	if err != nil {
+		return "", err
+	}
+
+	key := storage.ContentKey(prefix+"/"+userID.String(), data, ext)
+	if err := s.repo.PinAttachmentFile(ctx, key, attachmentPinDuration); err != nil {
+		return "", err
+	}
+	if err := s.storage.Put(ctx, key, encrypted, contentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// readAttachmentFile membaca dan mendekripsi isi file. File lama yang disimpan sebelum
+// enkripsi aktif dikembalikan apa adanya.
+func (s *transactionService) readAttachmentFile(ctx context.Context, userID uuid.UUID, key string) ([]byte, error) {
+	r, err := s.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.keyring.Decrypt(ctx, userID, data)
+}
+
+// removeOrphanedAttachment menghapus file dari storage jika tidak ada lampiran (termasuk
+// milik transaksi di trash) yang masih memakainya. Karena key berbasis isi file, satu file
+// bisa dipakai beberapa lampiran sekaligus, dan file yang baru ditulis upload lain baru
+// dihapus worker setelah tahanannya habis.
+func (s *transactionService) removeOrphanedAttachment(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+
+	if err := s.repo.ReleaseAttachmentFile(ctx, key, s.storage.Delete); err != nil {
+		log.Printf("[Storage] failed to release attachment %s: %v", key, err)
+	}
+}
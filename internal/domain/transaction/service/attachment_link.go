@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// AttachmentLinks mengatur URL download lampiran yang ditandatangani. BaseURL adalah
+// alamat publik aplikasi dan Secret kunci HMAC untuk tanda tangannya.
+type AttachmentLinks struct {
+	BaseURL string
+	Secret  string
+}
+
+const defaultAttachmentLinkTTL = 15 * time.Minute
+
+var errInvalidAttachmentLink = errx.NewForbiddenError("Invalid or expired link")
+
+// CreateAttachmentLink membuat URL download tanpa bearer token yang berlaku sampai waktu
+// tertentu, misalnya untuk tag <img> atau dibagikan ke akuntan.
+func (s *transactionService) CreateAttachmentLink(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, req dto.CreateAttachmentLinkRequest) (*dto.AttachmentLinkResponse, error) {
+	if _, err := s.getOwnedAttachment(ctx, userID, transactionID, attachmentID); err != nil {
+		return nil, err
+	}
+
+	ttl := defaultAttachmentLinkTTL
+	if req.ExpiresIn > 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+
+	// Link sekali pakai ditandai nonce yang disimpan sampai link kedaluwarsa
+	var nonce string
+	if req.OneTime {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, errx.ErrInternalServer
+		}
+		nonce = hex.EncodeToString(buf)
+
+		if err := s.repo.StoreAttachmentLink(ctx, nonce, attachmentID, ttl); err != nil {
+			return nil, err
+		}
+		query.Set("nonce", nonce)
+	}
+
+	query.Set("sig", s.signAttachmentLink(attachmentID, expiresAt.Unix(), nonce))
+
+	return &dto.AttachmentLinkResponse{
+		URL:       fmt.Sprintf("%s/api/v1/attachments/%s?%s", strings.TrimRight(s.links.BaseURL, "/"), attachmentID, query.Encode()),
+		ExpiresAt: expiresAt,
+		OneTime:   req.OneTime,
+	}, nil
+}
+
+// OpenSignedAttachment memverifikasi tanda tangan dan masa berlaku URL dari
+// CreateAttachmentLink lalu membuka isi lampiran. Pemanggil wajib menutup reader.
+func (s *transactionService) OpenSignedAttachment(ctx context.Context, attachmentID uuid.UUID, params dto.SignedAttachmentParams) (*entity.Attachment, io.ReadCloser, error) {
+	expected := s.signAttachmentLink(attachmentID, params.Expires, params.Nonce)
+	if !hmac.Equal([]byte(params.Signature), []byte(expected)) {
+		return nil, nil, errInvalidAttachmentLink
+	}
+	if time.Now().Unix() > params.Expires {
+		return nil, nil, errInvalidAttachmentLink
+	}
+
+	attachment, err := s.repo.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if params.Nonce != "" {
+		ok, err := s.repo.ConsumeAttachmentLink(ctx, params.Nonce)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, errx.NewForbiddenError("Link has already been used")
+		}
+	}
+
+	return s.openAttachmentFile(ctx, attachment.Variant(params.Size))
+}
+
+func (s *transactionService) signAttachmentLink(attachmentID uuid.UUID, expires int64, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(s.links.Secret))
+	fmt.Fprintf(mac, "attachment:%s:%d:%s", attachmentID, expires, nonce)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+	"github.com/kenziehh/cashflow-be/pkg/imageutil"
+)
+
+const (
+	thumbnailSize    = 200
+	thumbnailQuality = 75
+	mediumSize       = 1280
+	mediumQuality    = 85
+
+	attachmentBatchSize = 20
+)
+
+// RunAttachmentWorker membuat thumbnail dan preview untuk lampiran baru dan menghapus file
+// lampiran yang tertunda sampai ctx selesai.
+// Worker bangun setiap interval, atau lebih cepat saat ada upload baru di instance ini.
+func (s *transactionService) RunAttachmentWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := s.processPendingAttachments(ctx)
+			if err != nil {
+				log.Println("[AttachmentWorker] failed:", err)
+			}
+			if err != nil || n < attachmentBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.attachmentQueued:
+		}
+	}
+}
+
+// queueAttachmentWork membangunkan worker tanpa menunggu; jika worker sedang sibuk,
+// lampiran tetap akan diambil pada putaran berikutnya.
+func (s *transactionService) queueAttachmentWork() {
+	select {
+	case s.attachmentQueued <- struct{}{}:
+	default:
+	}
+}
+
+func (s *transactionService) processPendingAttachments(ctx context.Context) (int, error) {
+	// File yang penghapusannya tertunda karena masih ditahan upload lain dicoba lagi
+	keys, err := s.repo.GetReleasableAttachmentFiles(ctx, attachmentBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range keys {
+		s.removeOrphanedAttachment(ctx, key)
+	}
+
+	attachments, err := s.repo.ClaimPendingAttachments(ctx, attachmentBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		if err := s.processAttachment(ctx, attachment); err != nil {
+			// Klaim dibiarkan kedaluwarsa supaya lampiran dicoba lagi nanti
+			log.Printf("[AttachmentWorker] failed to process attachment %s: %v", attachment.ID, err)
+		}
+	}
+
+	return len(attachments), nil
+}
+
+// processAttachment membuat versi thumb dan medium dari foto JPEG/PNG. Metadata sudah dibuang
+// saat upload; pembuangan di sini untuk lampiran lama yang disimpan sebelum itu, termasuk HEIC.
+// PDF dan HEIC tidak dibuatkan preview.
+func (s *transactionService) processAttachment(ctx context.Context, attachment *entity.Attachment) error {
+	now := time.Now()
+	attachment.ProcessedAt = &now
+	originalKey := attachment.StorageKey
+
+	if !strings.HasPrefix(attachment.ContentType, "image/") {
+		return s.repo.SaveAttachmentPreviews(ctx, attachment)
+	}
+
+	data, err := s.readAttachmentFile(ctx, attachment.UserID, originalKey)
+	if err == storage.ErrNotFound {
+		return s.repo.SaveAttachmentPreviews(ctx, attachment)
+	}
+	if err != nil {
+		return err
+	}
+
+	// File yang tidak bisa dibaca sebagai gambar ditandai selesai tanpa preview
+	stripped, err := imageutil.StripMetadata(data)
+	if err != nil {
+		return s.repo.SaveAttachmentPreviews(ctx, attachment)
+	}
+	ext := allowedAttachmentTypes[attachment.ContentType]
+	if !bytes.Equal(stripped, data) {
+		key, err := s.putAttachmentFile(ctx, attachment.UserID, "attachments", stripped, ext, attachment.ContentType)
+		if err != nil {
+			return err
+		}
+		attachment.StorageKey = key
+		attachment.Size = int64(len(stripped))
+	}
+
+	variants := []struct {
+		prefix  string
+		size    int
+		quality int
+		key     *string
+	}{
+		{"thumbnails", thumbnailSize, thumbnailQuality, &attachment.ThumbnailKey},
+		{"previews", mediumSize, mediumQuality, &attachment.MediumKey},
+	}
+	if attachment.ContentType != "image/jpeg" && attachment.ContentType != "image/png" {
+		variants = nil
+	}
+	for _, v := range variants {
+		resized, err := imageutil.Thumbnail(stripped, v.size, v.quality)
+		if err != nil {
+			log.Printf("[AttachmentWorker] cannot resize attachment %s: %v", attachment.ID, err)
+			break
+		}
+		key, err := s.putAttachmentFile(ctx, attachment.UserID, v.prefix, resized, ".jpg", "image/jpeg")
+		if err != nil {
+			return err
+		}
+		*v.key = key
+	}
+
+	if err := s.repo.SaveAttachmentPreviews(ctx, attachment); err != nil {
+		return err
+	}
+
+	// File asli yang masih berisi metadata dihapus jika tidak dipakai lampiran lain
+	if attachment.StorageKey != originalKey {
+		s.removeOrphanedAttachment(ctx, originalKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/repository"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// BatchRejectedError dikembalikan jika ada operasi batch yang gagal. Tidak ada operasi yang
+// disimpan; Results berisi status setiap operasi dan Status mengikuti operasi pertama yang gagal.
+type BatchRejectedError struct {
+	Status  int
+	Results []dto.BatchOperationResult
+}
+
+func (e *BatchRejectedError) Error() string {
+	return "Batch rejected, no operations were applied"
+}
+
+// NewBatchRejectedError membentuk hasil per operasi dari error yang ditemukan.
+// Operasi tanpa error ditandai 424 karena ikut dibatalkan.
+func NewBatchRejectedError(ops []dto.BatchOperation, failures map[int]error) *BatchRejectedError {
+	rejected := &BatchRejectedError{Results: make([]dto.BatchOperationResult, 0, len(ops))}
+	for i, op := range ops {
+		result := dto.BatchOperationResult{
+			Index:  i,
+			Op:     op.Op,
+			Status: http.StatusFailedDependency,
+			Error:  "Not applied because another operation failed",
+		}
+		if err, ok := failures[i]; ok {
+			result = batchFailure(i, op.Op, err)
+			if rejected.Status == 0 {
+				rejected.Status = result.Status
+			}
+		}
+		rejected.Results = append(rejected.Results, result)
+	}
+
+	return rejected
+}
+
+// BatchTransactions memvalidasi seluruh operasi dengan aturan yang sama seperti endpoint
+// tunggal, lalu menyimpannya sekaligus dalam satu transaksi database. Operasi diproses
+// berurutan, jadi operasi berikutnya pada transaksi yang sama melihat hasil operasi sebelumnya.
+func (s *transactionService) BatchTransactions(ctx context.Context, userID uuid.UUID, req dto.BatchTransactionRequest) ([]dto.BatchOperationResult, error) {
+	var ids []uuid.UUID
+	for _, op := range req.Operations {
+		if id, err := uuid.Parse(op.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	current, err := s.repo.GetTransactionsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := make([]entity.BatchWrite, len(req.Operations))
+	befores := make([]*entity.Transaction, len(req.Operations))
+	failures := map[int]error{}
+	for i, op := range req.Operations {
+		write, before, err := s.prepareBatchWrite(ctx, userID, op, current)
+		if err != nil {
+			failures[i] = err
+			continue
+		}
+		writes[i] = write
+		befores[i] = before
+	}
+
+	if len(failures) > 0 {
+		return nil, NewBatchRejectedError(req.Operations, failures)
+	}
+
+	if err := s.repo.ApplyBatch(ctx, writes); err != nil {
+		var writeErr *repository.BatchWriteError
+		if errors.As(err, &writeErr) {
+			return nil, NewBatchRejectedError(req.Operations, map[int]error{writeErr.Index: writeErr.Err})
+		}
+		return nil, err
+	}
+
+	results := make([]dto.BatchOperationResult, 0, len(writes))
+	for i, w := range writes {
+		result := dto.BatchOperationResult{Index: i, Op: w.Op, Status: http.StatusOK, Transaction: w.Transaction}
+		switch w.Op {
+		case entity.BatchCreate:
+			result.Status = http.StatusCreated
+			s.learnCategory(ctx, w.Transaction)
+		case entity.BatchUpdate:
+			s.forgetCategory(ctx, befores[i])
+			s.learnCategory(ctx, w.Transaction)
+		case entity.BatchDelete:
+			result.Transaction = nil
+			s.forgetCategory(ctx, befores[i])
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// prepareBatchWrite menyiapkan satu operasi beserta kondisi transaksi sebelum diubah.
+// current diperbarui supaya operasi berikutnya memakai kondisi setelah operasi ini.
+func (s *transactionService) prepareBatchWrite(ctx context.Context, userID uuid.UUID, op dto.BatchOperation, current map[uuid.UUID]*entity.Transaction) (entity.BatchWrite, *entity.Transaction, error) {
+	if op.Op == entity.BatchCreate {
+		if op.Create == nil {
+			return entity.BatchWrite{}, nil, errx.NewBadRequestError("create is required for create operations")
+		}
+
+		tx, err := s.newTransaction(ctx, *op.Create, userID)
+		if err != nil {
+			return entity.BatchWrite{}, nil, err
+		}
+
+		return entity.BatchWrite{Op: op.Op, Transaction: tx}, nil, nil
+	}
+
+	if op.ID == "" || op.Version == 0 {
+		return entity.BatchWrite{}, nil, errx.NewBadRequestError("id and version are required for update and delete operations")
+	}
+
+	id, err := uuid.Parse(op.ID)
+	if err != nil {
+		return entity.BatchWrite{}, nil, errx.NewBadRequestError("Invalid transaction ID format")
+	}
+
+	existing := current[id]
+	if existing == nil {
+		return entity.BatchWrite{}, nil, errx.ErrTransactionNotFound
+	}
+	if existing.UserID != userID {
+		return entity.BatchWrite{}, nil, errx.NewUnauthorizedError("You do not have access to this transaction")
+	}
+	if existing.Version != op.Version {
+		return entity.BatchWrite{}, nil, errx.ErrVersionMismatch
+	}
+
+	next := *existing
+	switch op.Op {
+	case entity.BatchUpdate:
+		if op.Update == nil {
+			return entity.BatchWrite{}, nil, errx.NewBadRequestError("update is required for update operations")
+		}
+
+		applyUpdate(&next, *op.Update)
+		if err := checkRepaymentChange(existing, &next); err != nil {
+			return entity.BatchWrite{}, nil, err
+		}
+
+		after := next
+		after.Version++
+		current[id] = &after
+
+		return entity.BatchWrite{Op: op.Op, Transaction: &next, Revision: newRevision(existing, userID)}, existing, nil
+	case entity.BatchDelete:
+		delete(current, id)
+
+		return entity.BatchWrite{Op: op.Op, Transaction: &next}, existing, nil
+	}
+
+	return entity.BatchWrite{}, nil, errx.NewBadRequestError("op must be one of create, update, delete")
+}
+
+func batchFailure(index int, op string, err error) dto.BatchOperationResult {
+	result := dto.BatchOperationResult{
+		Index:  index,
+		Op:     op,
+		Status: http.StatusInternalServerError,
+		Error:  "Internal server error",
+	}
+
+	var dupErr *DuplicateWarningError
+	if errors.As(err, &dupErr) {
+		result.Status = http.StatusConflict
+		result.Error = dupErr.Error()
+		result.Duplicates = dupErr.Candidates
+	} else if appErr, ok := errx.IsAppError(err); ok {
+		result.Status = appErr.Code
+		result.Error = appErr.Message
+	}
+
+	return result
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+)
+
+const (
+	// Rentang hari default untuk menganggap dua transaksi berdekatan
+	defaultDuplicateDays = 3
+	// Batas minimal kemiripan note agar dianggap duplikat
+	duplicateNoteThreshold = 0.5
+)
+
+// DuplicateWarningError dikembalikan saat transaksi baru mirip transaksi yang sudah ada
+// dan user belum mengonfirmasi dengan confirm_duplicate.
+type DuplicateWarningError struct {
+	Candidates []dto.DuplicateCandidate
+}
+
+func (e *DuplicateWarningError) Error() string {
+	return "Possible duplicate transaction found, resubmit with confirm_duplicate to save it anyway"
+}
+
+// noteSimilarity menghitung kemiripan Jaccard dari kata-kata pada note.
+// Dua note kosong dianggap sama, satu note kosong dianggap setengah mirip.
+func noteSimilarity(a, b string) float64 {
+	ta, tb := noteWords(a), noteWords(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0.5
+	}
+
+	intersection := 0
+	for w := range ta {
+		if tb[w] {
+			intersection++
+		}
+	}
+	union := len(ta) + len(tb) - intersection
+
+	return math.Round(float64(intersection)/float64(union)*100) / 100
+}
+
+func noteWords(note string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(note), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+func daysApart(a, b string) int {
+	da, errA := time.Parse("2006-01-02", dateOnly(a))
+	db, errB := time.Parse("2006-01-02", dateOnly(b))
+	if errA != nil || errB != nil {
+		return 0
+	}
+
+	days := int(da.Sub(db).Hours() / 24)
+	if days < 0 {
+		days = -days
+	}
+	return days
+}
+
+func dateOnly(date string) string {
+	if len(date) > len("2006-01-02") {
+		return date[:len("2006-01-02")]
+	}
+	return date
+}
+
+// mergeInto menggabungkan data duplikat ke transaksi yang dipertahankan:
+// note digabung bila berbeda, tag disatukan, dan bukti/kategori diambil jika kosong.
+func mergeInto(keep, duplicate *entity.Transaction) {
+	keepNote := strings.TrimSpace(keep.Note)
+	dupNote := strings.TrimSpace(duplicate.Note)
+	switch {
+	case dupNote == "" || strings.Contains(strings.ToLower(keepNote), strings.ToLower(dupNote)):
+	case keepNote == "":
+		keep.Note = dupNote
+	default:
+		keep.Note = keepNote + " / " + dupNote
+	}
+
+	for _, tag := range duplicate.Tags {
+		found := false
+		for _, t := range keep.Tags {
+			if strings.EqualFold(t, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keep.Tags = append(keep.Tags, tag)
+		}
+	}
+
+	if keep.CategoryID == "" {
+		keep.CategoryID = duplicate.CategoryID
+	}
+
+	keep.UpdatedAt = time.Now()
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	ruleEntity "github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+	ruleService "github.com/kenziehh/cashflow-be/internal/domain/rule/service"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/importer"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// Batas jumlah transaksi per file supaya satu import tetap muat dalam satu transaksi database
+const maxImportRecords = 5000
+
+// Kolom amount bertipe DECIMAL(12,2), jadi nominal harus di bawah 10 miliar
+const maxImportAmount = 1e10
+
+// ImportTransactions membaca file export bank atau aplikasi lain lalu menyimpan transaksinya.
+// Kategori ditentukan berurutan dari mapping kategori user, nama kategori yang sama persis,
+// rule auto-categorization, lalu default_category_id. Transaksi yang external ID-nya sudah
+// pernah diimport dilewati sehingga file yang sama aman diimport berulang kali.
+func (s *transactionService) ImportTransactions(ctx context.Context, userID uuid.UUID, data []byte, params dto.ImportParams) (*dto.ImportResult, error) {
+	imp, parsed, err := parseImportFile(params.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ImportResult{
+		Format:             imp.Name(),
+		DryRun:             params.DryRun,
+		Total:              len(parsed.Records),
+		UnmappedCategories: []string{},
+		Errors:             parsed.Errors,
+	}
+	if result.Errors == nil {
+		result.Errors = []importer.RowError{}
+	}
+
+	categorizer, err := s.newImportCategorizer(ctx, userID, imp.Name())
+	if err != nil {
+		return nil, err
+	}
+	if params.DefaultCategoryID != "" && !categorizer.exists(params.DefaultCategoryID) {
+		return nil, errx.ErrCategoryNotFound
+	}
+
+	isLocked, err := s.lockedPeriods(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	externalIDs := make([]string, 0, len(parsed.Records))
+	for _, record := range parsed.Records {
+		externalIDs = append(externalIDs, record.ExternalID)
+	}
+	imported, err := s.repo.GetImportedExternalIDs(ctx, userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	unmapped := map[string]string{}
+	var txs []*entity.Transaction
+	for _, record := range parsed.Records {
+		if imported[record.ExternalID] {
+			result.AlreadyImported++
+			continue
+		}
+		// External ID yang berulang di file yang sama juga hanya diimport sekali
+		imported[record.ExternalID] = true
+
+		amount, problem := importAmount(record.Amount)
+		if problem != "" {
+			result.Errors = append(result.Errors, importer.RowError{Line: record.Line, Message: problem})
+			continue
+		}
+		if isLocked(record.Date) {
+			result.Locked++
+			continue
+		}
+
+		tx := newImportedTransaction(userID, record.Date, amount, record.Note, record.ExternalID, now)
+		mapped := categorizer.apply(tx, record.Category)
+
+		if tx.CategoryID == "" {
+			tx.CategoryID = params.DefaultCategoryID
+		}
+		if !mapped && record.Category != "" && tx.CategoryID == "" {
+			unmapped[importer.NormalizeCategory(record.Category)] = strings.TrimSpace(record.Category)
+		}
+		if tx.CategoryID == "" {
+			result.Uncategorized++
+			continue
+		}
+
+		txs = append(txs, tx)
+	}
+
+	for _, name := range unmapped {
+		result.UnmappedCategories = append(result.UnmappedCategories, name)
+	}
+	sort.Strings(result.UnmappedCategories)
+
+	if params.DryRun || len(txs) == 0 {
+		result.Created = len(txs)
+		return result, nil
+	}
+
+	created, err := s.repo.ImportTransactions(ctx, txs)
+	if err != nil {
+		return nil, err
+	}
+	result.Created = len(created)
+	result.AlreadyImported += len(txs) - len(created)
+
+	if len(created) > 0 {
+		s.retrainAfterImport(ctx, userID)
+	}
+
+	return result, nil
+}
+
+func parseImportFile(format string, data []byte) (importer.Importer, *importer.Result, error) {
+	imp, ok := importer.Lookup(format)
+	if !ok {
+		return nil, nil, errx.NewBadRequestError(fmt.Sprintf("Unsupported format, must be one of: %s", strings.Join(importer.Formats(), ", ")))
+	}
+
+	parsed, err := imp.Parse(data)
+	if err != nil {
+		return nil, nil, errx.NewBadRequestError("Failed to read file: " + err.Error())
+	}
+	if len(parsed.Records) > maxImportRecords {
+		return nil, nil, errx.NewBadRequestError(fmt.Sprintf("File contains more than %d transactions, split it into smaller files", maxImportRecords))
+	}
+
+	return imp, parsed, nil
+}
+
+// importAmount membulatkan nominal ke sen lalu memeriksanya, supaya 0.004 tidak lolos dan
+// tersimpan sebagai 0. Pesan yang dikembalikan kosong jika nominal bisa disimpan.
+func importAmount(amount float64) (float64, string) {
+	amount = roundCents(amount)
+	switch {
+	case amount == 0:
+		return 0, "amount must not be zero"
+	case math.Abs(amount) >= maxImportAmount:
+		return 0, "amount must be less than 10,000,000,000"
+	}
+	return amount, ""
+}
+
+// newImportedTransaction membentuk transaksi dari baris file import; amount negatif berarti pengeluaran.
+func newImportedTransaction(userID uuid.UUID, date string, amount float64, note, externalID string, now time.Time) *entity.Transaction {
+	tx := &entity.Transaction{
+		ID:              uuid.New(),
+		UserID:          userID,
+		TransactionType: "income",
+		Amount:          amount,
+		Period:          "daily",
+		Note:            note,
+		Date:            date,
+		Tags:            []string{},
+		ExternalID:      externalID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if amount < 0 {
+		tx.TransactionType = "expense"
+		tx.Amount = -amount
+	}
+	return tx
+}
+
+// retrainAfterImport melatih ulang model saran kategori sekali, lebih murah daripada
+// mempelajari banyak transaksi baru satu per satu.
+func (s *transactionService) retrainAfterImport(ctx context.Context, userID uuid.UUID) {
+	if err := s.suggestions.Retrain(ctx, userID); err != nil {
+		log.Printf("[CategoryModel] failed to retrain after import: %v", err)
+	}
+}
+
+// lockedPeriods mengembalikan fungsi yang memeriksa apakah tanggal (2006-01-02) berada di
+// periode rekonsiliasi yang dikunci.
+func (s *transactionService) lockedPeriods(ctx context.Context, userID uuid.UUID) (func(date string) bool, error) {
+	reconciliations, err := s.reconciliations.GetReconciliations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked []*entity.Reconciliation
+	for _, rec := range reconciliations {
+		if rec.LockedAt != nil {
+			locked = append(locked, rec)
+		}
+	}
+
+	return func(date string) bool {
+		for _, rec := range locked {
+			if date >= rec.StartDate && date <= rec.EndDate {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// importCategorizer menentukan kategori transaksi hasil import. Mapping user didahulukan,
+// lalu kategori kita yang namanya sama, lalu rule auto-categorization.
+type importCategorizer struct {
+	byName      map[string]string
+	categoryIDs map[string]bool
+	engine      *ruleService.Engine
+}
+
+func (s *transactionService) newImportCategorizer(ctx context.Context, userID uuid.UUID, source string) (*importCategorizer, error) {
+	categories, err := s.repo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := s.repo.GetCategoryMappings(ctx, userID, source)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := s.rules.NewEngine(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &importCategorizer{
+		byName:      map[string]string{},
+		categoryIDs: map[string]bool{},
+		engine:      engine,
+	}
+	for _, category := range categories {
+		c.byName[importer.NormalizeCategory(category.Name)] = category.ID
+		c.categoryIDs[category.ID] = true
+	}
+	for _, m := range mappings {
+		c.byName[m.SourceCategory] = m.CategoryID
+	}
+
+	return c, nil
+}
+
+func (c *importCategorizer) exists(categoryID string) bool {
+	return c.categoryIDs[categoryID]
+}
+
+// apply mengisi kategori, note dan tag tx dari kategori sumber dan rule. Hasilnya false jika
+// kategori sumber tidak dikenali; kategori dari rule tetap bisa terisi.
+func (c *importCategorizer) apply(tx *entity.Transaction, sourceCategory string) bool {
+	categoryID, mapped := "", false
+	if sourceCategory != "" {
+		categoryID, mapped = c.byName[importer.NormalizeCategory(sourceCategory)]
+	}
+
+	target, _ := c.engine.Apply(ruleEntity.Target{
+		TransactionType: tx.TransactionType,
+		Amount:          tx.Amount,
+		Note:            tx.Note,
+		CategoryID:      categoryID,
+		Tags:            tx.Tags,
+	}, mapped)
+
+	tx.CategoryID = target.CategoryID
+	tx.Note = target.Note
+	tx.Tags = target.Tags
+	return mapped
+}
+
+func (s *transactionService) GetCategoryMappings(ctx context.Context, userID uuid.UUID, params dto.CategoryMappingParams) ([]*entity.CategoryMapping, error) {
+	source := params.Source
+	if imp, ok := importer.Lookup(source); ok {
+		source = imp.Name()
+	}
+
+	return s.repo.GetCategoryMappings(ctx, userID, source)
+}
+
+func (s *transactionService) SaveCategoryMapping(ctx context.Context, userID uuid.UUID, req dto.CategoryMappingRequest) (*entity.CategoryMapping, error) {
+	imp, ok := importer.Lookup(req.Source)
+	if !ok {
+		return nil, errx.NewBadRequestError(fmt.Sprintf("Unsupported source, must be one of: %s", strings.Join(importer.Formats(), ", ")))
+	}
+
+	mapping := &entity.CategoryMapping{
+		Source:         imp.Name(),
+		SourceCategory: importer.NormalizeCategory(req.SourceCategory),
+		CategoryID:     req.CategoryID,
+	}
+	if mapping.SourceCategory == "" {
+		return nil, errx.NewBadRequestError("source_category is required")
+	}
+
+	if err := s.repo.SaveCategoryMapping(ctx, userID, mapping); err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+func (s *transactionService) DeleteCategoryMapping(ctx context.Context, userID uuid.UUID, params dto.DeleteCategoryMappingParams) error {
+	imp, ok := importer.Lookup(params.Source)
+	if !ok {
+		return errx.ErrCategoryMappingNotFound
+	}
+
+	return s.repo.DeleteCategoryMapping(ctx, userID, imp.Name(), importer.NormalizeCategory(params.SourceCategory))
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	ruleEntity "github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+	suggestionDto "github.com/kenziehh/cashflow-be/internal/domain/suggestion/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/importer"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/quickadd"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// Confidence minimal saran model supaya kategorinya dipakai di draft
+const minQuickAddConfidence = 0.5
+
+// QuickAdd membaca kalimat seperti "makan siang 35rb kemarin" menjadi draft transaksi. Kategori
+// ditebak dari rule, nama kategori yang disebut di kalimat, lalu model saran kategori. Draft
+// hanya disimpan jika req.Create true dan nominal serta kategorinya sudah lengkap.
+func (s *transactionService) QuickAdd(ctx context.Context, userID uuid.UUID, req dto.QuickAddRequest) (*dto.QuickAddResponse, error) {
+	today := time.Now()
+	if req.Today != "" {
+		today, _ = time.Parse("2006-01-02", req.Today)
+	}
+
+	entry := quickadd.Parse(req.Text, today)
+	resp := &dto.QuickAddResponse{
+		Draft: dto.CreateTransactionRequest{
+			TransactionType:  entry.TransactionType,
+			Amount:           entry.Amount,
+			CategoryID:       req.CategoryID,
+			Note:             entry.Note,
+			Period:           "daily",
+			Date:             entry.Date,
+			ConfirmDuplicate: req.ConfirmDuplicate,
+		},
+		Missing: []string{},
+	}
+
+	if err := s.guessQuickAddCategory(ctx, userID, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Draft.Amount <= 0 {
+		resp.Missing = append(resp.Missing, "amount")
+	}
+	if resp.Draft.CategoryID == "" {
+		resp.Missing = append(resp.Missing, "category_id")
+	}
+
+	if !req.Create {
+		return resp, nil
+	}
+	if resp.Draft.Amount <= 0 {
+		return nil, errx.NewBadRequestError("Could not find an amount in the text")
+	}
+	if resp.Draft.CategoryID == "" {
+		return nil, errx.NewBadRequestError("category_id is required when no category matches")
+	}
+
+	tx, err := s.CreateTransaction(ctx, resp.Draft, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Transaction = tx
+
+	return resp, nil
+}
+
+func (s *transactionService) guessQuickAddCategory(ctx context.Context, userID uuid.UUID, resp *dto.QuickAddResponse) error {
+	categories, err := s.repo.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+	names := map[string]string{}
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	draft := &resp.Draft
+	if draft.CategoryID != "" {
+		if _, ok := names[draft.CategoryID]; !ok {
+			return errx.ErrCategoryNotFound
+		}
+		resp.CategoryName, resp.CategorySource = names[draft.CategoryID], "request"
+		return nil
+	}
+
+	engine, err := s.rules.NewEngine(ctx, userID)
+	if err != nil {
+		return err
+	}
+	target, _ := engine.Apply(ruleEntity.Target{
+		TransactionType: draft.TransactionType,
+		Amount:          draft.Amount,
+		Note:            draft.Note,
+		Tags:            []string{},
+	}, false)
+	if target.CategoryID != "" {
+		draft.CategoryID, resp.CategoryName, resp.CategorySource = target.CategoryID, names[target.CategoryID], "rule"
+		return nil
+	}
+
+	if draft.Note == "" {
+		return nil
+	}
+
+	// Nama kategori yang disebut utuh di kalimat, yang terpanjang menang ("makan siang" atas "makan")
+	note := " " + importer.NormalizeCategory(draft.Note) + " "
+	best, bestLen := "", 0
+	for _, category := range categories {
+		name := importer.NormalizeCategory(category.Name)
+		if name != "" && len(name) > bestLen && strings.Contains(note, " "+name+" ") {
+			best, bestLen = category.ID, len(name)
+		}
+	}
+	if best != "" {
+		draft.CategoryID, resp.CategoryName, resp.CategorySource = best, names[best], "category_name"
+		return nil
+	}
+
+	suggestion, err := s.suggestions.SuggestCategory(ctx, userID, suggestionDto.SuggestCategoryRequest{
+		Note:   draft.Note,
+		Amount: draft.Amount,
+		Date:   draft.Date,
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(suggestion.Suggestions) > 0 && suggestion.Suggestions[0].Confidence >= minQuickAddConfidence {
+		top := suggestion.Suggestions[0]
+		draft.CategoryID, resp.CategoryName, resp.CategorySource = top.CategoryID, top.CategoryName, "model"
+		resp.Confidence = top.Confidence
+	}
+
+	return nil
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/receipt"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// ReceiptInbox mengatur alamat email penerima struk lewat listener SMTP. Domain kosong berarti
+// listener tidak aktif; Secret adalah kunci HMAC untuk alamat per user.
+type ReceiptInbox struct {
+	Domain string
+	Secret string
+}
+
+// Panjang tanda tangan (hex) di alamat inbox
+const inboxSignatureLength = 16
+
+// receiptUpload adalah file email yang belum disimpan ke storage.
+type receiptUpload struct {
+	file *entity.ReceiptFile
+	ext  string
+	data []byte
+}
+
+// IngestEmail membaca email struk mentah lalu menyimpannya sebagai draft yang menunggu ditinjau.
+// Email asli dan lampiran bergambar/PDF disimpan terenkripsi dan ikut menjadi lampiran transaksi
+// saat draft disetujui. source adalah upload atau smtp.
+func (s *transactionService) IngestEmail(ctx context.Context, userID uuid.UUID, source string, data []byte) (*entity.ReceiptDraft, error) {
+	size := int64(len(data))
+	if size == 0 {
+		return nil, errx.NewBadRequestError("Email is empty")
+	}
+	if s.limits.MaxSize > 0 && size > s.limits.MaxSize {
+		return nil, errx.NewPayloadTooLargeError(fmt.Sprintf("Email exceeds the maximum size of %d bytes", s.limits.MaxSize))
+	}
+
+	email, err := receipt.Parse(data)
+	if err != nil {
+		return nil, errx.NewBadRequestError(err.Error())
+	}
+	now := time.Now()
+	parsed := receipt.Extract(email, time.Local, now)
+
+	draft := &entity.ReceiptDraft{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Source:          source,
+		Template:        parsed.Template,
+		MessageKey:      messageKey(email, data),
+		Sender:          email.From,
+		Merchant:        parsed.Merchant,
+		TransactionType: parsed.TransactionType,
+		Date:            parsed.Date,
+		Note:            parsed.Note,
+		Status:          "pending",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if parsed.Amount > 0 {
+		draft.Amount = &parsed.Amount
+	}
+
+	files := []receiptUpload{{
+		file: &entity.ReceiptFile{
+			Kind:        "email",
+			Filename:    sanitizeFilename(emailFilename(email), ".eml"),
+			ContentType: "message/rfc822",
+		},
+		ext:  ".eml",
+		data: data,
+	}}
+	for _, f := range email.Attachments {
+		contentType := mimetype.Detect(f.Data).String()
+		if i := strings.Index(contentType, ";"); i >= 0 {
+			contentType = contentType[:i]
+		}
+		// Lampiran lain (misalnya .ics atau .zip) tidak disimpan
+		ext, ok := allowedAttachmentTypes[contentType]
+		if !ok || (s.limits.MaxSize > 0 && int64(len(f.Data)) > s.limits.MaxSize) {
+			continue
+		}
+		// Foto yang metadatanya tidak bisa dibuang juga dilewati
+		attachmentData, err := stripImageMetadata(f.Data, contentType)
+		if err != nil {
+			continue
+		}
+		files = append(files, receiptUpload{
+			file: &entity.ReceiptFile{
+				Kind:        "attachment",
+				Filename:    sanitizeFilename(f.Filename, ext),
+				ContentType: contentType,
+			},
+			ext:  ext,
+			data: attachmentData,
+		})
+	}
+
+	if s.limits.Quota > 0 {
+		var total int64
+		for _, f := range files {
+			total += int64(len(f.data))
+		}
+		used, err := s.repo.GetAttachmentUsage(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if used+total > s.limits.Quota {
+			return nil, errx.NewPayloadTooLargeError("Attachment storage quota exceeded")
+		}
+	}
+
+	// Kategori yang gagal ditebak tidak membatalkan email, user tetap bisa memilihnya saat meninjau
+	if err := s.guessReceiptCategory(ctx, draft); err != nil {
+		log.Printf("[Receipt] failed to guess category for draft %s: %v", draft.ID, err)
+	}
+
+	draft.Files = []*entity.ReceiptFile{}
+	for _, f := range files {
+		key, err := s.putAttachmentFile(ctx, userID, "receipts", f.data, f.ext, f.file.ContentType)
+		if err != nil {
+			log.Printf("[Storage] failed to store receipt file %s: %v", key, err)
+			s.removeReceiptFiles(ctx, draft.Files)
+			return nil, errx.NewInternalServerError("Failed to save email")
+		}
+		f.file.ID, f.file.DraftID, f.file.Size, f.file.StorageKey, f.file.CreatedAt = uuid.New(), draft.ID, int64(len(f.data)), key, now
+		draft.Files = append(draft.Files, f.file)
+	}
+
+	if err := s.receipts.CreateReceiptDraft(ctx, draft); err != nil {
+		s.removeReceiptFiles(ctx, draft.Files)
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+func (s *transactionService) GetReceiptDrafts(ctx context.Context, userID uuid.UUID, params dto.ReceiptDraftParams) ([]*entity.ReceiptDraft, error) {
+	status := params.Status
+	if status == "" {
+		status = "pending"
+	}
+
+	return s.receipts.GetReceiptDrafts(ctx, userID, status)
+}
+
+func (s *transactionService) GetReceiptDraft(ctx context.Context, userID, id uuid.UUID) (*entity.ReceiptDraft, error) {
+	return s.getOwnedReceiptDraft(ctx, userID, id)
+}
+
+// OpenReceiptFile membuka email asli atau lampiran draft yang belum disetujui; pemanggil wajib
+// menutup reader. Setelah disetujui file tersedia sebagai lampiran transaksi.
+func (s *transactionService) OpenReceiptFile(ctx context.Context, userID, draftID, fileID uuid.UUID) (*entity.ReceiptFile, io.ReadCloser, error) {
+	draft, err := s.getOwnedReceiptDraft(ctx, userID, draftID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, f := range draft.Files {
+		if f.ID != fileID {
+			continue
+		}
+
+		data, err := s.readAttachmentFile(ctx, userID, f.StorageKey)
+		if err == storage.ErrNotFound {
+			return nil, nil, errx.ErrReceiptFileNotFound
+		}
+		if err != nil {
+			log.Printf("[Storage] failed to read receipt file %s: %v", f.StorageKey, err)
+			return nil, nil, errx.ErrInternalServer
+		}
+		return f, io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return nil, nil, errx.ErrReceiptFileNotFound
+}
+
+// ApproveReceiptDraft menyimpan draft sebagai transaksi setelah dikoreksi user. Email asli dan
+// lampirannya menjadi lampiran transaksi sebagai bukti.
+func (s *transactionService) ApproveReceiptDraft(ctx context.Context, userID, id uuid.UUID, req dto.ApproveReceiptRequest) (*entity.Transaction, error) {
+	draft, err := s.getOwnedReceiptDraft(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != "pending" {
+		return nil, errx.ErrReceiptAlreadyReviewed
+	}
+
+	create := dto.CreateTransactionRequest{
+		TransactionType:  draft.TransactionType,
+		CategoryID:       draft.CategoryID,
+		Note:             draft.Note,
+		Period:           "daily",
+		Date:             draft.Date,
+		Tags:             req.Tags,
+		ConfirmDuplicate: req.ConfirmDuplicate,
+	}
+	if draft.Amount != nil {
+		create.Amount = *draft.Amount
+	}
+	if req.TransactionType != "" {
+		create.TransactionType = req.TransactionType
+	}
+	if req.Amount > 0 {
+		create.Amount = req.Amount
+	}
+	if req.CategoryID != "" {
+		create.CategoryID = req.CategoryID
+	}
+	if req.Note != nil {
+		create.Note = *req.Note
+	}
+	if req.Date != "" {
+		create.Date = req.Date
+	}
+	if create.Amount <= 0 {
+		return nil, errx.NewBadRequestError("amount is required because no total was found in the email")
+	}
+
+	tx, err := s.newTransaction(ctx, create, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, f := range draft.Files {
+		tx.Attachments = append(tx.Attachments, &entity.Attachment{
+			ID:            uuid.New(),
+			TransactionID: tx.ID,
+			UserID:        userID,
+			Filename:      f.Filename,
+			ContentType:   f.ContentType,
+			Size:          f.Size,
+			StorageKey:    f.StorageKey,
+			CreatedAt:     now,
+		})
+	}
+
+	if err := s.receipts.ApproveReceiptDraft(ctx, draft.ID, tx); err != nil {
+		return nil, err
+	}
+
+	if len(tx.Attachments) > 0 {
+		s.queueAttachmentWork()
+	}
+	s.learnCategory(ctx, tx)
+
+	return tx, nil
+}
+
+// RejectReceiptDraft menolak draft dan menghapus file email yang tidak dipakai lagi. Draft tetap
+// tercatat supaya email yang sama tidak masuk lagi ke antrean.
+func (s *transactionService) RejectReceiptDraft(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwnedReceiptDraft(ctx, userID, id); err != nil {
+		return err
+	}
+
+	keys, err := s.receipts.RejectReceiptDraft(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		s.removeOrphanedAttachment(ctx, key)
+	}
+
+	return nil
+}
+
+func (s *transactionService) GetReceiptInbox(ctx context.Context, userID uuid.UUID) *dto.ReceiptInboxResponse {
+	resp := &dto.ReceiptInboxResponse{
+		Enabled:   s.inbox.Domain != "",
+		Templates: []dto.ReceiptTemplate{},
+	}
+	if resp.Enabled {
+		resp.Address = fmt.Sprintf("%s.%s@%s", hex.EncodeToString(userID[:]), s.signInbox(userID), strings.ToLower(s.inbox.Domain))
+	}
+	for _, tmpl := range receipt.Templates() {
+		resp.Templates = append(resp.Templates, dto.ReceiptTemplate{Name: tmpl.Name, Merchant: tmpl.Merchant, Domains: tmpl.Domains})
+	}
+
+	return resp
+}
+
+// ResolveReceiptInbox mengembalikan pemilik alamat inbox dari GetReceiptInbox. Tag setelah "+"
+// di alamat diabaikan.
+func (s *transactionService) ResolveReceiptInbox(address string) (uuid.UUID, bool) {
+	if s.inbox.Domain == "" {
+		return uuid.Nil, false
+	}
+
+	local, domain, ok := strings.Cut(strings.ToLower(strings.Trim(address, "<> ")), "@")
+	if !ok || domain != strings.ToLower(s.inbox.Domain) {
+		return uuid.Nil, false
+	}
+	if i := strings.Index(local, "+"); i >= 0 {
+		local = local[:i]
+	}
+
+	rawID, signature, ok := strings.Cut(local, ".")
+	if !ok {
+		return uuid.Nil, false
+	}
+	raw, err := hex.DecodeString(rawID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signInbox(userID))) {
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+func (s *transactionService) signInbox(userID uuid.UUID) string {
+	mac := hmac.New(sha256.New, []byte(s.inbox.Secret))
+	mac.Write([]byte("receipt-inbox:" + userID.String()))
+	return hex.EncodeToString(mac.Sum(nil))[:inboxSignatureLength]
+}
+
+func (s *transactionService) getOwnedReceiptDraft(ctx context.Context, userID, id uuid.UUID) (*entity.ReceiptDraft, error) {
+	draft, err := s.receipts.GetReceiptDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, errx.ErrReceiptDraftNotFound
+	}
+	return draft, nil
+}
+
+// guessReceiptCategory memakai tebakan kategori quick-add dengan merchant dan subject email.
+// Kategori yang tidak yakin dibiarkan kosong untuk dipilih user saat meninjau draft.
+func (s *transactionService) guessReceiptCategory(ctx context.Context, draft *entity.ReceiptDraft) error {
+	guess := &dto.QuickAddResponse{Draft: dto.CreateTransactionRequest{
+		TransactionType: draft.TransactionType,
+		Note:            strings.TrimSpace(draft.Merchant + " " + draft.Note),
+		Date:            draft.Date,
+	}}
+	if draft.Amount != nil {
+		guess.Draft.Amount = *draft.Amount
+	}
+
+	if err := s.guessQuickAddCategory(ctx, draft.UserID, guess); err != nil {
+		return err
+	}
+	draft.CategoryID = guess.Draft.CategoryID
+
+	return nil
+}
+
+func (s *transactionService) removeReceiptFiles(ctx context.Context, files []*entity.ReceiptFile) {
+	for _, f := range files {
+		s.removeOrphanedAttachment(ctx, f.StorageKey)
+	}
+}
+
+// messageKey memakai Message-ID supaya email yang sama (termasuk yang diteruskan ulang) hanya
+// masuk sekali, atau hash isi email jika Message-ID tidak ada.
+func messageKey(email *receipt.Email, data []byte) string {
+	if id := email.MessageID; id != "" && len(id) <= 255 {
+		return id
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func emailFilename(email *receipt.Email) string {
+	if email.Subject != "" {
+		return email.Subject
+	}
+	return "email"
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/importer"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+const (
+	// Selisih tanggal maksimal antara baris rekening koran dan transaksi, karena bank sering
+	// membukukan transaksi satu sampai beberapa hari setelah dicatat
+	reconcileDateWindow = 3
+	// Periode maksimal satu rekening koran
+	maxReconciliationDays = 366
+)
+
+var errReconciliationLocked = errx.NewConflictError("Reconciliation is locked, unlock it first")
+
+// CreateReconciliation membaca rekening koran untuk satu periode, menyimpan barisnya lalu
+// mengembalikan laporan pencocokan dengan transaksi yang ada. Baris di luar periode diabaikan.
+func (s *transactionService) CreateReconciliation(ctx context.Context, userID uuid.UUID, data []byte, params dto.CreateReconciliationParams) (*dto.ReconciliationReport, error) {
+	start, _ := time.Parse("2006-01-02", params.StartDate)
+	end, _ := time.Parse("2006-01-02", params.EndDate)
+	if end.Before(start) {
+		return nil, errx.NewBadRequestError("start_date must not be after end_date")
+	}
+	if end.Sub(start) > maxReconciliationDays*24*time.Hour {
+		return nil, errx.NewBadRequestError(fmt.Sprintf("Period must not be longer than %d days", maxReconciliationDays))
+	}
+
+	imp, parsed, err := parseImportFile(params.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rec := &entity.Reconciliation{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Account:   strings.TrimSpace(params.Account),
+		Format:    imp.Name(),
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	errors := parsed.Errors
+	outside := 0
+	var lines []*entity.StatementLine
+	for _, record := range parsed.Records {
+		if record.Date < rec.StartDate || record.Date > rec.EndDate {
+			outside++
+			continue
+		}
+		if record.Amount == 0 {
+			errors = append(errors, importer.RowError{Line: record.Line, Message: "amount must not be zero"})
+			continue
+		}
+
+		lines = append(lines, &entity.StatementLine{
+			ID:               uuid.New(),
+			ReconciliationID: rec.ID,
+			Line:             record.Line,
+			ExternalID:       record.ExternalID,
+			Date:             record.Date,
+			Amount:           record.Amount,
+			Note:             record.Note,
+			Category:         truncateRunes(strings.TrimSpace(record.Category), 100),
+		})
+	}
+	if len(lines) == 0 {
+		return nil, errx.NewBadRequestError("Statement has no transactions between start_date and end_date")
+	}
+
+	if err := s.reconciliations.CreateReconciliation(ctx, rec, lines); err != nil {
+		return nil, err
+	}
+
+	report, err := s.reconciliationReport(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	report.OutsidePeriod = outside
+	report.Errors = errors
+
+	return report, nil
+}
+
+func (s *transactionService) GetReconciliations(ctx context.Context, userID uuid.UUID) ([]*entity.Reconciliation, error) {
+	return s.reconciliations.GetReconciliations(ctx, userID)
+}
+
+func (s *transactionService) GetReconciliation(ctx context.Context, userID, id uuid.UUID) (*dto.ReconciliationReport, error) {
+	rec, err := s.getOwnedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationReport(ctx, rec)
+}
+
+// ConfirmStatementMatches menyimpan pasangan baris rekening koran dan transaksi, baik dari saran
+// laporan maupun pilihan user sendiri. Nominal dan tipe keduanya harus sama.
+func (s *transactionService) ConfirmStatementMatches(ctx context.Context, userID, id uuid.UUID, req dto.ConfirmMatchesRequest) (*dto.ReconciliationReport, error) {
+	rec, err := s.getUnlockedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.reconciliations.GetStatementLines(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	lineByID := map[uuid.UUID]*entity.StatementLine{}
+	for _, line := range lines {
+		lineByID[line.ID] = line
+	}
+
+	txIDs := make([]uuid.UUID, 0, len(req.Matches))
+	for _, m := range req.Matches {
+		txIDs = append(txIDs, uuid.MustParse(m.TransactionID))
+	}
+	txs, err := s.repo.GetTransactionsByIDs(ctx, txIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := map[uuid.UUID]uuid.UUID{}
+	usedTx := map[uuid.UUID]bool{}
+	for _, m := range req.Matches {
+		line := lineByID[uuid.MustParse(m.LineID)]
+		if line == nil {
+			return nil, errx.ErrStatementLineNotFound
+		}
+		tx := txs[uuid.MustParse(m.TransactionID)]
+		if tx == nil || tx.UserID != userID {
+			return nil, errx.ErrTransactionNotFound
+		}
+		if _, dup := matches[line.ID]; dup || usedTx[tx.ID] {
+			return nil, errx.NewBadRequestError("Each statement line and transaction can only be matched once")
+		}
+		if !sameAmount(line.Amount, signedAmount(tx)) {
+			return nil, errx.NewBadRequestError(fmt.Sprintf("Amount of statement line %d does not match the transaction", line.Line))
+		}
+
+		matches[line.ID] = tx.ID
+		usedTx[tx.ID] = true
+	}
+
+	if err := s.reconciliations.ConfirmStatementMatches(ctx, rec.ID, matches); err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationReport(ctx, rec)
+}
+
+func (s *transactionService) UnmatchStatementLine(ctx context.Context, userID, id, lineID uuid.UUID) (*dto.ReconciliationReport, error) {
+	rec, err := s.getUnlockedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reconciliations.UnmatchStatementLine(ctx, rec.ID, lineID); err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationReport(ctx, rec)
+}
+
+// CreateFromStatement membuat transaksi untuk baris rekening koran yang belum tercatat lalu
+// langsung memasangkannya. Kategori ditentukan seperti import, dengan category_id sebagai cadangan.
+func (s *transactionService) CreateFromStatement(ctx context.Context, userID, id uuid.UUID, req dto.CreateFromStatementRequest) (*dto.ReconciliationReport, error) {
+	rec, err := s.getUnlockedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.reconciliationReport(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := report.UnmatchedStatement
+	if len(req.LineIDs) > 0 {
+		// Baris dengan saran pasangan juga boleh dipilih, misalnya jika sarannya keliru
+		open := map[uuid.UUID]*entity.StatementLine{}
+		for _, line := range report.UnmatchedStatement {
+			open[line.ID] = line
+		}
+		for _, m := range report.Matched {
+			if !m.Confirmed {
+				open[m.Line.ID] = m.Line
+			}
+		}
+
+		targets = nil
+		for _, raw := range req.LineIDs {
+			line, ok := open[uuid.MustParse(raw)]
+			if !ok {
+				return nil, errx.NewBadRequestError(fmt.Sprintf("Statement line %s does not exist or is already matched", raw))
+			}
+			delete(open, line.ID)
+			targets = append(targets, line)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errx.NewBadRequestError("There are no unmatched statement lines")
+	}
+
+	categorizer, err := s.newImportCategorizer(ctx, userID, rec.Format)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != "" && !categorizer.exists(req.CategoryID) {
+		return nil, errx.ErrCategoryNotFound
+	}
+
+	externalIDs := make([]string, 0, len(targets))
+	for _, line := range targets {
+		externalIDs = append(externalIDs, line.ExternalID)
+	}
+	imported, err := s.repo.GetImportedExternalIDs(ctx, userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	txs := map[uuid.UUID]*entity.Transaction{}
+	var uncategorized []string
+	for _, line := range targets {
+		// External ID yang sudah dipakai transaksi lain (misalnya yang ada di trash) tidak dipakai ulang
+		externalID := line.ExternalID
+		if imported[externalID] {
+			externalID = ""
+		}
+
+		tx := newImportedTransaction(userID, line.Date, line.Amount, line.Note, externalID, now)
+		categorizer.apply(tx, line.Category)
+		if tx.CategoryID == "" {
+			tx.CategoryID = req.CategoryID
+		}
+		if tx.CategoryID == "" {
+			uncategorized = append(uncategorized, fmt.Sprint(line.Line))
+			continue
+		}
+		txs[line.ID] = tx
+	}
+	if len(uncategorized) > 0 {
+		return nil, errx.NewBadRequestError("category_id is required, no category matches statement lines " + strings.Join(uncategorized, ", "))
+	}
+
+	if err := s.reconciliations.CreateFromStatement(ctx, rec.ID, txs); err != nil {
+		return nil, err
+	}
+	s.retrainAfterImport(ctx, userID)
+
+	return s.reconciliationReport(ctx, rec)
+}
+
+// LockReconciliation mengunci periode setelah semua baris rekening koran punya pasangan yang
+// dikonfirmasi. Transaksi tanpa pasangan di rekening koran tidak menghalangi kunci, karena
+// bisa saja berasal dari rekening lain.
+func (s *transactionService) LockReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error) {
+	rec, err := s.getOwnedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.LockedAt != nil {
+		return rec, nil
+	}
+
+	report, err := s.reconciliationReport(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	pending := len(report.UnmatchedStatement)
+	for _, m := range report.Matched {
+		if !m.Confirmed {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return nil, errx.NewConflictError(fmt.Sprintf("%d statement lines are not reconciled yet", pending))
+	}
+
+	now := time.Now()
+	if err := s.reconciliations.SetReconciliationLock(ctx, rec.ID, &now); err != nil {
+		return nil, err
+	}
+	rec.LockedAt = &now
+	rec.UpdatedAt = now
+
+	return rec, nil
+}
+
+func (s *transactionService) UnlockReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error) {
+	rec, err := s.getOwnedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reconciliations.SetReconciliationLock(ctx, rec.ID, nil); err != nil {
+		return nil, err
+	}
+	rec.LockedAt = nil
+	rec.UpdatedAt = time.Now()
+
+	return rec, nil
+}
+
+func (s *transactionService) DeleteReconciliation(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getUnlockedReconciliation(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.reconciliations.DeleteReconciliation(ctx, id)
+}
+
+func (s *transactionService) getOwnedReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error) {
+	rec, err := s.reconciliations.GetReconciliation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.UserID != userID {
+		return nil, errx.ErrReconciliationNotFound
+	}
+	return rec, nil
+}
+
+func (s *transactionService) getUnlockedReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error) {
+	rec, err := s.getOwnedReconciliation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.LockedAt != nil {
+		return nil, errReconciliationLocked
+	}
+	return rec, nil
+}
+
+// reconciliationReport menyusun pasangan yang sudah dikonfirmasi, saran pasangan untuk baris
+// lainnya dari transaksi saat ini, serta baris dan transaksi yang tidak punya pasangan.
+func (s *transactionService) reconciliationReport(ctx context.Context, rec *entity.Reconciliation) (*dto.ReconciliationReport, error) {
+	lines, err := s.reconciliations.GetStatementLines(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.reconciliations.GetLedger(ctx, rec.UserID, rec.ID,
+		shiftDate(rec.StartDate, -reconcileDateWindow), shiftDate(rec.EndDate, reconcileDateWindow))
+	if err != nil {
+		return nil, err
+	}
+	txByID := map[uuid.UUID]*entity.Transaction{}
+	for _, tx := range ledger {
+		txByID[tx.ID] = tx
+	}
+
+	// Pasangan yang dikonfirmasi bisa bertanggal di luar jendela pencocokan
+	var missing []uuid.UUID
+	for _, line := range lines {
+		if line.TransactionID != nil && txByID[*line.TransactionID] == nil {
+			missing = append(missing, *line.TransactionID)
+		}
+	}
+	if len(missing) > 0 {
+		extra, err := s.repo.GetTransactionsByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for id, tx := range extra {
+			txByID[id] = tx
+		}
+	}
+
+	report := &dto.ReconciliationReport{
+		Reconciliation:     rec,
+		Matched:            []dto.ReconciliationMatch{},
+		UnmatchedStatement: []*entity.StatementLine{},
+		UnmatchedLedger:    []*entity.Transaction{},
+	}
+
+	used := map[uuid.UUID]bool{}
+	var open []*entity.StatementLine
+	for _, line := range lines {
+		report.StatementNet += line.Amount
+
+		// Pasangan yang transaksinya sudah dihapus dianggap belum berpasangan
+		if line.TransactionID != nil {
+			if tx := txByID[*line.TransactionID]; tx != nil {
+				report.Matched = append(report.Matched, dto.ReconciliationMatch{
+					Line: line, Transaction: tx, Score: matchScore(line, tx), Confirmed: true,
+				})
+				used[tx.ID] = true
+				continue
+			}
+		}
+		open = append(open, line)
+	}
+
+	var available []*entity.Transaction
+	for _, tx := range ledger {
+		if !used[tx.ID] {
+			available = append(available, tx)
+		}
+	}
+
+	suggested := suggestMatches(open, available)
+	for _, m := range suggested {
+		used[m.Transaction.ID] = true
+	}
+	report.Matched = append(report.Matched, suggested...)
+
+	suggestedLines := map[uuid.UUID]bool{}
+	for _, m := range suggested {
+		suggestedLines[m.Line.ID] = true
+	}
+	for _, line := range open {
+		if !suggestedLines[line.ID] {
+			report.UnmatchedStatement = append(report.UnmatchedStatement, line)
+		}
+	}
+
+	for _, tx := range available {
+		date := dateOnly(tx.Date)
+		if !used[tx.ID] && date >= rec.StartDate && date <= rec.EndDate {
+			report.UnmatchedLedger = append(report.UnmatchedLedger, tx)
+		}
+	}
+
+	// Net ledger dihitung dari transaksi yang dipasangkan dan yang tidak punya pasangan,
+	// sehingga sama dengan net rekening koran jika semuanya sudah cocok
+	for _, m := range report.Matched {
+		report.LedgerNet += signedAmount(m.Transaction)
+	}
+	for _, tx := range report.UnmatchedLedger {
+		report.LedgerNet += signedAmount(tx)
+	}
+	report.StatementNet = roundCents(report.StatementNet)
+	report.LedgerNet = roundCents(report.LedgerNet)
+
+	sort.SliceStable(report.Matched, func(i, j int) bool {
+		return report.Matched[i].Line.Line < report.Matched[j].Line.Line
+	})
+
+	return report, nil
+}
+
+// suggestMatches memasangkan baris rekening koran dengan transaksi yang nominal dan tipenya
+// sama dan tanggalnya berdekatan. Pasangan dengan skor tertinggi dipilih lebih dulu, dan setiap
+// baris maupun transaksi hanya dipakai sekali.
+func suggestMatches(lines []*entity.StatementLine, ledger []*entity.Transaction) []dto.ReconciliationMatch {
+	type candidate struct {
+		line  *entity.StatementLine
+		tx    *entity.Transaction
+		score float64
+		days  int
+	}
+
+	var candidates []candidate
+	for _, line := range lines {
+		for _, tx := range ledger {
+			if !sameAmount(line.Amount, signedAmount(tx)) {
+				continue
+			}
+			days := daysApart(line.Date, tx.Date)
+			sameSource := line.ExternalID != "" && line.ExternalID == tx.ExternalID
+			if days > reconcileDateWindow && !sameSource {
+				continue
+			}
+			candidates = append(candidates, candidate{line: line, tx: tx, score: matchScore(line, tx), days: days})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.days != b.days {
+			return a.days < b.days
+		}
+		return a.line.Line < b.line.Line
+	})
+
+	usedLines := map[uuid.UUID]bool{}
+	usedTx := map[uuid.UUID]bool{}
+	var matches []dto.ReconciliationMatch
+	for _, c := range candidates {
+		if usedLines[c.line.ID] || usedTx[c.tx.ID] {
+			continue
+		}
+		usedLines[c.line.ID] = true
+		usedTx[c.tx.ID] = true
+		matches = append(matches, dto.ReconciliationMatch{Line: c.line, Transaction: c.tx, Score: c.score})
+	}
+
+	return matches
+}
+
+// matchScore bernilai 1 untuk transaksi yang diimport dari baris yang sama, selain itu
+// gabungan kedekatan tanggal (70%) dan kemiripan note (30%).
+func matchScore(line *entity.StatementLine, tx *entity.Transaction) float64 {
+	if line.ExternalID != "" && line.ExternalID == tx.ExternalID {
+		return 1
+	}
+
+	days := daysApart(line.Date, tx.Date)
+	dateScore := 1 - float64(days)/float64(reconcileDateWindow+1)
+	if dateScore < 0 {
+		dateScore = 0
+	}
+
+	return math.Round((0.7*dateScore+0.3*noteSimilarity(line.Note, tx.Note))*100) / 100
+}
+
+func signedAmount(tx *entity.Transaction) float64 {
+	if tx.TransactionType == "expense" {
+		return -tx.Amount
+	}
+	return tx.Amount
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func roundCents(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func shiftDate(date string, days int) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+// GetHistory mengembalikan setiap revisi beserta field yang berubah menuju kondisi berikutnya.
+// Revisi terakhir dibandingkan dengan kondisi transaksi saat ini.
+func (s *transactionService) GetHistory(ctx context.Context, userID, id uuid.UUID) ([]dto.RevisionDiff, error) {
+	tx, err := s.getOwnedTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.GetRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]dto.RevisionDiff, 0, len(revisions))
+	for i, rev := range revisions {
+		next := tx.Snapshot()
+		if i+1 < len(revisions) {
+			next = revisions[i+1].Snapshot
+		}
+
+		history = append(history, dto.RevisionDiff{
+			Version:   rev.Version,
+			ChangedBy: rev.ChangedBy,
+			ChangedAt: rev.CreatedAt,
+			Before:    rev.Snapshot,
+			Changes:   diffSnapshots(rev.Snapshot, next),
+		})
+	}
+
+	return history, nil
+}
+
+// RevertTransaction mengembalikan transaksi ke kondisi sebelum perubahan versi tertentu.
+// Revert sendiri dicatat sebagai revisi baru sehingga bisa di-undo lagi.
+func (s *transactionService) RevertTransaction(ctx context.Context, userID, id uuid.UUID, req dto.RevertTransactionRequest) (*entity.Transaction, error) {
+	tx, err := s.getOwnedTransaction(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := s.repo.GetRevision(ctx, id, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *tx
+	snap := rev.Snapshot
+	tx.TransactionType = snap.TransactionType
+	tx.Amount = snap.Amount
+	tx.CategoryID = snap.CategoryID
+	if snap.Period != "" {
+		tx.Period = snap.Period
+	}
+	tx.Note = snap.Note
+	tx.Date = snap.Date
+	tx.Tags = snap.Tags
+	tx.UpdatedAt = time.Now()
+	if err := checkRepaymentChange(&before, tx); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTransaction(ctx, tx, newRevision(&before, userID)); err != nil {
+		return nil, err
+	}
+
+	s.forgetCategory(ctx, &before)
+	s.learnCategory(ctx, tx)
+
+	return tx, nil
+}
+
+func (s *transactionService) getOwnedTransaction(ctx context.Context, userID, id uuid.UUID) (*entity.Transaction, error) {
+	tx, err := s.repo.GetTransactionByID(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this transaction")
+	}
+
+	return tx, nil
+}
+
+func newRevision(before *entity.Transaction, changedBy uuid.UUID) *entity.Revision {
+	return &entity.Revision{
+		ID:            uuid.New(),
+		TransactionID: before.ID,
+		ChangedBy:     &changedBy,
+		Snapshot:      before.Snapshot(),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func diffSnapshots(from, to entity.Snapshot) []dto.FieldChange {
+	changes := []dto.FieldChange{}
+	add := func(field string, a, b interface{}) {
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			changes = append(changes, dto.FieldChange{Field: field, From: a, To: b})
+		}
+	}
+
+	add("transaction_type", from.TransactionType, to.TransactionType)
+	add("amount", from.Amount, to.Amount)
+	add("category_id", from.CategoryID, to.CategoryID)
+	add("period", from.Period, to.Period)
+	add("note", from.Note, to.Note)
+	add("date", from.Date, to.Date)
+	if strings.Join(from.Tags, ",") != strings.Join(to.Tags, ",") {
+		changes = append(changes, dto.FieldChange{Field: "tags", From: from.Tags, To: to.Tags})
+	}
+
+	return changes
+}
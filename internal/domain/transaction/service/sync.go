@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	categoryEntity "github.com/kenziehh/cashflow-be/internal/domain/category/entity"
+	maximumSpendEntity "github.com/kenziehh/cashflow-be/internal/domain/maximum_spend/entity"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
+	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
+	"github.com/kenziehh/cashflow-be/pkg/errx"
+)
+
+const (
+	defaultSyncLimit = 500
+	// Token v1 hanya berisi nomor urut dan dibaca sebagai cursor dengan xid 0
+	syncTokenPrefixV1 = "v1:"
+	syncTokenPrefix   = "v2:"
+)
+
+// Sync mengembalikan perubahan sejak token sebelumnya beserta token baru. Token kosong
+// berarti sync penuh yang hanya berisi data yang masih ada.
+func (s *transactionService) Sync(ctx context.Context, userID uuid.UUID, params dto.SyncParams) (dto.SyncResponse, error) {
+	since, err := decodeSyncToken(params.Since)
+	if err != nil {
+		return dto.SyncResponse{}, err
+	}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = defaultSyncLimit
+	}
+
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	changes, err := s.repo.GetChangesSince(ctx, userID, since, limit+1)
+	if err != nil {
+		return dto.SyncResponse{}, err
+	}
+
+	resp := dto.SyncResponse{
+		Transactions: dto.SyncTransactionChanges{Created: []*entity.Transaction{}, Updated: []*entity.Transaction{}, Deleted: []string{}},
+		Categories:   dto.SyncCategoryChanges{Created: []*categoryEntity.Category{}, Updated: []*categoryEntity.Category{}, Deleted: []string{}},
+		Budgets:      dto.SyncBudgetChanges{Created: []*maximumSpendEntity.MaximumSpend{}, Updated: []*maximumSpendEntity.MaximumSpend{}, Deleted: []string{}},
+	}
+
+	if len(changes) > limit {
+		changes = changes[:limit]
+		resp.HasMore = true
+	}
+
+	last := since
+	for _, c := range changes {
+		last = c.Cursor
+		switch c.Entity {
+		case entity.SyncTransaction:
+			switch {
+			case c.Deleted:
+				resp.Transactions.Deleted = append(resp.Transactions.Deleted, c.EntityID)
+			case c.Created:
+				resp.Transactions.Created = append(resp.Transactions.Created, c.Transaction)
+			default:
+				resp.Transactions.Updated = append(resp.Transactions.Updated, c.Transaction)
+			}
+		case entity.SyncCategory:
+			switch {
+			case c.Deleted:
+				resp.Categories.Deleted = append(resp.Categories.Deleted, c.EntityID)
+			case c.Created:
+				resp.Categories.Created = append(resp.Categories.Created, c.Category)
+			default:
+				resp.Categories.Updated = append(resp.Categories.Updated, c.Category)
+			}
+		case entity.SyncBudget:
+			switch {
+			case c.Deleted:
+				resp.Budgets.Deleted = append(resp.Budgets.Deleted, c.EntityID)
+			case c.Created:
+				resp.Budgets.Created = append(resp.Budgets.Created, c.Budget)
+			default:
+				resp.Budgets.Updated = append(resp.Budgets.Updated, c.Budget)
+			}
+		}
+	}
+
+	resp.Token = encodeSyncToken(last)
+	return resp, nil
+}
+
+// Token sync bersifat opaque bagi client, isinya posisi perubahan terakhir yang terkirim.
+func encodeSyncToken(cursor entity.SyncCursor) string {
+	raw := syncTokenPrefix + strconv.FormatInt(cursor.Xid, 10) + ":" + strconv.FormatInt(cursor.Seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSyncToken(token string) (entity.SyncCursor, error) {
+	if token == "" {
+		return entity.SyncCursor{}, nil
+	}
+
+	invalid := errx.NewBadRequestError("Invalid sync token")
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return entity.SyncCursor{}, invalid
+	}
+
+	var xid, seq string
+	switch {
+	case strings.HasPrefix(string(raw), syncTokenPrefix):
+		var ok bool
+		if xid, seq, ok = strings.Cut(strings.TrimPrefix(string(raw), syncTokenPrefix), ":"); !ok {
+			return entity.SyncCursor{}, invalid
+		}
+	case strings.HasPrefix(string(raw), syncTokenPrefixV1):
+		xid, seq = "0", strings.TrimPrefix(string(raw), syncTokenPrefixV1)
+	default:
+		return entity.SyncCursor{}, invalid
+	}
+
+	var cursor entity.SyncCursor
+	if cursor.Xid, err = strconv.ParseInt(xid, 10, 64); err != nil || cursor.Xid < 0 {
+		return entity.SyncCursor{}, invalid
+	}
+	if cursor.Seq, err = strconv.ParseInt(seq, 10, 64); err != nil || cursor.Seq < 0 {
+		return entity.SyncCursor{}, invalid
+	}
+
+	return cursor, nil
+}
@@ -2,35 +2,144 @@ package service
 
 import (
 	"context"
+	"io"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
+	ruleEntity "github.com/kenziehh/cashflow-be/internal/domain/rule/entity"
+	ruleService "github.com/kenziehh/cashflow-be/internal/domain/rule/service"
+	suggestionEntity "github.com/kenziehh/cashflow-be/internal/domain/suggestion/entity"
+	suggestionService "github.com/kenziehh/cashflow-be/internal/domain/suggestion/service"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/dto"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/entity"
 	"github.com/kenziehh/cashflow-be/internal/domain/transaction/repository"
+	"github.com/kenziehh/cashflow-be/internal/infra/encryption"
+	"github.com/kenziehh/cashflow-be/internal/infra/storage"
 	"github.com/kenziehh/cashflow-be/pkg/errx"
 )
 
 type TransactionService interface {
-	CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID uuid.UUID, proofFilePath string) (*entity.Transaction, error)
+	// upload boleh nil; jika diisi, file disimpan sebagai lampiran pertama transaksi
+	CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID uuid.UUID, upload *dto.AttachmentUpload) (*entity.Transaction, error)
 	GetTransactionByID(ctx context.Context, id uuid.UUID) (*entity.Transaction, error)
-	UpdateTransaction(ctx context.Context, id uuid.UUID, req dto.UpdateTransactionRequest,proofFilePath string) (*entity.Transaction, error)
-	DeleteTransaction(ctx context.Context, id uuid.UUID) error
+	// version adalah versi dari header If-Match, perubahan ditolak jika sudah tidak sama
+	UpdateTransaction(ctx context.Context, id uuid.UUID, req dto.UpdateTransactionRequest, changedBy uuid.UUID, version int) (*entity.Transaction, error)
+	PatchTransaction(ctx context.Context, id uuid.UUID, req dto.PatchTransactionRequest, changedBy uuid.UUID, version int) (*entity.Transaction, error)
+	DeleteTransaction(ctx context.Context, id uuid.UUID, version int) error
 	GetTransactionsWithPagination(ctx context.Context, userID uuid.UUID, params dto.TransactionListParams) (dto.PaginatedTransactionsResponse, error)
 	GetSummaryTransaction(ctx context.Context, userID uuid.UUID) (dto.SummaryTransactionResponse, error)
+	FindDuplicates(ctx context.Context, userID uuid.UUID, params dto.DuplicateScanParams) ([]dto.DuplicatePair, error)
+	// version adalah versi transaksi yang dipertahankan dari header If-Match
+	MergeTransactions(ctx context.Context, userID, keepID uuid.UUID, version int, req dto.MergeTransactionsRequest) (*entity.Transaction, error)
+	GetTrash(ctx context.Context, userID uuid.UUID) ([]dto.TrashedTransaction, error)
+	RestoreTransaction(ctx context.Context, userID, id uuid.UUID) (*entity.Transaction, error)
+	PurgeTrash(ctx context.Context) (int, error)
+	GetHistory(ctx context.Context, userID, id uuid.UUID) ([]dto.RevisionDiff, error)
+	RevertTransaction(ctx context.Context, userID, id uuid.UUID, req dto.RevertTransactionRequest) (*entity.Transaction, error)
+	BatchTransactions(ctx context.Context, userID uuid.UUID, req dto.BatchTransactionRequest) ([]dto.BatchOperationResult, error)
+	Sync(ctx context.Context, userID uuid.UUID, params dto.SyncParams) (dto.SyncResponse, error)
+	ListAttachments(ctx context.Context, userID, transactionID uuid.UUID) ([]*entity.Attachment, error)
+	AddAttachment(ctx context.Context, userID, transactionID uuid.UUID, upload dto.AttachmentUpload) (*entity.Attachment, error)
+	// size salah satu dari thumb, medium atau original (default); lampiran yang dikembalikan sesuai ukuran yang dibuka
+	OpenAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, size string) (*entity.Attachment, io.ReadCloser, error)
+	RemoveAttachment(ctx context.Context, userID, transactionID, attachmentID uuid.UUID) error
+	CreateAttachmentLink(ctx context.Context, userID, transactionID, attachmentID uuid.UUID, req dto.CreateAttachmentLinkRequest) (*dto.AttachmentLinkResponse, error)
+	OpenSignedAttachment(ctx context.Context, attachmentID uuid.UUID, params dto.SignedAttachmentParams) (*entity.Attachment, io.ReadCloser, error)
+	RunAttachmentWorker(ctx context.Context, interval time.Duration)
+	ImportTransactions(ctx context.Context, userID uuid.UUID, data []byte, params dto.ImportParams) (*dto.ImportResult, error)
+	GetCategoryMappings(ctx context.Context, userID uuid.UUID, params dto.CategoryMappingParams) ([]*entity.CategoryMapping, error)
+	// SaveCategoryMapping membuat atau mengganti mapping untuk kategori sumber yang sama
+	SaveCategoryMapping(ctx context.Context, userID uuid.UUID, req dto.CategoryMappingRequest) (*entity.CategoryMapping, error)
+	DeleteCategoryMapping(ctx context.Context, userID uuid.UUID, params dto.DeleteCategoryMappingParams) error
+	CreateReconciliation(ctx context.Context, userID uuid.UUID, data []byte, params dto.CreateReconciliationParams) (*dto.ReconciliationReport, error)
+	GetReconciliations(ctx context.Context, userID uuid.UUID) ([]*entity.Reconciliation, error)
+	GetReconciliation(ctx context.Context, userID, id uuid.UUID) (*dto.ReconciliationReport, error)
+	ConfirmStatementMatches(ctx context.Context, userID, id uuid.UUID, req dto.ConfirmMatchesRequest) (*dto.ReconciliationReport, error)
+	UnmatchStatementLine(ctx context.Context, userID, id, lineID uuid.UUID) (*dto.ReconciliationReport, error)
+	CreateFromStatement(ctx context.Context, userID, id uuid.UUID, req dto.CreateFromStatementRequest) (*dto.ReconciliationReport, error)
+	LockReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error)
+	UnlockReconciliation(ctx context.Context, userID, id uuid.UUID) (*entity.Reconciliation, error)
+	DeleteReconciliation(ctx context.Context, userID, id uuid.UUID) error
+	QuickAdd(ctx context.Context, userID uuid.UUID, req dto.QuickAddRequest) (*dto.QuickAddResponse, error)
+	// source adalah upload atau smtp
+	IngestEmail(ctx context.Context, userID uuid.UUID, source string, data []byte) (*entity.ReceiptDraft, error)
+	GetReceiptDrafts(ctx context.Context, userID uuid.UUID, params dto.ReceiptDraftParams) ([]*entity.ReceiptDraft, error)
+	GetReceiptDraft(ctx context.Context, userID, id uuid.UUID) (*entity.ReceiptDraft, error)
+	OpenReceiptFile(ctx context.Context, userID, draftID, fileID uuid.UUID) (*entity.ReceiptFile, io.ReadCloser, error)
+	ApproveReceiptDraft(ctx context.Context, userID, id uuid.UUID, req dto.ApproveReceiptRequest) (*entity.Transaction, error)
+	RejectReceiptDraft(ctx context.Context, userID, id uuid.UUID) error
+	GetReceiptInbox(ctx context.Context, userID uuid.UUID) *dto.ReceiptInboxResponse
+	// ResolveReceiptInbox mengembalikan pemilik alamat inbox struk, false jika alamat tidak dikenal
+	ResolveReceiptInbox(address string) (uuid.UUID, bool)
 }
 
 type transactionService struct {
-	repo repository.TransactionRepository
+	repo            repository.TransactionRepository
+	reconciliations repository.ReconciliationRepository
+	receipts        repository.ReceiptRepository
+	rules           ruleService.RuleService
+	suggestions     suggestionService.SuggestionService
+	storage         storage.FileStorage
+	keyring         *encryption.Keyring
+	limits          AttachmentLimits
+	links           AttachmentLinks
+	inbox           ReceiptInbox
+	trashRetention  time.Duration
+
+	attachmentQueued chan struct{}
 }
 
-func NewTransactionService(repo repository.TransactionRepository) TransactionService {
+func NewTransactionService(repo repository.TransactionRepository, reconciliations repository.ReconciliationRepository, receipts repository.ReceiptRepository, rules ruleService.RuleService, suggestions suggestionService.SuggestionService, storage storage.FileStorage, keyring *encryption.Keyring, limits AttachmentLimits, links AttachmentLinks, inbox ReceiptInbox, trashRetention time.Duration) TransactionService {
 	return &transactionService{
-		repo: repo,
+		repo:            repo,
+		reconciliations: reconciliations,
+		receipts:        receipts,
+		rules:           rules,
+		suggestions:     suggestions,
+		storage:         storage,
+		keyring:         keyring,
+		limits:          limits,
+		links:           links,
+		inbox:           inbox,
+		trashRetention:  trashRetention,
+
+		attachmentQueued: make(chan struct{}, 1),
 	}
 }
 
-func (s *transactionService) CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID uuid.UUID, proofPath string) (*entity.Transaction, error) {
+func (s *transactionService) CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID uuid.UUID, upload *dto.AttachmentUpload) (*entity.Transaction, error) {
+	tx, err := s.newTransaction(ctx, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if upload != nil {
+		attachment, err := s.storeAttachment(ctx, tx, *upload)
+		if err != nil {
+			return nil, err
+		}
+		tx.Attachments = []*entity.Attachment{attachment}
+	}
+
+	if err := s.repo.CreateTransaction(ctx, tx); err != nil {
+		for _, attachment := range tx.Attachments {
+			s.removeOrphanedAttachment(ctx, attachment.StorageKey)
+		}
+		return nil, err
+	}
+
+	if len(tx.Attachments) > 0 {
+		s.queueAttachmentWork()
+	}
+	s.learnCategory(ctx, tx)
+
+	return tx, nil
+}
+
+// newTransaction membentuk transaksi baru dari request lalu menjalankan rule dan
+// pengecekan duplikat, tanpa menyimpannya.
+func (s *transactionService) newTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID uuid.UUID) (*entity.Transaction, error) {
 	now := time.Now()
 
 	tx := &entity.Transaction{
@@ -42,15 +151,29 @@ func (s *transactionService) CreateTransaction(ctx context.Context, req dto.Crea
 		Period:          req.Period,
 		Note:            req.Note,
 		Date:            req.Date,
-		ProofFile:       proofPath,
+		Tags:            req.Tags,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 
-	if err := s.repo.CreateTransaction(ctx, tx); err != nil {
+	if err := s.applyRules(ctx, tx, req.CategoryID != ""); err != nil {
 		return nil, err
 	}
 
+	if tx.CategoryID == "" {
+		return nil, errx.NewBadRequestError("category_id is required when no rule matches")
+	}
+
+	if !req.ConfirmDuplicate {
+		candidates, err := s.duplicateCandidates(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			return nil, &DuplicateWarningError{Candidates: candidates}
+		}
+	}
+
 	return tx, nil
 }
 
@@ -66,7 +189,7 @@ func (s *transactionService) GetTransactionByID(ctx context.Context, id uuid.UUI
 	return tx, nil
 }
 
-func (s *transactionService) UpdateTransaction(ctx context.Context, id uuid.UUID, req dto.UpdateTransactionRequest, proofPath string) (*entity.Transaction, error) {
+func (s *transactionService) UpdateTransaction(ctx context.Context, id uuid.UUID, req dto.UpdateTransactionRequest, changedBy uuid.UUID, version int) (*entity.Transaction, error) {
 	tx, err := s.repo.GetTransactionByID(ctx, id.String())
 	if err != nil {
 		return nil, err
@@ -74,7 +197,27 @@ func (s *transactionService) UpdateTransaction(ctx context.Context, id uuid.UUID
 	if tx == nil {
 		return nil, errx.ErrTransactionNotFound
 	}
+	if tx.Version != version {
+		return nil, errx.ErrVersionMismatch
+	}
+	before := *tx
+
+	applyUpdate(tx, req)
+	if err := checkRepaymentChange(&before, tx); err != nil {
+		return nil, err
+	}
 
+	if err := s.repo.UpdateTransaction(ctx, tx, newRevision(&before, changedBy)); err != nil {
+		return nil, err
+	}
+
+	s.forgetCategory(ctx, &before)
+	s.learnCategory(ctx, tx)
+
+	return tx, nil
+}
+
+func applyUpdate(tx *entity.Transaction, req dto.UpdateTransactionRequest) {
 	// Update fields (hanya jika ada perubahan)
 	if req.Amount != 0 {
 		tx.Amount = req.Amount
@@ -91,23 +234,99 @@ func (s *transactionService) UpdateTransaction(ctx context.Context, id uuid.UUID
 	if req.Date != "" {
 		tx.Date = req.Date
 	}
+	if req.Period != "" {
+		tx.Period = req.Period
+	}
+	if req.Tags != nil {
+		tx.Tags = req.Tags
+	}
+
+	tx.UpdatedAt = time.Now()
+}
+
+// checkRepaymentChange menolak perubahan nominal, tipe dan tanggal cicilan hutang. Sisa saldo
+// hutang hanya dicek saat cicilan dicatat lewat endpoint hutang, jadi perubahan dari sini bisa
+// membuat total cicilan melebihi pokok hutang.
+func checkRepaymentChange(before, after *entity.Transaction) error {
+	if before.DebtID == nil {
+		return nil
+	}
+	if after.Amount != before.Amount || after.TransactionType != before.TransactionType || after.Date != before.Date {
+		return errx.ErrRepaymentReadOnly
+	}
+	return nil
+}
+
+
+// PatchTransaction menerapkan JSON Merge Patch: hanya field yang dikirim yang berubah,
+// dan null mengosongkan field yang boleh kosong (category_id, note, tags).
+func (s *transactionService) PatchTransaction(ctx context.Context, id uuid.UUID, req dto.PatchTransactionRequest, changedBy uuid.UUID, version int) (*entity.Transaction, error) {
+	for _, field := range []string{"transaction_type", "amount", "period", "date"} {
+		if req.Cleared[field] {
+			return nil, errx.NewBadRequestError(field + " cannot be null")
+		}
+	}
+
+	tx, err := s.repo.GetTransactionByID(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	if tx.UserID != changedBy {
+		return nil, errx.NewUnauthorizedError("You do not have access to this transaction")
+	}
+	if tx.Version != version {
+		return nil, errx.ErrVersionMismatch
+	}
+	before := *tx
 
-	// Update proof file jika ada
-	if proofPath != "" {
-		tx.ProofFile = proofPath
+	if req.TransactionType != nil {
+		tx.TransactionType = *req.TransactionType
+	}
+	if req.Amount != nil {
+		tx.Amount = *req.Amount
+	}
+	if req.CategoryID != nil {
+		tx.CategoryID = *req.CategoryID
+	}
+	if req.Note != nil {
+		tx.Note = *req.Note
+	}
+	if req.Period != nil {
+		tx.Period = *req.Period
+	}
+	if req.Date != nil {
+		tx.Date = *req.Date
+	}
+	if req.Tags != nil {
+		tx.Tags = *req.Tags
+	}
+
+	if req.Cleared["category_id"] {
+		tx.CategoryID = ""
+	}
+	if req.Cleared["note"] {
+		tx.Note = ""
+	}
+	if req.Cleared["tags"] {
+		tx.Tags = []string{}
 	}
 
 	tx.UpdatedAt = time.Now()
+	if err := checkRepaymentChange(&before, tx); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.UpdateTransaction(ctx, tx); err != nil {
+	if err := s.repo.UpdateTransaction(ctx, tx, newRevision(&before, changedBy)); err != nil {
 		return nil, err
 	}
 
+	s.forgetCategory(ctx, &before)
+	s.learnCategory(ctx, tx)
+
 	return tx, nil
 }
 
-
-func (s *transactionService) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
+func (s *transactionService) DeleteTransaction(ctx context.Context, id uuid.UUID, version int) error {
 	tx, err := s.repo.GetTransactionByID(ctx, id.String())
 	if err != nil {
 		return err
@@ -115,11 +334,16 @@ func (s *transactionService) DeleteTransaction(ctx context.Context, id uuid.UUID
 	if tx == nil {
 		return errx.ErrTransactionNotFound
 	}
+	if tx.Version != version {
+		return errx.ErrVersionMismatch
+	}
 
-	if err := s.repo.DeleteTransaction(ctx, id.String()); err != nil {
+	if err := s.repo.DeleteTransaction(ctx, id.String(), version); err != nil {
 		return err
 	}
 
+	s.forgetCategory(ctx, tx)
+
 	return nil
 }
 
@@ -139,4 +363,225 @@ func (s *transactionService) GetSummaryTransaction(ctx context.Context, userID u
 		return dto.SummaryTransactionResponse{}, err
 	}
 	return summary, nil
-}
\ No newline at end of file
+}
+
+func (s *transactionService) GetTrash(ctx context.Context, userID uuid.UUID) ([]dto.TrashedTransaction, error) {
+	txs, err := s.repo.GetTrashedTransactions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.TrashedTransaction, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, dto.TrashedTransaction{
+			Transaction: tx,
+			PurgeAt:     tx.DeletedAt.Add(s.trashRetention),
+		})
+	}
+
+	return result, nil
+}
+
+func (s *transactionService) RestoreTransaction(ctx context.Context, userID, id uuid.UUID) (*entity.Transaction, error) {
+	tx, err := s.repo.GetTrashedTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this transaction")
+	}
+	// Selama di trash cicilan lain bisa sudah dicatat, jadi memulihkannya bisa melebihi pokok hutang
+	if tx.DebtID != nil {
+		return nil, errx.NewBadRequestError("Debt repayments cannot be restored from the trash, record a new repayment on the debt instead")
+	}
+
+	if err := s.repo.RestoreTransaction(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tx.DeletedAt = nil
+	tx.UpdatedAt = time.Now()
+	s.learnCategory(ctx, tx)
+
+	return tx, nil
+}
+
+// PurgeTrash menghapus permanen transaksi yang sudah melewati masa retensi trash
+// beserta file lampiran yang tidak lagi dipakai transaksi lain.
+func (s *transactionService) PurgeTrash(ctx context.Context) (int, error) {
+	purged, keys, err := s.repo.PurgeTrashedBefore(ctx, time.Now().Add(-s.trashRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		s.removeOrphanedAttachment(ctx, key)
+	}
+
+	return purged, nil
+}
+
+func (s *transactionService) FindDuplicates(ctx context.Context, userID uuid.UUID, params dto.DuplicateScanParams) ([]dto.DuplicatePair, error) {
+	if params.Days == 0 {
+		params.Days = defaultDuplicateDays
+	}
+
+	pairs, err := s.repo.FindDuplicatePairs(ctx, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, p := range pairs {
+		ids = append(ids, p[0], p[1])
+	}
+
+	txs, err := s.repo.GetTransactionsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.DuplicatePair{}
+	for _, p := range pairs {
+		first, second := txs[p[0]], txs[p[1]]
+		if first == nil || second == nil {
+			continue
+		}
+
+		similarity := noteSimilarity(first.Note, second.Note)
+		if similarity < duplicateNoteThreshold {
+			continue
+		}
+
+		result = append(result, dto.DuplicatePair{
+			First:          first,
+			Second:         second,
+			NoteSimilarity: similarity,
+			DaysApart:      daysApart(first.Date, second.Date),
+		})
+	}
+
+	return result, nil
+}
+
+func (s *transactionService) MergeTransactions(ctx context.Context, userID, keepID uuid.UUID, version int, req dto.MergeTransactionsRequest) (*entity.Transaction, error) {
+	duplicateID, err := uuid.Parse(req.DuplicateID)
+	if err != nil {
+		return nil, errx.NewBadRequestError("Invalid duplicate ID format")
+	}
+	if duplicateID == keepID {
+		return nil, errx.NewBadRequestError("A transaction cannot be merged with itself")
+	}
+
+	txs, err := s.repo.GetTransactionsByIDs(ctx, []uuid.UUID{keepID, duplicateID})
+	if err != nil {
+		return nil, err
+	}
+
+	keep, duplicate := txs[keepID], txs[duplicateID]
+	if keep == nil || duplicate == nil {
+		return nil, errx.ErrTransactionNotFound
+	}
+	if keep.UserID != userID || duplicate.UserID != userID {
+		return nil, errx.NewUnauthorizedError("You do not have access to this transaction")
+	}
+	// Kedua transaksi berubah, jadi keduanya harus masih sama dengan yang dilihat user
+	if keep.Version != version || duplicate.Version != req.DuplicateVersion {
+		return nil, errx.ErrVersionMismatch
+	}
+	if keep.TransactionType != duplicate.TransactionType {
+		return nil, errx.NewBadRequestError("Only transactions of the same type can be merged")
+	}
+	// Cicilan hutang menentukan sisa saldo hutang, jadi tidak boleh hilang karena digabung
+	if keep.DebtID != nil || duplicate.DebtID != nil {
+		return nil, errx.NewBadRequestError("Debt repayments cannot be merged")
+	}
+
+	before := *keep
+	mergeInto(keep, duplicate)
+
+	if err := s.repo.MergeTransactions(ctx, keep, newRevision(&before, userID), duplicate); err != nil {
+		return nil, err
+	}
+
+	s.forgetCategory(ctx, duplicate)
+	s.forgetCategory(ctx, &before)
+	s.learnCategory(ctx, keep)
+
+	return keep, nil
+}
+
+func (s *transactionService) duplicateCandidates(ctx context.Context, tx *entity.Transaction) ([]dto.DuplicateCandidate, error) {
+	existing, err := s.repo.FindDuplicateCandidates(ctx, tx, defaultDuplicateDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []dto.DuplicateCandidate
+	for _, e := range existing {
+		similarity := noteSimilarity(tx.Note, e.Note)
+		if similarity < duplicateNoteThreshold {
+			continue
+		}
+		candidates = append(candidates, dto.DuplicateCandidate{
+			Transaction:    e,
+			NoteSimilarity: similarity,
+			DaysApart:      daysApart(tx.Date, e.Date),
+		})
+	}
+
+	return candidates, nil
+}
+
+// applyRules menjalankan rule auto-categorization milik user terhadap transaksi baru.
+// Kategori yang dipilih user sendiri tidak ditimpa oleh rule.
+func (s *transactionService) applyRules(ctx context.Context, tx *entity.Transaction, keepCategory bool) error {
+	engine, err := s.rules.NewEngine(ctx, tx.UserID)
+	if err != nil {
+		return err
+	}
+
+	result, _ := engine.Apply(ruleEntity.Target{
+		TransactionType: tx.TransactionType,
+		Amount:          tx.Amount,
+		Note:            tx.Note,
+		CategoryID:      tx.CategoryID,
+		Tags:            tx.Tags,
+	}, keepCategory)
+
+	tx.CategoryID = result.CategoryID
+	tx.Note = result.Note
+	tx.Tags = result.Tags
+	return nil
+}
+
+// learnCategory dan forgetCategory menjaga model saran kategori tetap sinkron.
+// Kegagalan hanya dicatat karena model bisa dilatih ulang dari riwayat.
+func (s *transactionService) learnCategory(ctx context.Context, tx *entity.Transaction) {
+	if tx.DebtID != nil {
+		return
+	}
+	if err := s.suggestions.Learn(ctx, tx.UserID, toSample(tx)); err != nil {
+		log.Printf("[CategoryModel] failed to learn transaction %s: %v", tx.ID, err)
+	}
+}
+
+func (s *transactionService) forgetCategory(ctx context.Context, tx *entity.Transaction) {
+	if tx.DebtID != nil {
+		return
+	}
+	if err := s.suggestions.Forget(ctx, tx.UserID, toSample(tx)); err != nil {
+		log.Printf("[CategoryModel] failed to forget transaction %s: %v", tx.ID, err)
+	}
+}
+
+func toSample(tx *entity.Transaction) suggestionEntity.Sample {
+	// Tanggal dari database berformat RFC3339, samakan dengan format request
+	return suggestionEntity.Sample{
+		CategoryID: tx.CategoryID,
+		Note:       tx.Note,
+		Amount:     tx.Amount,
+		Date:       dateOnly(tx.Date),
+	}
+}
@@ -0,0 +1,357 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kenziehh/cashflow-be/config"
+)
+
+// Nilai teks terenkripsi diberi prefix supaya data lama yang masih plaintext tetap bisa dibaca.
+const textPrefix = "enc:v1:"
+
+// Blob biner (isi file) terenkripsi diawali magic ini, diikuti nonce dan ciphertext.
+var blobMagic = []byte("CFE1")
+
+var ErrUnknownMasterKey = errors.New("encryption: data key is wrapped with an unknown master key")
+
+// Keyring menerapkan envelope encryption: setiap user punya data key AES-256 acak yang
+// disimpan di database dalam bentuk terbungkus (wrapped) oleh master key dari config.
+// Data user dienkripsi dengan data key tersebut memakai AES-GCM.
+type Keyring struct {
+	db *sql.DB
+	// masterKeys berisi master key aktif dan master key lama yang masih dibutuhkan
+	// untuk membuka data key yang belum di-rotate
+	masterKeys map[string][]byte
+	currentID  string
+
+	mu       sync.RWMutex
+	dataKeys map[uuid.UUID]cipher.AEAD
+}
+
+// NewKeyring membuat keyring dengan master key currentID sebagai kunci pembungkus data key baru.
+// Tanpa master key, keyring tidak mengenkripsi apa pun (mode pengembangan).
+func NewKeyring(db *sql.DB, masterKeys map[string][]byte, currentID string) (*Keyring, error) {
+	for id, key := range masterKeys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes", id)
+		}
+	}
+	if len(masterKeys) > 0 && masterKeys[currentID] == nil {
+		return nil, fmt.Errorf("current master key %q is not configured", currentID)
+	}
+
+	return &Keyring{
+		db:         db,
+		masterKeys: masterKeys,
+		currentID:  currentID,
+		dataKeys:   map[uuid.UUID]cipher.AEAD{},
+	}, nil
+}
+
+// InitKeyring membaca ENCRYPTION_MASTER_KEYS dengan format "id:base64,id:base64".
+// Key pertama adalah master key aktif, sisanya key lama untuk keperluan rotasi. Di luar
+// development, aplikasi tidak mau berjalan tanpa master key kecuali ENCRYPTION_DISABLED=true,
+// supaya config yang lupa diisi tidak diam-diam menyimpan data tanpa enkripsi.
+func InitKeyring(cfg *config.Config, db *sql.DB) *Keyring {
+	masterKeys := map[string][]byte{}
+	currentID := ""
+	for _, entry := range strings.Split(cfg.EncryptionMasterKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			log.Fatal("Invalid ENCRYPTION_MASTER_KEYS entry, expected id:base64")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			log.Fatalf("Invalid master key %q: %v", id, err)
+		}
+
+		masterKeys[id] = key
+		if currentID == "" {
+			currentID = id
+		}
+	}
+
+	keyring, err := NewKeyring(db, masterKeys, currentID)
+	if err != nil {
+		log.Fatal("Failed to initialize keyring:", err)
+	}
+
+	if currentID == "" {
+		if cfg.Environment != "development" && !cfg.EncryptionDisabled {
+			log.Fatal("ENCRYPTION_MASTER_KEYS is not set; set ENCRYPTION_DISABLED=true to run without encryption")
+		}
+		log.Println("⚠️ ENCRYPTION_MASTER_KEYS is not set, notes and attachments are stored unencrypted")
+	} else {
+		log.Printf("Encryption enabled (master key %s)", currentID)
+	}
+	return keyring
+}
+
+func (k *Keyring) Enabled() bool {
+	return k.currentID != ""
+}
+
+// EncryptString mengenkripsi teks milik user. String kosong tidak dienkripsi.
+func (k *Keyring) EncryptString(ctx context.Context, userID uuid.UUID, plaintext string) (string, error) {
+	if plaintext == "" || !k.Enabled() {
+		return plaintext, nil
+	}
+
+	sealed, err := k.seal(ctx, userID, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return textPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptString membuka hasil EncryptString. Teks tanpa prefix dianggap data lama yang
+// belum dienkripsi dan dikembalikan apa adanya.
+func (k *Keyring) DecryptString(ctx context.Context, userID uuid.UUID, value string) (string, error) {
+	if !IsSealedString(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, textPrefix))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := k.open(ctx, userID, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Encrypt mengenkripsi isi file milik user.
+func (k *Keyring) Encrypt(ctx context.Context, userID uuid.UUID, plaintext []byte) ([]byte, error) {
+	if !k.Enabled() {
+		return plaintext, nil
+	}
+
+	sealed, err := k.seal(ctx, userID, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, blobMagic...), sealed...), nil
+}
+
+// Decrypt membuka hasil Encrypt. Data tanpa magic dianggap file lama yang belum dienkripsi.
+func (k *Keyring) Decrypt(ctx context.Context, userID uuid.UUID, data []byte) ([]byte, error) {
+	if !IsSealedBlob(data) {
+		return data, nil
+	}
+	return k.open(ctx, userID, data[len(blobMagic):])
+}
+
+// IsSealedString melaporkan apakah value adalah hasil EncryptString.
+func IsSealedString(value string) bool {
+	return strings.HasPrefix(value, textPrefix)
+}
+
+// IsSealedBlob melaporkan apakah data adalah hasil Encrypt.
+func IsSealedBlob(data []byte) bool {
+	return bytes.HasPrefix(data, blobMagic)
+}
+
+// Forget membuang data key user dari cache, dipakai setelah akun user dihapus.
+func (k *Keyring) Forget(userID uuid.UUID) {
+	k.mu.Lock()
+	delete(k.dataKeys, userID)
+	k.mu.Unlock()
+}
+
+func (k *Keyring) seal(ctx context.Context, userID uuid.UUID, plaintext []byte) ([]byte, error) {
+	aead, err := k.dataKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	// User ID dipakai sebagai associated data sehingga ciphertext tidak bisa dipindah ke user lain
+	return aead.Seal(nonce, nonce, plaintext, userID[:]), nil
+}
+
+func (k *Keyring) open(ctx context.Context, userID uuid.UUID, sealed []byte) ([]byte, error) {
+	aead, err := k.dataKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encryption: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, userID[:])
+}
+
+// dataKey mengembalikan cipher untuk data key user, membuat data key baru jika belum ada.
+// Data key yang sudah dibuka disimpan di memori karena tidak pernah berubah, rotasi hanya
+// mengganti pembungkusnya.
+func (k *Keyring) dataKey(ctx context.Context, userID uuid.UUID) (cipher.AEAD, error) {
+	k.mu.RLock()
+	aead, ok := k.dataKeys[userID]
+	k.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	key, err := k.loadDataKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err = newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.dataKeys[userID] = aead
+	k.mu.Unlock()
+	return aead, nil
+}
+
+func (k *Keyring) loadDataKey(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	// Buat data key baru lebih dulu; jika request lain sudah membuatnya, yang tersimpan yang dipakai
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	wrapped, err := k.wrap(userID, key, k.currentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := k.db.ExecContext(ctx, `
+		INSERT INTO user_data_keys (user_id, master_key_id, wrapped_key, created_at, rotated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, k.currentID, wrapped); err != nil {
+		log.Printf("[DB ERROR] CreateDataKey failed: %v\n", err)
+		return nil, err
+	}
+
+	var masterKeyID string
+	if err := k.db.QueryRowContext(ctx,
+		`SELECT master_key_id, wrapped_key FROM user_data_keys WHERE user_id = $1`, userID,
+	).Scan(&masterKeyID, &wrapped); err != nil {
+		log.Printf("[DB ERROR] GetDataKey failed: %v\n", err)
+		return nil, err
+	}
+
+	return k.unwrap(userID, wrapped, masterKeyID)
+}
+
+// Rotate membungkus ulang semua data key dengan master key aktif. Data user tidak perlu
+// dienkripsi ulang karena data key-nya tetap sama.
+func (k *Keyring) Rotate(ctx context.Context) (int, error) {
+	if !k.Enabled() {
+		return 0, errors.New("encryption: no master key configured")
+	}
+
+	rows, err := k.db.QueryContext(ctx,
+		`SELECT user_id, master_key_id, wrapped_key FROM user_data_keys WHERE master_key_id <> $1`, k.currentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type wrappedKey struct {
+		userID      uuid.UUID
+		masterKeyID string
+		wrapped     []byte
+	}
+	var pending []wrappedKey
+	for rows.Next() {
+		var w wrappedKey
+		if err := rows.Scan(&w.userID, &w.masterKeyID, &w.wrapped); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, w := range pending {
+		key, err := k.unwrap(w.userID, w.wrapped, w.masterKeyID)
+		if err != nil {
+			return rotated, fmt.Errorf("user %s: %w", w.userID, err)
+		}
+		rewrapped, err := k.wrap(w.userID, key, k.currentID)
+		if err != nil {
+			return rotated, err
+		}
+
+		// Kondisi master_key_id mencegah menimpa hasil rotasi proses lain
+		if _, err := k.db.ExecContext(ctx, `
+			UPDATE user_data_keys SET master_key_id = $1, wrapped_key = $2, rotated_at = NOW()
+			WHERE user_id = $3 AND master_key_id = $4
+		`, k.currentID, rewrapped, w.userID, w.masterKeyID); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	return rotated, nil
+}
+
+func (k *Keyring) wrap(userID uuid.UUID, key []byte, masterKeyID string) ([]byte, error) {
+	aead, err := newGCM(k.masterKeys[masterKeyID])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, key, userID[:]), nil
+}
+
+func (k *Keyring) unwrap(userID uuid.UUID, wrapped []byte, masterKeyID string) ([]byte, error) {
+	master, ok := k.masterKeys[masterKeyID]
+	if !ok {
+		return nil, ErrUnknownMasterKey
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("encryption: wrapped key too short")
+	}
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, userID[:])
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
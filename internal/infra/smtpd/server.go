@@ -0,0 +1,265 @@
+// Package smtpd adalah server SMTP minimal untuk menerima email struk yang diteruskan user.
+// Server tidak mendukung TLS maupun AUTH, jadi sebaiknya hanya mendengarkan di localhost atau
+// di belakang MTA (Postfix, Haraka, dsb.) yang meneruskan email ke alamat inbox.
+package smtpd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxRecipients  = 20
+	maxConnections = 50
+	// Batas panjang baris perintah yang terbaca sekaligus, termasuk perintah yang dikirim berurutan
+	maxCommandBytes = 64 * 1024
+	commandTimeout  = 5 * time.Minute
+	dataTimeout     = 10 * time.Minute
+	deliverTimeout  = time.Minute
+)
+
+// Server menerima email untuk alamat yang lolos AcceptRecipient lalu menyerahkannya ke Deliver.
+type Server struct {
+	Addr string
+	// Nama host di sapaan server
+	Domain string
+	// Ukuran maksimal satu email dalam byte
+	MaxSize int64
+	// AcceptRecipient menolak alamat penerima yang tidak dikenal saat RCPT TO
+	AcceptRecipient func(address string) bool
+	// Deliver dipanggil sekali per email dengan semua penerima yang diterima. Error dikirim
+	// ke pengirim sebagai penolakan permanen.
+	Deliver func(ctx context.Context, recipients []string, data []byte) error
+}
+
+// ListenAndServe mendengarkan di s.Addr dan melayani koneksi sampai listener gagal.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	slots := make(chan struct{}, maxConnections)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		select {
+		case slots <- struct{}{}:
+			go func() {
+				defer func() { <-slots }()
+				s.serve(conn)
+			}()
+		default:
+			fmt.Fprintf(conn, "421 4.3.2 %s too many connections, try again later\r\n", s.Domain)
+			conn.Close()
+		}
+	}
+}
+
+// limitedConn membatasi jumlah byte yang boleh dibaca sebelum limit di-reset lagi, supaya
+// baris tanpa akhir tidak menghabiskan memori.
+type limitedConn struct {
+	net.Conn
+	reader *io.LimitedReader
+}
+
+func (c *limitedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+type session struct {
+	from       string
+	recipients []string
+}
+
+func (s *Server) serve(netConn net.Conn) {
+	defer netConn.Close()
+
+	limited := &limitedConn{Conn: netConn, reader: &io.LimitedReader{R: netConn, N: maxCommandBytes}}
+	conn := textproto.NewConn(limited)
+	defer conn.Close()
+
+	reply := func(format string, args ...any) bool {
+		netConn.SetWriteDeadline(time.Now().Add(commandTimeout))
+		return conn.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 %s ESMTP ready", s.Domain) {
+		return
+	}
+
+	var sess *session
+	for {
+		limited.reader.N = maxCommandBytes
+		netConn.SetReadDeadline(time.Now().Add(commandTimeout))
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			sess = nil
+			ok := reply("250-%s", s.Domain) &&
+				reply("250-SIZE %d", s.MaxSize) &&
+				reply("250-8BITMIME") &&
+				reply("250 PIPELINING")
+			if !ok {
+				return
+			}
+		case "HELO":
+			sess = nil
+			if !reply("250 %s", s.Domain) {
+				return
+			}
+		case "MAIL":
+			from, params, ok := parsePath(arg, "FROM:")
+			if !ok {
+				reply("501 5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			if size, err := strconv.ParseInt(params["SIZE"], 10, 64); err == nil && s.MaxSize > 0 && size > s.MaxSize {
+				reply("552 5.3.4 Message too big")
+				continue
+			}
+			sess = &session{from: from}
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			if sess == nil {
+				reply("503 5.5.1 Need MAIL command first")
+				continue
+			}
+			to, _, ok := parsePath(arg, "TO:")
+			if !ok || to == "" {
+				reply("501 5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			if len(sess.recipients) >= maxRecipients {
+				reply("452 4.5.3 Too many recipients")
+				continue
+			}
+			if s.AcceptRecipient != nil && !s.AcceptRecipient(to) {
+				reply("550 5.1.1 Mailbox unavailable")
+				continue
+			}
+			sess.recipients = append(sess.recipients, to)
+			reply("250 2.1.5 OK")
+		case "DATA":
+			if sess == nil || len(sess.recipients) == 0 {
+				reply("503 5.5.1 Need RCPT command first")
+				continue
+			}
+			if !reply("354 Start mail input; end with <CRLF>.<CRLF>") {
+				return
+			}
+			// Email yang kebesaran tetap dibaca sampai habis supaya balasan berikutnya sinkron
+			limited.reader.N = 2*s.MaxSize + maxCommandBytes
+			netConn.SetReadDeadline(time.Now().Add(dataTimeout))
+			dot := conn.DotReader()
+			data, err := io.ReadAll(io.LimitReader(dot, s.MaxSize+1))
+			if err != nil {
+				return
+			}
+			if int64(len(data)) > s.MaxSize {
+				if _, err := io.Copy(io.Discard, dot); err != nil {
+					return
+				}
+				reply("552 5.3.4 Message too big")
+				sess = nil
+				continue
+			}
+
+			if err := s.deliver(sess.recipients, data); err != nil {
+				reply("554 5.6.0 %s", firstLine(err.Error()))
+			} else {
+				reply("250 2.0.0 OK: queued")
+			}
+			sess = nil
+		case "RSET":
+			sess = nil
+			reply("250 2.0.0 OK")
+		case "NOOP":
+			reply("250 2.0.0 OK")
+		case "VRFY":
+			reply("252 2.5.2 Cannot verify user")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *Server) deliver(recipients []string, data []byte) (err error) {
+	if s.Deliver == nil {
+		return nil
+	}
+
+	// Panic saat membaca satu email tidak boleh mematikan seluruh server
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[SMTP] panic while delivering email: %v", r)
+			err = errors.New("internal error")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliverTimeout)
+	defer cancel()
+	return s.Deliver(ctx, recipients, data)
+}
+
+// parsePath membaca argumen "FROM:<alamat> SIZE=123" atau "TO:<alamat>". Alamat kosong (<>)
+// diperbolehkan untuk MAIL FROM sesuai RFC 5321.
+func parsePath(arg, prefix string) (string, map[string]string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(rest, "<") {
+		return "", nil, false
+	}
+	end := strings.Index(rest, ">")
+	if end < 0 {
+		return "", nil, false
+	}
+
+	params := map[string]string{}
+	for _, field := range strings.Fields(rest[end+1:]) {
+		key, value, _ := strings.Cut(field, "=")
+		params[strings.ToUpper(key)] = value
+	}
+
+	address := rest[1:end]
+	// Source route lama seperti "<@a,@b:user@host>" hanya memakai alamat terakhirnya
+	if i := strings.LastIndex(address, ":"); i >= 0 && strings.HasPrefix(address, "@") {
+		address = address[i+1:]
+	}
+	return address, params, true
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
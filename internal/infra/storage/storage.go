@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenziehh/cashflow-be/config"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// FileStorage menyimpan file upload berdasarkan key dengan format path bergaris miring,
+// misalnya "proofs/ab/ab12...f9.jpg".
+type FileStorage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get mengembalikan ErrNotFound jika key tidak ada
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete tidak mengembalikan error jika key sudah tidak ada
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentKey membentuk key dari hash SHA-256 isi file sehingga upload dengan isi yang
+// sama selalu mendapat key yang sama dan tidak pernah bertabrakan dengan file lain.
+func ContentKey(prefix string, data []byte, filename string) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	ext := strings.ToLower(filepath.Ext(filename))
+	if len(ext) > 10 {
+		ext = ""
+	}
+	return path.Join(prefix, hash[:2], hash+ext)
+}
+
+func InitStorage(cfg *config.Config) FileStorage {
+	switch cfg.StorageDriver {
+	case "s3":
+		store, err := NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize S3 storage:", err)
+		}
+		log.Printf("Using S3 storage (bucket %s)", cfg.S3Bucket)
+		return store
+	default:
+		log.Printf("Using local storage (%s)", cfg.StorageDir)
+		return NewLocalStorage(cfg.StorageDir)
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	idempotencyHeader   = "Idempotency-Key"
+	idempotencyMaxKey   = 255
+	idempotencyLockTime = 1 * time.Minute
+)
+
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// Idempotency menyimpan response pertama dari request POST yang membawa header
+// Idempotency-Key, sehingga retry dengan key yang sama dalam window ttl
+// mendapatkan response yang sama tanpa menjalankan handler dua kali.
+func Idempotency(redis *redis.Client, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		idemKey := c.Get(idempotencyHeader)
+		if c.Method() != fiber.MethodPost || idemKey == "" {
+			return c.Next()
+		}
+
+		if len(idemKey) > idempotencyMaxKey {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Idempotency-Key must be at most 255 characters",
+			})
+		}
+
+		// Key dibatasi per pemilik token (atau IP jika tanpa token) supaya user
+		// yang berbeda tidak bisa saling membaca response
+		scope := c.IP()
+		if auth := c.Get("Authorization"); auth != "" {
+			scope = hashHex([]byte(auth))
+		}
+		key := fmt.Sprintf("idempotency:%s:%s:%s", scope, c.Path(), hashHex([]byte(idemKey)))
+		fingerprint := hashHex([]byte(c.Method()), []byte(c.Path()), c.Body())
+
+		lock, _ := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+		acquired, err := redis.SetNX(c.Context(), key, lock, idempotencyLockTime).Result()
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Redis error")
+		}
+
+		if !acquired {
+			raw, err := redis.Get(c.Context(), key).Bytes()
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Redis error")
+			}
+
+			var stored idempotentResponse
+			if err := json.Unmarshal(raw, &stored); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Invalid idempotency record")
+			}
+
+			if stored.Fingerprint != fingerprint {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+					"success": false,
+					"message": "Idempotency-Key was already used with a different request body",
+				})
+			}
+
+			if !stored.Completed {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"success": false,
+					"message": "A request with this Idempotency-Key is still being processed",
+				})
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+			return c.Status(stored.Status).Send(stored.Body)
+		}
+
+		// Error dan response 5xx tidak disimpan, key dilepas supaya client bisa retry
+		if err := c.Next(); err != nil {
+			redis.Del(c.Context(), key)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			redis.Del(c.Context(), key)
+			return nil
+		}
+
+		record, _ := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		redis.Set(c.Context(), key, record, ttl)
+
+		return nil
+	}
+}
+
+func hashHex(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package dateutil
+
+import "time"
+
+const Layout = "2006-01-02"
+
+// TruncateDate membuang komponen jam dan mengembalikan tanggal di UTC,
+// sama seperti nilai kolom DATE yang dibaca lib/pq.
+func TruncateDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Today mengembalikan tanggal hari ini (tanpa jam).
+func Today() time.Time {
+	return TruncateDate(time.Now())
+}
+
+// AddMonthsClamped menambah bulan tanpa overflow, mis. 31 Jan + 1 bulan = 28/29 Feb.
+func AddMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC)
+}
+
+// AddPeriod mengembalikan kemunculan ke-n dari anchor untuk period
+// daily, weekly, monthly atau yearly.
+func AddPeriod(anchor time.Time, period string, n int) time.Time {
+	switch period {
+	case "daily":
+		return anchor.AddDate(0, 0, n)
+	case "weekly":
+		return anchor.AddDate(0, 0, 7*n)
+	case "yearly":
+		return AddMonthsClamped(anchor, 12*n)
+	default:
+		return AddMonthsClamped(anchor, n)
+	}
+}
+
+// AddPeriodOnDay sama seperti AddPeriod, tetapi untuk monthly dan yearly tanggalnya selalu
+// dihitung dari day sehingga tanggal yang sudah dipendekkan di bulan pendek tidak ikut
+// bergeser, mis. day 31: 31 Jan -> 28 Feb -> 31 Mar.
+func AddPeriodOnDay(from time.Time, period string, n, day int) time.Time {
+	months := n
+	switch period {
+	case "monthly":
+	case "yearly":
+		months = 12 * n
+	default:
+		return AddPeriod(from, period, n)
+	}
+
+	if day < 1 {
+		day = from.Day()
+	}
+	first := time.Date(from.Year(), from.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
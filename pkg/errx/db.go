@@ -0,0 +1,23 @@
+package errx
+
+import (
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// PeriodLockedCode adalah SQLSTATE dari trigger kunci periode rekonsiliasi (migrasi 024)
+const PeriodLockedCode = "CF001"
+
+// FromWrite memetakan penolakan dari trigger kunci rekonsiliasi ke ErrPeriodLocked, error lain
+// dicatat dan dikembalikan sebagai ErrDatabaseError. Dipakai semua repository yang menulis ke
+// tabel transactions.
+func FromWrite(op string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == PeriodLockedCode {
+		return ErrPeriodLocked
+	}
+	log.Printf("[DB ERROR] %s failed: %v\n", op, err)
+	return ErrDatabaseError
+}
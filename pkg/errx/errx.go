@@ -18,6 +18,26 @@ var (
 	ErrRedisError          = NewInternalServerError("Redis error")
 	ErrInternalServer      = NewInternalServerError("Internal server error")
 	ErrTransactionNotFound = NewNotFoundError("Transaction not found")
+	ErrGoalNotFound        = NewNotFoundError("Goal not found")
+	ErrDebtNotFound        = NewNotFoundError("Debt not found")
+	ErrCounterpartyNotFound = NewNotFoundError("Counterparty not found")
+	ErrDebtHasRepayments   = NewConflictError("Debt has recorded repayments and cannot be deleted")
+	ErrRepaymentReadOnly   = NewBadRequestError("Amount, type and date of a debt repayment cannot be changed, use the debt endpoints instead")
+	ErrBillNotFound        = NewNotFoundError("Bill not found")
+	ErrRuleNotFound        = NewNotFoundError("Rule not found")
+	ErrAttachmentNotFound  = NewNotFoundError("Attachment not found")
+	ErrExportNotFound      = NewNotFoundError("Export not found")
+	ErrCategoryNotFound    = NewNotFoundError("Category not found")
+	ErrCategoryMappingNotFound = NewNotFoundError("Category mapping not found")
+	ErrReconciliationNotFound = NewNotFoundError("Reconciliation not found")
+	ErrStatementLineNotFound = NewNotFoundError("Statement line not found")
+	ErrPeriodLocked        = NewConflictError("Transaction date is in a reconciled period that is locked")
+	ErrReceiptDraftNotFound = NewNotFoundError("Receipt draft not found")
+	ErrReceiptFileNotFound = NewNotFoundError("Receipt file not found")
+	ErrEmailAlreadyReceived = NewConflictError("This email has already been received")
+	ErrReceiptAlreadyReviewed = NewConflictError("Receipt draft has already been reviewed")
+	ErrVersionMismatch     = NewPreconditionFailedError("Resource has been modified, reload it and try again")
+	ErrIfMatchRequired     = NewPreconditionRequiredError("If-Match header is required")
 )
 
 type AppError struct {
@@ -43,6 +63,13 @@ func NewUnauthorizedError(message string) *AppError {
 	}
 }
 
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Message: message,
+	}
+}
+
 func NewNotFoundError(message string) *AppError {
 	return &AppError{
 		Code:    http.StatusNotFound,
@@ -57,6 +84,34 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+func NewPayloadTooLargeError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: message,
+	}
+}
+
+func NewUnsupportedMediaTypeError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnsupportedMediaType,
+		Message: message,
+	}
+}
+
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusPreconditionFailed,
+		Message: message,
+	}
+}
+
+func NewPreconditionRequiredError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusPreconditionRequired,
+		Message: message,
+	}
+}
+
 func NewInternalServerError(message string) *AppError {
 	return &AppError{
 		Code:    http.StatusInternalServerError,
@@ -0,0 +1,293 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+// HEIC/HEIF adalah container ISOBMFF. EXIF (termasuk lokasi GPS) dan XMP disimpan sebagai
+// item di box meta yang lokasinya ditunjuk box iloc. Isi item tersebut ditimpa nol di
+// tempat sehingga offset item lain tetap berlaku dan gambar tidak perlu di-encode ulang.
+// Orientasi HEIF disimpan di properti irot/imir, bukan di EXIF, jadi tidak ikut hilang.
+
+type isoBox struct {
+	kind string
+	// Posisi isi box (setelah header) di dalam file
+	start, end int
+}
+
+// readBoxes membaca box berurutan di data[start:end].
+func readBoxes(data []byte, start, end int) ([]isoBox, error) {
+	var boxes []isoBox
+	pos := start
+	for pos < end {
+		if pos+8 > end {
+			return nil, ErrUnsupported
+		}
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(end - pos)
+		case 1:
+			if pos+16 > end {
+				return nil, ErrUnsupported
+			}
+			size = binary.BigEndian.Uint64(data[pos+8:])
+			header = 16
+		}
+		if size < uint64(header) || size > uint64(end-pos) {
+			return nil, ErrUnsupported
+		}
+
+		boxes = append(boxes, isoBox{kind: kind, start: pos + header, end: pos + int(size)})
+		pos += int(size)
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []isoBox, kind string) (isoBox, bool) {
+	for _, b := range boxes {
+		if b.kind == kind {
+			return b, true
+		}
+	}
+	return isoBox{}, false
+}
+
+// boxReader membaca field big-endian di dalam satu box dan mencatat jika datanya kurang.
+type boxReader struct {
+	data []byte
+	pos  int
+	end  int
+	bad  bool
+}
+
+func (r *boxReader) uint(size int) uint64 {
+	if r.bad || r.pos+size > r.end {
+		r.bad = true
+		return 0
+	}
+	var v uint64
+	for i := 0; i < size; i++ {
+		v = v<<8 | uint64(r.data[r.pos+i])
+	}
+	r.pos += size
+	return v
+}
+
+func (r *boxReader) fourcc() string {
+	if r.bad || r.pos+4 > r.end {
+		r.bad = true
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+4])
+	r.pos += 4
+	return s
+}
+
+func (r *boxReader) cstring() string {
+	if r.bad {
+		return ""
+	}
+	i := bytes.IndexByte(r.data[r.pos:r.end], 0)
+	if i < 0 {
+		r.bad = true
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+i])
+	r.pos += i + 1
+	return s
+}
+
+func isHEIF(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	switch string(data[8:12]) {
+	case "heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1":
+		return true
+	}
+	return false
+}
+
+func stripHEIF(data []byte) ([]byte, error) {
+	top, err := readBoxes(data, 0, len(data))
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := findBox(top, "meta")
+	if !ok || meta.end-meta.start < 4 {
+		return nil, ErrUnsupported
+	}
+	// meta adalah FullBox: 4 byte version dan flags sebelum box anaknya
+	children, err := readBoxes(data, meta.start+4, meta.end)
+	if err != nil {
+		return nil, err
+	}
+
+	iinf, ok := findBox(children, "iinf")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	metadataItems, err := heifMetadataItems(data, iinf)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(nil), data...)
+	if len(metadataItems) == 0 {
+		return out, nil
+	}
+
+	iloc, ok := findBox(children, "iloc")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	idat, hasIdat := findBox(children, "idat")
+
+	extents, err := heifItemExtents(data, iloc, metadataItems)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range extents {
+		start, length := e.offset, e.length
+		if e.inIdat {
+			if !hasIdat {
+				return nil, ErrUnsupported
+			}
+			start += uint64(idat.start)
+			if start+length > uint64(idat.end) {
+				return nil, ErrUnsupported
+			}
+		}
+		if length == 0 || start+length > uint64(len(out)) {
+			return nil, ErrUnsupported
+		}
+		clear(out[start : start+length])
+	}
+
+	return out, nil
+}
+
+// heifMetadataItems mencari ID item EXIF dan XMP di box iinf.
+func heifMetadataItems(data []byte, iinf isoBox) (map[uint64]bool, error) {
+	r := &boxReader{data: data, pos: iinf.start, end: iinf.end}
+	version := r.uint(1)
+	r.uint(3)
+	if version == 0 {
+		r.uint(2)
+	} else {
+		r.uint(4)
+	}
+	if r.bad {
+		return nil, ErrUnsupported
+	}
+
+	entries, err := readBoxes(data, r.pos, iinf.end)
+	if err != nil {
+		return nil, err
+	}
+
+	items := map[uint64]bool{}
+	for _, e := range entries {
+		if e.kind != "infe" {
+			continue
+		}
+		er := &boxReader{data: data, pos: e.start, end: e.end}
+		v := er.uint(1)
+		er.uint(3)
+		// infe versi 0 dan 1 tidak punya item_type; tidak dipakai di HEIC
+		if v < 2 {
+			return nil, ErrUnsupported
+		}
+		idSize := 2
+		if v >= 3 {
+			idSize = 4
+		}
+		id := er.uint(idSize)
+		er.uint(2)
+		itemType := er.fourcc()
+		er.cstring()
+		contentType := ""
+		if itemType == "mime" {
+			contentType = er.cstring()
+		}
+		if er.bad {
+			return nil, ErrUnsupported
+		}
+
+		if itemType == "Exif" || (itemType == "mime" && strings.EqualFold(contentType, "application/rdf+xml")) {
+			items[id] = true
+		}
+	}
+
+	return items, nil
+}
+
+type heifExtent struct {
+	offset, length uint64
+	inIdat         bool
+}
+
+// heifItemExtents membaca box iloc dan mengembalikan potongan data milik item yang diminta.
+func heifItemExtents(data []byte, iloc isoBox, items map[uint64]bool) ([]heifExtent, error) {
+	r := &boxReader{data: data, pos: iloc.start, end: iloc.end}
+	version := r.uint(1)
+	r.uint(3)
+	if version > 2 {
+		return nil, ErrUnsupported
+	}
+
+	sizes := r.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0F)
+	sizes = r.uint(1)
+	baseOffsetSize, indexSize := int(sizes>>4), int(sizes&0x0F)
+	if version == 0 {
+		indexSize = 0
+	}
+	for _, size := range []int{offsetSize, lengthSize, baseOffsetSize, indexSize} {
+		if size != 0 && size != 4 && size != 8 {
+			return nil, ErrUnsupported
+		}
+	}
+
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count := r.uint(idSize)
+
+	var extents []heifExtent
+	for i := uint64(0); i < count && !r.bad; i++ {
+		id := r.uint(idSize)
+		method := uint64(0)
+		if version >= 1 {
+			method = r.uint(2) & 0x0F
+		}
+		r.uint(2)
+		base := r.uint(baseOffsetSize)
+		extentCount := r.uint(2)
+
+		for j := uint64(0); j < extentCount && !r.bad; j++ {
+			r.uint(indexSize)
+			offset := r.uint(offsetSize)
+			length := r.uint(lengthSize)
+			if !items[id] {
+				continue
+			}
+			// construction_method 2 (referensi ke item lain) tidak dipakai untuk EXIF/XMP
+			if method > 1 {
+				return nil, ErrUnsupported
+			}
+			extents = append(extents, heifExtent{offset: base + offset, length: length, inIdat: method == 1})
+		}
+	}
+	if r.bad {
+		return nil, ErrUnsupported
+	}
+
+	return extents, nil
+}
@@ -0,0 +1,211 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var ErrUnsupported = errors.New("imageutil: unsupported image format")
+
+var (
+	jpegMagic = []byte{0xFF, 0xD8}
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+	exifMagic = []byte("Exif\x00\x00")
+)
+
+// StripMetadata membuang metadata (EXIF termasuk lokasi GPS, XMP, IPTC, komentar) dari
+// JPEG, PNG atau HEIC/HEIF tanpa meng-encode ulang gambar. Untuk JPEG, tag orientasi
+// dipertahankan supaya foto tetap tampil tegak.
+func StripMetadata(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegMagic):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngMagic):
+		return stripPNG(data)
+	case isHEIF(data):
+		return stripHEIF(data)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// Orientation membaca tag orientasi EXIF (1-8) dari JPEG, 1 jika tidak ada.
+func Orientation(data []byte) int {
+	orientation := 1
+	_ = walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifMagic) {
+			if o := exifOrientation(segment[len(exifMagic):]); o >= 1 && o <= 8 {
+				orientation = o
+			}
+			return false
+		}
+		return true
+	})
+	return orientation
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	orientation := Orientation(data)
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(jpegMagic)
+	if orientation != 1 {
+		writeSegment(out, 0xE1, orientationExif(orientation))
+	}
+
+	rest := -1
+	err := walkJPEG(data, func(marker byte, segment []byte) bool {
+		switch {
+		// APP1 (EXIF/XMP), APP13 (IPTC/Photoshop) dan komentar dibuang. APP0 (JFIF),
+		// APP2 (profil warna ICC) dan APP14 (Adobe) dibutuhkan untuk menampilkan warna dengan benar.
+		case marker == 0xE1, marker == 0xED, marker == 0xFE:
+		case marker == 0xDA:
+			rest = len(data) - len(segment) - 4
+			return false
+		default:
+			writeSegment(out, marker, segment)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rest < 0 {
+		return nil, ErrUnsupported
+	}
+
+	// Mulai dari marker SOS, sisanya data gambar yang disalin apa adanya
+	out.Write(data[rest:])
+	return out.Bytes(), nil
+}
+
+// walkJPEG memanggil fn untuk setiap segmen sebelum data gambar. Untuk marker SOS, segment
+// berisi header SOS sampai akhir file. Iterasi berhenti jika fn mengembalikan false.
+func walkJPEG(data []byte, fn func(marker byte, segment []byte) bool) error {
+	if !bytes.HasPrefix(data, jpegMagic) {
+		return ErrUnsupported
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return ErrUnsupported
+		}
+		marker := data[pos+1]
+		// Byte 0xFF tambahan boleh dipakai sebagai padding antar segmen
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return ErrUnsupported
+		}
+
+		if marker == 0xDA {
+			fn(marker, data[pos+4:])
+			return nil
+		}
+		if !fn(marker, data[pos+4:pos+2+length]) {
+			return nil
+		}
+		pos += 2 + length
+	}
+
+	return ErrUnsupported
+}
+
+func writeSegment(out *bytes.Buffer, marker byte, segment []byte) {
+	out.Write([]byte{0xFF, marker})
+	binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+}
+
+// exifOrientation mencari tag 0x0112 di IFD0 dari blok TIFF milik EXIF.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 0
+}
+
+// orientationExif membentuk blok EXIF minimal yang hanya berisi tag orientasi.
+func orientationExif(orientation int) []byte {
+	b := bytes.NewBuffer(nil)
+	b.Write(exifMagic)
+	b.WriteString("MM")
+	binary.Write(b, binary.BigEndian, uint16(42))
+	binary.Write(b, binary.BigEndian, uint32(8))
+	// IFD0: satu entri (tag, tipe SHORT, jumlah 1, nilai), lalu offset IFD berikutnya = 0
+	binary.Write(b, binary.BigEndian, uint16(1))
+	binary.Write(b, binary.BigEndian, uint16(0x0112))
+	binary.Write(b, binary.BigEndian, uint16(3))
+	binary.Write(b, binary.BigEndian, uint32(1))
+	binary.Write(b, binary.BigEndian, uint16(orientation))
+	binary.Write(b, binary.BigEndian, uint16(0))
+	binary.Write(b, binary.BigEndian, uint32(0))
+	return b.Bytes()
+}
+
+// Chunk PNG yang berisi metadata dan aman dibuang.
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngMagic)
+
+	pos := len(pngMagic)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrUnsupported
+		}
+
+		kind := string(data[pos+4 : pos+8])
+		if !pngMetadataChunks[kind] {
+			out.Write(data[pos:end])
+		}
+		if kind == "IEND" {
+			return out.Bytes(), nil
+		}
+		pos = end
+	}
+
+	return nil, ErrUnsupported
+}